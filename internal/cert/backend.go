@@ -0,0 +1,65 @@
+package cert
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Backend selects how an Engine performs certificate operations.
+type Backend string
+
+const (
+	// BackendOpenSSL shells out to the openssl binary via the Engine's Executor.
+	BackendOpenSSL Backend = "openssl"
+	// BackendGo uses crypto/x509 and a PKCS#12 library; no external tools.
+	BackendGo Backend = "go"
+)
+
+// lookPathFn is overridable in tests.
+var lookPathFn = exec.LookPath
+
+// ParseBackend converts a user-supplied backend name into a Backend.
+func ParseBackend(s string) (Backend, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case string(BackendOpenSSL):
+		return BackendOpenSSL, nil
+	case string(BackendGo), "native":
+		return BackendGo, nil
+	default:
+		return "", fmt.Errorf("unknown backend %q (expected: go or openssl)", s)
+	}
+}
+
+// OpenSSLAvailable reports whether an openssl binary can be found in PATH.
+func OpenSSLAvailable() bool {
+	path, err := lookPathFn("openssl")
+	return err == nil && path != ""
+}
+
+// DefaultBackend returns BackendOpenSSL when openssl is installed, and
+// BackendGo otherwise.
+func DefaultBackend() Backend {
+	if OpenSSLAvailable() {
+		return BackendOpenSSL
+	}
+	return BackendGo
+}
+
+// Backend returns the backend the Engine currently uses.
+func (e *Engine) Backend() Backend {
+	if e.backend == "" {
+		return BackendOpenSSL
+	}
+	return e.backend
+}
+
+// SetBackend switches the Engine between the openssl and pure-Go backends.
+func (e *Engine) SetBackend(b Backend) {
+	e.backend = b
+}
+
+// native reports whether operations should use the pure-Go implementations.
+func (e *Engine) native() bool {
+	return e.backend == BackendGo
+}
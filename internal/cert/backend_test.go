@@ -0,0 +1,60 @@
+package cert
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseBackend(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Backend
+		wantErr bool
+	}{
+		{"go", BackendGo, false},
+		{"GO", BackendGo, false},
+		{"native", BackendGo, false},
+		{"openssl", BackendOpenSSL, false},
+		{" openssl ", BackendOpenSSL, false},
+		{"libressl", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBackend(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseBackend(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseBackend(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultBackend_FallsBackToGoWithoutOpenSSL(t *testing.T) {
+	orig := lookPathFn
+	t.Cleanup(func() { lookPathFn = orig })
+
+	lookPathFn = func(string) (string, error) { return "", errors.New("not found") }
+	if got := DefaultBackend(); got != BackendGo {
+		t.Fatalf("DefaultBackend() = %q, want %q", got, BackendGo)
+	}
+	if got := NewDefaultEngine().Backend(); got != BackendGo {
+		t.Fatalf("NewDefaultEngine().Backend() = %q, want %q", got, BackendGo)
+	}
+
+	lookPathFn = func(string) (string, error) { return "/usr/bin/openssl", nil }
+	if got := DefaultBackend(); got != BackendOpenSSL {
+		t.Fatalf("DefaultBackend() = %q, want %q", got, BackendOpenSSL)
+	}
+}
+
+func TestNewEngine_DefaultsToOpenSSL(t *testing.T) {
+	e := NewEngine(&fakeExecutor{})
+	if e.Backend() != BackendOpenSSL {
+		t.Fatalf("Backend() = %q, want %q", e.Backend(), BackendOpenSSL)
+	}
+	e.SetBackend(BackendGo)
+	if e.Backend() != BackendGo {
+		t.Fatalf("Backend() = %q after SetBackend, want %q", e.Backend(), BackendGo)
+	}
+}
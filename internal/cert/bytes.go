@@ -28,6 +28,9 @@ func DetectTypeFromNameAndBytes(name string, data []byte) FileType {
 	case ".pfx", ".p12":
 		return FileTypePFX
 	case ".pub":
+		if isSSHCertBytes(data) {
+			return FileTypeSSHCert
+		}
 		return FileTypePublicKey
 	case ".der":
 		if _, err := x509.ParseCertificate(data); err == nil {
@@ -37,6 +40,29 @@ func DetectTypeFromNameAndBytes(name string, data []byte) FileType {
 		return FileTypeBase64
 	case ".p7b", ".p7c":
 		return FileTypeP7B
+	case ".csr", ".req":
+		if hasCSRMarkerBytes(data) || isDERCSR(data) {
+			return FileTypeCSR
+		}
+	case ".crl":
+		if hasCRLMarkerBytes(data) || isDERCRL(data) {
+			return FileTypeCRL
+		}
+	case ".jks", ".jceks":
+		if !IsJKSBytes(data) && len(data) > 0 && data[0] == 0x30 {
+			return FileTypePFX
+		}
+		return FileTypeJKS
+	}
+
+	if isK8sSecretBytes(data) {
+		return FileTypeK8sSecret
+	}
+	if isKubeconfigBytes(data) {
+		return FileTypeKubeconfig
+	}
+	if isJWKBytes(data) {
+		return FileTypeJWK
 	}
 
 	hasCert, hasKey := scanPEMMarkersBytes(data)
@@ -49,12 +75,27 @@ func DetectTypeFromNameAndBytes(name string, data []byte) FileType {
 	if hasKey {
 		return FileTypeKey
 	}
+	if isSSHCertBytes(data) {
+		return FileTypeSSHCert
+	}
 	if hasPublicKeyMarkerBytes(data) || hasOpenSSHPublicKeyMarkerBytes(data) {
 		return FileTypePublicKey
 	}
+	if hasCSRMarkerBytes(data) {
+		return FileTypeCSR
+	}
+	if hasCRLMarkerBytes(data) {
+		return FileTypeCRL
+	}
 	if _, err := x509.ParseCertificate(data); err == nil {
 		return FileTypeDER
 	}
+	if isDERCSR(data) {
+		return FileTypeCSR
+	}
+	if IsJKSBytes(data) {
+		return FileTypeJKS
+	}
 	if looksLikeBase64Bytes(data) {
 		return FileTypeBase64
 	}
@@ -92,8 +133,63 @@ func SummaryFromBytesWithPassword(name string, data []byte, password string) (*C
 		populateSummaryFromCertificate(s, c)
 		return s, nil
 
+	case FileTypeJKS:
+		if err := keystoreSummary(s, data, password, populateSummaryFromCertificate); err != nil {
+			return s, err
+		}
+		return s, nil
+
+	case FileTypeK8sSecret:
+		if err := k8sSecretSummary(s, data, populateSummaryFromCertificate); err != nil {
+			return s, err
+		}
+		return s, nil
+
+	case FileTypeKubeconfig:
+		entries, err := ParseKubeconfig(data, filepath.Dir(name))
+		if err != nil {
+			return s, fmt.Errorf("read kubeconfig: %w", err)
+		}
+		kubeconfigSummary(s, entries, populateSummaryFromCertificate)
+		return s, nil
+
+	case FileTypeCSR:
+		csr, err := ParseCSRBytes(data)
+		if err != nil {
+			return s, err
+		}
+		populateSummaryFromCSR(s, csr)
+		return s, nil
+
+	case FileTypeCRL:
+		crl, err := ParseCRLBytes(data)
+		if err != nil {
+			return s, err
+		}
+		populateSummaryFromCRL(s, crl)
+		return s, nil
+
 	case FileTypeKey:
 		s.KeyType = detectKeyTypeBytes(data)
+		if s.KeyType == KeyTypeOpenSSH {
+			openSSHKeySummary(s, data)
+		}
+		return s, nil
+
+	case FileTypeSSHCert:
+		c, comment, err := ParseSSHCertificate(data)
+		if err != nil {
+			return s, err
+		}
+		sshCertSummary(s, c, comment)
+		return s, nil
+
+	case FileTypeJWK:
+		keys, err := ParseJWKs(data)
+		if err != nil {
+			return s, err
+		}
+		jwkSummary(s, keys, populateSummaryFromCertificate)
 		return s, nil
 
 	case FileTypePublicKey:
@@ -124,26 +220,51 @@ func LintBytes(name string, data []byte) (*LintResult, error) {
 // including extracting the leaf certificate from password-protected PFX/P12
 // containers.
 func LintBytesWithPassword(name string, data []byte, password string) (*LintResult, error) {
-	ft := DetectTypeFromNameAndBytes(name, data)
+	return LintBytesWithConfig(name, data, password, LintConfig{})
+}
 
-	if ft == FileTypePFX {
-		c, _, err := ParsePFXCertificates(data, password)
+// LintBytesWithConfig is LintBytesWithPassword running the rules enabled in
+// cfg. Every certificate in a PEM bundle, PFX or P7B is linted, along with
+// the bundle's chain.
+func LintBytesWithConfig(name string, data []byte, password string, cfg LintConfig) (*LintResult, error) {
+	var certs []*x509.Certificate
+	switch DetectTypeFromNameAndBytes(name, data) {
+	case FileTypeJKS:
+		return lintKeystore(name, data, password, cfg)
+	case FileTypeK8sSecret:
+		return lintK8sSecrets(name, data, cfg)
+	case FileTypeKubeconfig:
+		return lintKubeconfig(name, data, cfg)
+	case FileTypeCSR:
+		return lintCSRBytes(name, data, cfg)
+	case FileTypePFX:
+		_, all, err := ParsePFXCertificates(data, password)
+		if err != nil {
+			return nil, err
+		}
+		certs = all
+	case FileTypeP7B:
+		all, err := ParsePKCS7Certificates(data)
+		if err != nil {
+			return nil, err
+		}
+		certs = all
+	default:
+		all, _, err := parsePEMCerts(data)
 		if err != nil {
 			return nil, err
 		}
-		issues := LintCertificate(c)
-		return &LintResult{
-			File:   name,
-			Issues: issues,
-			Clean:  len(issues) == 0,
-		}, nil
+		if len(all) == 0 {
+			c, err := ParseCertBytes(data)
+			if err != nil {
+				return nil, err
+			}
+			all = []*x509.Certificate{c}
+		}
+		certs = all
 	}
 
-	c, err := ParseCertBytes(data)
-	if err != nil {
-		return nil, err
-	}
-	issues := LintCertificate(c)
+	issues := lintCertificates(certs, cfg)
 	return &LintResult{
 		File:   name,
 		Issues: issues,
@@ -240,22 +361,54 @@ func CheckExpiry(name string, data []byte, password string) (string, error) {
 	switch ft {
 	case FileTypePFX:
 		cert, _, err = ParsePFXCertificates(data, password)
+	case FileTypeJKS:
+		var ks *Keystore
+		if ks, err = ParseKeystore(data, password); err == nil {
+			if cert = ks.soonestExpiring(); cert == nil {
+				err = fmt.Errorf("keystore contains no certificates")
+			}
+		}
+	case FileTypeK8sSecret:
+		var secrets []K8sSecret
+		if secrets, err = ParseK8sSecrets(data); err == nil {
+			cert = k8sSecretSoonestExpiring(secrets)
+		}
+	case FileTypeKubeconfig:
+		var entries []KubeconfigEntry
+		if entries, err = ParseKubeconfig(data, filepath.Dir(name)); err == nil {
+			cert = kubeconfigSoonestExpiring(entries)
+		}
+	case FileTypeJWK:
+		var keys []JWK
+		if keys, err = ParseJWKs(data); err == nil {
+			cert, err = jwkSoonestExpiring(keys)
+		}
+	case FileTypeSSHCert:
+		c, _, err := ParseSSHCertificate(data)
+		if err != nil {
+			return "", err
+		}
+		r := sshCertExpiryResult(c, 0)
+		return expiryStatusText(r.ExpiresAt, r.Subject), nil
 	default:
 		cert, err = ParseCertBytes(data)
 	}
 	if err != nil {
 		return "", fmt.Errorf("failed to parse certificate: %w", err)
 	}
+	return expiryStatusText(cert.NotAfter, cert.Subject.String()), nil
+}
 
+func expiryStatusText(notAfter time.Time, subject string) string {
 	now := time.Now()
-	remaining := cert.NotAfter.Sub(now)
+	remaining := notAfter.Sub(now)
 	days := int(remaining.Hours() / 24)
 
 	var status string
-	switch {
-	case now.After(cert.NotAfter):
+	switch ClassifyExpiry(notAfter, now, ExpiryThresholds{WarnDays: 30}) {
+	case ExpiryCritical:
 		status = "EXPIRED"
-	case days <= 30:
+	case ExpiryWarning:
 		status = "EXPIRING SOON"
 	default:
 		status = "VALID"
@@ -263,10 +416,10 @@ func CheckExpiry(name string, data []byte, password string) (string, error) {
 
 	return fmt.Sprintf("Status:  %s\nExpires: %s\nDays:    %d remaining\nSubject: %s",
 		status,
-		cert.NotAfter.UTC().Format("2006-01-02 15:04:05 UTC"),
+		notAfter.UTC().Format("2006-01-02 15:04:05 UTC"),
 		days,
-		cert.Subject.String(),
-	), nil
+		subject,
+	)
 }
 
 // CertToDERBytes converts a PEM or combined PEM certificate to DER bytes.
@@ -350,7 +503,7 @@ func scanPEMMarkersBytes(data []byte) (hasCert, hasKey bool) {
 	sc := bufio.NewScanner(bytes.NewReader(data))
 	for sc.Scan() {
 		line := strings.TrimSpace(sc.Text())
-		if strings.Contains(line, "BEGIN CERTIFICATE") {
+		if isCertPEMHeader(line) {
 			hasCert = true
 		}
 		if keyHeaderRE.MatchString(line) {
@@ -386,6 +539,9 @@ func detectKeyTypeBytes(data []byte) KeyType {
 	sc := bufio.NewScanner(bytes.NewReader(data))
 	for sc.Scan() {
 		line := sc.Text()
+		if strings.Contains(line, "OPENSSH PRIVATE KEY") {
+			return KeyTypeOpenSSH
+		}
 		if strings.Contains(line, "RSA PRIVATE KEY") {
 			return KeyTypeRSA
 		}
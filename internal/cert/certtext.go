@@ -0,0 +1,337 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// opensslTimeLayout matches the date format openssl prints for notBefore/notAfter.
+const opensslTimeLayout = "Jan _2 15:04:05 2006 GMT"
+
+var rdnShortNames = map[string]string{
+	"2.5.4.3":                    "CN",
+	"2.5.4.5":                    "serialNumber",
+	"2.5.4.6":                    "C",
+	"2.5.4.7":                    "L",
+	"2.5.4.8":                    "ST",
+	"2.5.4.9":                    "street",
+	"2.5.4.10":                   "O",
+	"2.5.4.11":                   "OU",
+	"2.5.4.17":                   "postalCode",
+	"1.2.840.113549.1.9.1":       "emailAddress",
+	"0.9.2342.19200300.100.1.1":  "UID",
+	"0.9.2342.19200300.100.1.25": "DC",
+}
+
+// opensslName renders a distinguished name in the same "C = GB, O = Org, CN = x"
+// form that openssl 3 prints, preserving the order the names appear in the
+// certificate.
+func opensslName(n pkix.Name) string {
+	// Names keeps parse order; ToRDNSequence would reorder to C, O, ..., CN.
+	atvs := n.Names
+	if len(atvs) == 0 {
+		for _, rdn := range n.ToRDNSequence() {
+			atvs = append(atvs, rdn...)
+		}
+	}
+	var parts []string
+	for _, atv := range atvs {
+		key, ok := rdnShortNames[atv.Type.String()]
+		if !ok {
+			key = atv.Type.String()
+		}
+		parts = append(parts, fmt.Sprintf("%s = %v", key, atv.Value))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// opensslSerial renders a serial number as even-length uppercase hex, like
+// "openssl x509 -serial".
+func opensslSerial(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+	s := strings.ToUpper(n.Text(16))
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	return s
+}
+
+// colonHex renders bytes as colon-separated lowercase hex wrapped at width
+// bytes per line, each line prefixed with indent.
+func colonHex(b []byte, width int, indent string) string {
+	var lines []string
+	for i := 0; i < len(b); i += width {
+		end := i + width
+		if end > len(b) {
+			end = len(b)
+		}
+		var parts []string
+		for _, c := range b[i:end] {
+			parts = append(parts, hex.EncodeToString([]byte{c}))
+		}
+		line := indent + strings.Join(parts, ":")
+		if end < len(b) {
+			line += ":"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func opensslSigAlgName(a x509.SignatureAlgorithm) string {
+	switch a {
+	case x509.SHA1WithRSA:
+		return "sha1WithRSAEncryption"
+	case x509.SHA256WithRSA:
+		return "sha256WithRSAEncryption"
+	case x509.SHA384WithRSA:
+		return "sha384WithRSAEncryption"
+	case x509.SHA512WithRSA:
+		return "sha512WithRSAEncryption"
+	case x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS:
+		return "rsassaPss"
+	case x509.ECDSAWithSHA1:
+		return "ecdsa-with-SHA1"
+	case x509.ECDSAWithSHA256:
+		return "ecdsa-with-SHA256"
+	case x509.ECDSAWithSHA384:
+		return "ecdsa-with-SHA384"
+	case x509.ECDSAWithSHA512:
+		return "ecdsa-with-SHA512"
+	case x509.PureEd25519:
+		return "ED25519"
+	}
+	return a.String()
+}
+
+func opensslEKUName(eku x509.ExtKeyUsage) string {
+	switch eku {
+	case x509.ExtKeyUsageServerAuth:
+		return "TLS Web Server Authentication"
+	case x509.ExtKeyUsageClientAuth:
+		return "TLS Web Client Authentication"
+	case x509.ExtKeyUsageCodeSigning:
+		return "Code Signing"
+	case x509.ExtKeyUsageEmailProtection:
+		return "E-mail Protection"
+	case x509.ExtKeyUsageTimeStamping:
+		return "Time Stamping"
+	case x509.ExtKeyUsageOCSPSigning:
+		return "OCSP Signing"
+	case x509.ExtKeyUsageAny:
+		return "Any Extended Key Usage"
+	}
+	return strings.Join(describeExtKeyUsage([]x509.ExtKeyUsage{eku}), "")
+}
+
+// renderPublicKeyText renders a public key in the layout of
+// "openssl pkey -pubin -text", indented by indent.
+func renderPublicKeyText(pub any, indent string) string {
+	var b strings.Builder
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		fmt.Fprintf(&b, "%sPublic-Key: (%d bit)\n", indent, k.N.BitLen())
+		fmt.Fprintf(&b, "%sModulus:\n", indent)
+		mod := k.N.Bytes()
+		if len(mod) > 0 && mod[0]&0x80 != 0 {
+			mod = append([]byte{0}, mod...)
+		}
+		b.WriteString(colonHex(mod, 15, indent+"    ") + "\n")
+		fmt.Fprintf(&b, "%sExponent: %d (0x%x)\n", indent, k.E, k.E)
+	case *ecdsa.PublicKey:
+		fmt.Fprintf(&b, "%sPublic-Key: (%d bit)\n", indent, k.Curve.Params().BitSize)
+		fmt.Fprintf(&b, "%spub:\n", indent)
+		//nolint:staticcheck // elliptic.Marshal is the simplest uncompressed-point encoder here
+		b.WriteString(colonHex(elliptic.Marshal(k.Curve, k.X, k.Y), 15, indent+"    ") + "\n")
+		fmt.Fprintf(&b, "%sNIST CURVE: %s\n", indent, curveName(k.Curve))
+	case ed25519.PublicKey:
+		fmt.Fprintf(&b, "%sED25519 Public-Key:\n", indent)
+		fmt.Fprintf(&b, "%spub:\n", indent)
+		b.WriteString(colonHex(k, 15, indent+"    ") + "\n")
+	default:
+		fmt.Fprintf(&b, "%sUnsupported public key type %T\n", indent, pub)
+	}
+	return b.String()
+}
+
+func publicKeyAlgorithmName(pub any) string {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return "rsaEncryption"
+	case *ecdsa.PublicKey:
+		return "id-ecPublicKey"
+	case ed25519.PublicKey:
+		return "ED25519"
+	}
+	return "unknown"
+}
+
+// RenderCertificateText renders a certificate in a layout close to
+// "openssl x509 -text -noout". It is used by the pure-Go backend.
+func RenderCertificateText(c *x509.Certificate) string {
+	var b strings.Builder
+	b.WriteString("Certificate:\n")
+	b.WriteString("    Data:\n")
+	fmt.Fprintf(&b, "        Version: %d (0x%x)\n", c.Version, c.Version-1)
+	if c.SerialNumber.IsInt64() && c.SerialNumber.Sign() >= 0 {
+		fmt.Fprintf(&b, "        Serial Number: %d (0x%x)\n", c.SerialNumber.Int64(), c.SerialNumber.Int64())
+	} else {
+		b.WriteString("        Serial Number:\n")
+		b.WriteString(colonHex(c.SerialNumber.Bytes(), 32, "            ") + "\n")
+	}
+	fmt.Fprintf(&b, "        Signature Algorithm: %s\n", opensslSigAlgName(c.SignatureAlgorithm))
+	fmt.Fprintf(&b, "        Issuer: %s\n", opensslName(c.Issuer))
+	b.WriteString("        Validity\n")
+	fmt.Fprintf(&b, "            Not Before: %s\n", c.NotBefore.UTC().Format(opensslTimeLayout))
+	fmt.Fprintf(&b, "            Not After : %s\n", c.NotAfter.UTC().Format(opensslTimeLayout))
+	fmt.Fprintf(&b, "        Subject: %s\n", opensslName(c.Subject))
+	b.WriteString("        Subject Public Key Info:\n")
+	fmt.Fprintf(&b, "            Public Key Algorithm: %s\n", publicKeyAlgorithmName(c.PublicKey))
+	b.WriteString(renderPublicKeyText(c.PublicKey, "                "))
+
+	if len(c.Extensions) > 0 {
+		b.WriteString("        X509v3 extensions:\n")
+		for _, ext := range c.Extensions {
+			writeExtensionText(&b, c, ext)
+		}
+	}
+
+	fmt.Fprintf(&b, "    Signature Algorithm: %s\n", opensslSigAlgName(c.SignatureAlgorithm))
+	b.WriteString("    Signature Value:\n")
+	b.WriteString(colonHex(c.Signature, 18, "        ") + "\n")
+	return b.String()
+}
+
+var (
+	oidExtSubjectKeyID      = asn1.ObjectIdentifier{2, 5, 29, 14}
+	oidExtKeyUsage          = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtSubjectAltName    = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidExtBasicConstraints  = asn1.ObjectIdentifier{2, 5, 29, 19}
+	oidExtCRLDistPoints     = asn1.ObjectIdentifier{2, 5, 29, 31}
+	oidExtCertPolicies      = asn1.ObjectIdentifier{2, 5, 29, 32}
+	oidExtAuthorityKeyID    = asn1.ObjectIdentifier{2, 5, 29, 35}
+	oidExtExtendedKeyUsage  = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidExtAuthorityInfoAcc  = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 1}
+	oidExtNameConstraints   = asn1.ObjectIdentifier{2, 5, 29, 30}
+	oidExtCRLNumber         = asn1.ObjectIdentifier{2, 5, 29, 20}
+	oidExtCTPrecertSCTs     = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+	extensionDisplayNameMap = map[string]string{
+		oidExtSubjectKeyID.String():     "X509v3 Subject Key Identifier",
+		oidExtKeyUsage.String():         "X509v3 Key Usage",
+		oidExtSubjectAltName.String():   "X509v3 Subject Alternative Name",
+		oidExtBasicConstraints.String(): "X509v3 Basic Constraints",
+		oidExtCRLDistPoints.String():    "X509v3 CRL Distribution Points",
+		oidExtCertPolicies.String():     "X509v3 Certificate Policies",
+		oidExtAuthorityKeyID.String():   "X509v3 Authority Key Identifier",
+		oidExtExtendedKeyUsage.String(): "X509v3 Extended Key Usage",
+		oidExtAuthorityInfoAcc.String(): "Authority Information Access",
+		oidExtNameConstraints.String():  "X509v3 Name Constraints",
+		oidExtCRLNumber.String():        "X509v3 CRL Number",
+		oidExtCTPrecertSCTs.String():    "CT Precertificate SCTs",
+	}
+)
+
+// extensionDisplayName returns the openssl-style label for an extension OID.
+func extensionDisplayName(oid asn1.ObjectIdentifier) string {
+	if name, ok := extensionDisplayNameMap[oid.String()]; ok {
+		return name
+	}
+	return oid.String()
+}
+
+func writeExtensionText(b *strings.Builder, c *x509.Certificate, ext pkix.Extension) {
+	writeExtensionTextIndent(b, c, ext, "            ")
+}
+
+// writeExtensionTextIndent renders ext with its header at base indentation and
+// the value four spaces deeper.
+func writeExtensionTextIndent(b *strings.Builder, c *x509.Certificate, ext pkix.Extension, base string) {
+	indent := base + "    "
+	header := base + extensionDisplayName(ext.Id) + ":"
+	if ext.Critical {
+		header += " critical"
+	}
+	b.WriteString(header + "\n")
+
+	var body []string
+	switch {
+	case ext.Id.Equal(oidExtSubjectKeyID):
+		body = []string{strings.ToUpper(colonHex(c.SubjectKeyId, len(c.SubjectKeyId)+1, ""))}
+	case ext.Id.Equal(oidExtAuthorityKeyID):
+		body = []string{strings.ToUpper(colonHex(c.AuthorityKeyId, len(c.AuthorityKeyId)+1, ""))}
+	case ext.Id.Equal(oidExtKeyUsage):
+		body = []string{strings.Join(describeKeyUsage(c.KeyUsage), ", ")}
+	case ext.Id.Equal(oidExtExtendedKeyUsage):
+		var names []string
+		for _, eku := range c.ExtKeyUsage {
+			names = append(names, opensslEKUName(eku))
+		}
+		for _, oid := range c.UnknownExtKeyUsage {
+			names = append(names, oid.String())
+		}
+		body = []string{strings.Join(names, ", ")}
+	case ext.Id.Equal(oidExtBasicConstraints):
+		line := "CA:FALSE"
+		if c.IsCA {
+			line = "CA:TRUE"
+			if c.MaxPathLen > 0 || c.MaxPathLenZero {
+				line += fmt.Sprintf(", pathlen:%d", c.MaxPathLen)
+			}
+		}
+		body = []string{line}
+	case ext.Id.Equal(oidExtSubjectAltName):
+		var names []string
+		for _, d := range c.DNSNames {
+			names = append(names, "DNS:"+d)
+		}
+		for _, ip := range c.IPAddresses {
+			names = append(names, "IP Address:"+ip.String())
+		}
+		for _, e := range c.EmailAddresses {
+			names = append(names, "email:"+e)
+		}
+		for _, u := range c.URIs {
+			names = append(names, "URI:"+u.String())
+		}
+		body = []string{strings.Join(names, ", ")}
+	case ext.Id.Equal(oidExtCRLDistPoints):
+		body = append(body, "Full Name:")
+		for _, u := range c.CRLDistributionPoints {
+			body = append(body, "  URI:"+u)
+		}
+	case ext.Id.Equal(oidExtAuthorityInfoAcc):
+		for _, u := range c.OCSPServer {
+			body = append(body, "OCSP - URI:"+u)
+		}
+		for _, u := range c.IssuingCertificateURL {
+			body = append(body, "CA Issuers - URI:"+u)
+		}
+	case ext.Id.Equal(oidExtCertPolicies):
+		for _, p := range c.Policies {
+			body = append(body, "Policy: "+p.String())
+		}
+	default:
+		body = []string{colonHex(ext.Value, 16, "")}
+	}
+
+	for _, line := range body {
+		b.WriteString(indent + line + "\n")
+	}
+}
+
+// formatOpenSSLTime renders t the way openssl prints certificate dates.
+func formatOpenSSLTime(t time.Time) string {
+	return t.UTC().Format(opensslTimeLayout)
+}
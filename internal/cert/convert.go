@@ -25,6 +25,14 @@ func (e *Engine) ToPFX(ctx context.Context, certPath, keyPath, outputPath, passw
 		return err
 	}
 
+	if e.native() {
+		b, err := nativePFXBytes(certPath, keyPath, password, caPath, keyPassword)
+		if err != nil {
+			return err
+		}
+		return writeFileExclusive(outputPath, b, 0o600)
+	}
+
 	// Check key matches cert
 	m, err := e.MatchKeyToCert(ctx, certPath, keyPath, keyPassword)
 	if err != nil {
@@ -69,6 +77,10 @@ func (e *Engine) ToPFX(ctx context.Context, certPath, keyPath, outputPath, passw
 
 // FromPFX extracts cert, key, and optionally CA certs from a PFX file.
 func (e *Engine) FromPFX(ctx context.Context, inputPath, outputDir, password string) (*FromPFXResult, error) {
+	if e.native() {
+		return nativeFromPFX(inputPath, outputDir, password)
+	}
+
 	// Validate PFX
 	extra := []ExtraFile{{Data: []byte(password)}}
 	_, stderr, err := e.runPKCS12WithExtraFiles(ctx, extra,
@@ -172,6 +184,9 @@ func (e *Engine) ToDER(ctx context.Context, inputPath, outputPath string, isKey
 	if err := ensureNotExists(outputPath); err != nil {
 		return err
 	}
+	if e.native() {
+		return nativeToDER(inputPath, outputPath, isKey, keyPassword)
+	}
 
 	tmp, err := newTempPath(outputPath)
 	if err != nil {
@@ -233,6 +248,9 @@ func (e *Engine) FromDER(ctx context.Context, inputPath, outputPath string, isKe
 	if err := ensureNotExists(outputPath); err != nil {
 		return err
 	}
+	if e.native() {
+		return nativeFromDER(inputPath, outputPath, isKey, keyPassword)
+	}
 	tmp, err := newTempPath(outputPath)
 	if err != nil {
 		return fmt.Errorf("create temp output: %w", err)
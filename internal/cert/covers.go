@@ -0,0 +1,186 @@
+package cert
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// HostCoverage is whether a certificate covers one hostname or IP address.
+// MatchedBy names the identifier that matched ("DNS: *.example.com",
+// "IP: 10.0.0.1", "CN: example.com"); Reason explains a miss.
+type HostCoverage struct {
+	Host      string `json:"host"`
+	Covered   bool   `json:"covered"`
+	MatchedBy string `json:"matched_by,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// CoversResult is the coverage of a set of hosts by a file's certificate.
+// Covered is set when every host is.
+type CoversResult struct {
+	File    string         `json:"file"`
+	Subject string         `json:"subject"`
+	SANs    []string       `json:"sans"`
+	Covered bool           `json:"covered"`
+	Hosts   []HostCoverage `json:"hosts"`
+}
+
+// CoversFile checks hosts against the first certificate in path (a PEM or
+// DER certificate, bundle, PFX, P7B or keystore).
+func CoversFile(path, password string, hosts []string, legacyCN bool) (*CoversResult, error) {
+	ft, err := DetectType(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := loadFirstCertificate(path, ft, password)
+	if err != nil {
+		return nil, err
+	}
+	r := &CoversResult{
+		File:    path,
+		Subject: opensslName(c.Subject),
+		SANs:    FormatSANsList(c),
+		Covered: true,
+		Hosts:   CheckCoverage(c, hosts, legacyCN),
+	}
+	if r.SANs == nil {
+		r.SANs = []string{}
+	}
+	for _, h := range r.Hosts {
+		r.Covered = r.Covered && h.Covered
+	}
+	return r, nil
+}
+
+// CheckCoverage matches each host against c's Subject Alternative Names
+// following RFC 6125 section 6.4: names compare case-insensitively, a
+// wildcard must be the whole leftmost label and matches exactly one label,
+// and IP addresses match only IP SANs. With legacyCN the subject Common Name
+// is tried too, but (as RFC 6125 6.4.4 requires) only when the certificate
+// has no DNS SANs.
+func CheckCoverage(c *x509.Certificate, hosts []string, legacyCN bool) []HostCoverage {
+	out := make([]HostCoverage, 0, len(hosts))
+	for _, h := range hosts {
+		out = append(out, checkHost(c, h, legacyCN))
+	}
+	return out
+}
+
+func checkHost(c *x509.Certificate, host string, legacyCN bool) HostCoverage {
+	res := HostCoverage{Host: host}
+	ref := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+
+	if ip := net.ParseIP(strings.Trim(ref, "[]")); ip != nil {
+		for _, san := range c.IPAddresses {
+			if san.Equal(ip) {
+				res.Covered, res.MatchedBy = true, "IP: "+san.String()
+				return res
+			}
+		}
+		switch {
+		case containsFold(c.DNSNames, ref):
+			res.Reason = "listed only as a DNS SAN; clients match IP addresses against IP SANs"
+		case len(c.IPAddresses) == 0:
+			res.Reason = "certificate has no IP SANs"
+		default:
+			res.Reason = "no IP SAN matches"
+		}
+		return res
+	}
+
+	switch {
+	case ref == "":
+		res.Reason = "empty hostname"
+		return res
+	case strings.Contains(ref, "*"):
+		res.Reason = "the hostname to check cannot contain a wildcard"
+		return res
+	}
+
+	for _, san := range c.DNSNames {
+		if matchHostname(san, ref) {
+			res.Covered, res.MatchedBy = true, "DNS: "+san
+			return res
+		}
+	}
+
+	cn := c.Subject.CommonName
+	if legacyCN && len(c.DNSNames) == 0 && cn != "" && matchHostname(cn, ref) {
+		res.Covered, res.MatchedBy = true, "CN: "+cn
+		return res
+	}
+	res.Reason = missReason(c, ref, legacyCN)
+	return res
+}
+
+// matchHostname reports whether the presented identifier pattern (a SAN or
+// CN) matches the reference hostname, which is already lower-case without a
+// trailing dot.
+func matchHostname(pattern, host string) bool {
+	pattern = strings.TrimSuffix(strings.ToLower(pattern), ".")
+	if pattern == host {
+		return true
+	}
+	rest, ok := strings.CutPrefix(pattern, "*.")
+	if !ok || strings.Contains(rest, "*") || !strings.Contains(rest, ".") {
+		return false
+	}
+	label, hostRest, ok := strings.Cut(host, ".")
+	return ok && label != "" && hostRest == rest
+}
+
+// missReason explains why no identifier in c matched host, naming the
+// nearest miss when there is one.
+func missReason(c *x509.Certificate, host string, legacyCN bool) string {
+	for _, san := range c.DNSNames {
+		pattern := strings.TrimSuffix(strings.ToLower(san), ".")
+		rest, ok := strings.CutPrefix(pattern, "*.")
+		switch {
+		case ok && !strings.Contains(rest, "."):
+			if strings.HasSuffix(host, "."+rest) {
+				return fmt.Sprintf("DNS: %s is a wildcard directly under a top-level domain, which clients do not honour", san)
+			}
+		case ok && host == rest:
+			return fmt.Sprintf("DNS: %s does not cover the bare domain %s", san, rest)
+		case ok && strings.HasSuffix(host, "."+rest):
+			return fmt.Sprintf("DNS: %s covers exactly one label; %s has more", san, host)
+		case strings.Contains(pattern, "*") && wildcardGlobMatch(pattern, host):
+			return fmt.Sprintf("DNS: %s is a partial-label wildcard, which RFC 6125 clients do not honour", san)
+		}
+	}
+
+	cn := c.Subject.CommonName
+	cnMatches := cn != "" && matchHostname(cn, host)
+	switch {
+	case cnMatches && len(c.DNSNames) > 0:
+		return "only the CN matches; it is ignored because the certificate has DNS SANs"
+	case cnMatches && !legacyCN:
+		return "only the CN matches; pass --legacy-cn to accept it"
+	case len(c.DNSNames) == 0:
+		return "certificate has no DNS SANs"
+	}
+	return "no DNS SAN matches"
+}
+
+// wildcardGlobMatch matches a pattern with a '*' inside its leftmost label
+// (such as "w*.example.com") against host, for explaining misses.
+func wildcardGlobMatch(pattern, host string) bool {
+	pLabel, pRest, ok := strings.Cut(pattern, ".")
+	hLabel, hRest, ok2 := strings.Cut(host, ".")
+	if !ok || !ok2 || pRest != hRest || strings.Count(pLabel, "*") != 1 {
+		return false
+	}
+	prefix, suffix, _ := strings.Cut(pLabel, "*")
+	return len(hLabel) >= len(prefix)+len(suffix) && strings.HasPrefix(hLabel, prefix) && strings.HasSuffix(hLabel, suffix)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSuffix(v, "."), s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package cert
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+func TestCheckCoverage_RFC6125(t *testing.T) {
+	c := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "legacy.test"},
+		DNSNames:    []string{"*.example.com", "api.example.org.", "w*.example.net", "*.com"},
+		IPAddresses: []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("2001:db8::1")},
+	}
+
+	tests := []struct {
+		host      string
+		matchedBy string
+		reason    string
+	}{
+		{host: "www.example.com", matchedBy: "DNS: *.example.com"},
+		{host: "WWW.Example.COM.", matchedBy: "DNS: *.example.com"},
+		{host: "api.example.org", matchedBy: "DNS: api.example.org."},
+		{host: "10.0.0.1", matchedBy: "IP: 10.0.0.1"},
+		{host: "[2001:db8::1]", matchedBy: "IP: 2001:db8::1"},
+		{host: "example.com", reason: "bare domain"},
+		{host: "a.b.example.com", reason: "exactly one label"},
+		{host: "www.example.net", reason: "partial-label wildcard"},
+		{host: "example.com.com", reason: "top-level domain"},
+		{host: "10.0.0.2", reason: "no IP SAN matches"},
+		{host: "*.example.com", reason: "cannot contain a wildcard"},
+		{host: "legacy.test", reason: "ignored because the certificate has DNS SANs"},
+		{host: "other.test", reason: "no DNS SAN matches"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got := CheckCoverage(c, []string{tt.host}, true)[0]
+			if got.Covered != (tt.matchedBy != "") || got.MatchedBy != tt.matchedBy {
+				t.Fatalf("got %+v, want matched by %q", got, tt.matchedBy)
+			}
+			if !strings.Contains(got.Reason, tt.reason) {
+				t.Fatalf("reason = %q, want it to mention %q", got.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestCheckCoverage_LegacyCN(t *testing.T) {
+	c := &x509.Certificate{Subject: pkix.Name{CommonName: "*.example.com"}}
+
+	got := CheckCoverage(c, []string{"www.example.com"}, false)[0]
+	if got.Covered || !strings.Contains(got.Reason, "--legacy-cn") {
+		t.Fatalf("without legacy CN: got %+v", got)
+	}
+	got = CheckCoverage(c, []string{"www.example.com"}, true)[0]
+	if !got.Covered || got.MatchedBy != "CN: *.example.com" {
+		t.Fatalf("with legacy CN: got %+v", got)
+	}
+	got = CheckCoverage(c, []string{"10.0.0.1"}, true)[0]
+	if got.Covered || got.Reason != "certificate has no IP SANs" {
+		t.Fatalf("IP against CN-only cert: got %+v", got)
+	}
+}
+
+func TestCoversFile(t *testing.T) {
+	chain := testutil.MakeChain(t)
+
+	r, err := CoversFile(chain.LeafPath, "", []string{"app.test.local", "leaf.test.local"}, false)
+	if err != nil {
+		t.Fatalf("CoversFile: %v", err)
+	}
+	if r.Covered || !r.Hosts[0].Covered || r.Hosts[1].Covered {
+		t.Fatalf("want only app.test.local covered, got %+v", r)
+	}
+	if len(r.SANs) != 1 || r.SANs[0] != "DNS: app.test.local" {
+		t.Fatalf("SANs = %v", r.SANs)
+	}
+
+	if _, err := CoversFile(filepath.Join(t.TempDir(), "missing.pem"), "", []string{"x"}, false); err == nil {
+		t.Fatalf("expected error for missing file")
+	}
+}
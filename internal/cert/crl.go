@@ -0,0 +1,305 @@
+package cert
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const crlPEMType = "X509 CRL"
+
+var oidExtCRLReason = asn1.ObjectIdentifier{2, 5, 29, 21}
+
+// crlReasonNames follows the labels openssl prints for CRL reason codes
+// (RFC 5280 section 5.3.1). Code 7 is unassigned.
+var crlReasonNames = map[int]string{
+	0:  "Unspecified",
+	1:  "Key Compromise",
+	2:  "CA Compromise",
+	3:  "Affiliation Changed",
+	4:  "Superseded",
+	5:  "Cessation Of Operation",
+	6:  "Certificate Hold",
+	8:  "Remove From CRL",
+	9:  "Privilege Withdrawn",
+	10: "AA Compromise",
+}
+
+// ParseCRLFile reads the first PEM or DER certificate revocation list in path.
+func ParseCRLFile(path string) (*x509.RevocationList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCRLBytes(data)
+}
+
+// ParseCRLBytes parses the first PEM ("X509 CRL") or DER revocation list in
+// data.
+func ParseCRLBytes(data []byte) (*x509.RevocationList, error) {
+	crls, err := parseCRLs(data)
+	if err != nil {
+		return nil, err
+	}
+	return crls[0], nil
+}
+
+// parseCRLs returns every CRL in data: all X509 CRL PEM blocks, or a single
+// DER CRL.
+func parseCRLs(data []byte) ([]*x509.RevocationList, error) {
+	var crls []*x509.RevocationList
+	rest := data
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		rest = r
+		if block.Type != crlPEMType {
+			continue
+		}
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		crls = append(crls, crl)
+	}
+	if len(crls) > 0 {
+		return crls, nil
+	}
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		return nil, errors.New("no CRL found")
+	}
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, err
+	}
+	return []*x509.RevocationList{crl}, nil
+}
+
+// loadCRLFiles reads every CRL from the given files.
+func loadCRLFiles(paths []string) ([]*x509.RevocationList, error) {
+	var all []*x509.RevocationList
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("read CRL: %w", err)
+		}
+		crls, err := parseCRLs(data)
+		if err != nil {
+			return nil, fmt.Errorf("read CRL %s: %w", p, err)
+		}
+		all = append(all, crls...)
+	}
+	return all, nil
+}
+
+func hasCRLMarker(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), "BEGIN X509 CRL") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCRLMarkerBytes(data []byte) bool {
+	return bytes.Contains(data, []byte("-----BEGIN X509 CRL-----"))
+}
+
+func hasDERCRL(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return isDERCRL(data)
+}
+
+func isDERCRL(data []byte) bool {
+	if len(data) == 0 || data[0] != 0x30 {
+		return false
+	}
+	_, err := x509.ParseRevocationList(data)
+	return err == nil
+}
+
+// crlEntryReason returns the reason label for a revoked entry, or "" when
+// the entry carries no reason code extension.
+func crlEntryReason(e x509.RevocationListEntry) string {
+	for _, ext := range e.Extensions {
+		if ext.Id.Equal(oidExtCRLReason) {
+			if name, ok := crlReasonNames[e.ReasonCode]; ok {
+				return name
+			}
+			return fmt.Sprintf("Unknown (%d)", e.ReasonCode)
+		}
+	}
+	return ""
+}
+
+// populateSummaryFromCRL fills the CRL summary fields. Times are RFC 3339,
+// like populateSummaryFromCertificate.
+func populateSummaryFromCRL(s *CertSummary, crl *x509.RevocationList) {
+	s.Issuer = crl.Issuer.String()
+	s.ThisUpdate = crl.ThisUpdate.UTC().Format(time.RFC3339)
+	if !crl.NextUpdate.IsZero() {
+		s.NextUpdate = crl.NextUpdate.UTC().Format(time.RFC3339)
+	}
+	if crl.Number != nil {
+		s.CRLNumber = crl.Number.String()
+	}
+	s.SignatureAlgorithm = crl.SignatureAlgorithm.String()
+	s.Revoked = make([]RevokedCertInfo, 0, len(crl.RevokedCertificateEntries))
+	for _, e := range crl.RevokedCertificateEntries {
+		s.Revoked = append(s.Revoked, RevokedCertInfo{
+			Serial:    opensslSerial(e.SerialNumber),
+			RevokedAt: e.RevocationTime.UTC().Format(time.RFC3339),
+			Reason:    crlEntryReason(e),
+		})
+	}
+
+	fp := sha256.Sum256(crl.Raw)
+	s.Fingerprint = formatFingerprint(hex.EncodeToString(fp[:]))
+}
+
+// nativeCRLSummary is the file-based summary, with names and dates in
+// openssl's layout to match the certificate summaries.
+func nativeCRLSummary(path string) (*CertSummary, error) {
+	s := &CertSummary{File: path, FileType: FileTypeCRL}
+	crl, err := ParseCRLFile(path)
+	if err != nil {
+		return s, fmt.Errorf("read crl: %w", err)
+	}
+	populateSummaryFromCRL(s, crl)
+	s.Issuer = opensslName(crl.Issuer)
+	s.ThisUpdate = formatOpenSSLTime(crl.ThisUpdate)
+	if !crl.NextUpdate.IsZero() {
+		s.NextUpdate = formatOpenSSLTime(crl.NextUpdate)
+	}
+	for i, e := range crl.RevokedCertificateEntries {
+		s.Revoked[i].RevokedAt = formatOpenSSLTime(e.RevocationTime)
+	}
+	return s, nil
+}
+
+// RenderCRLText approximates "openssl crl -text -noout".
+func RenderCRLText(crl *x509.RevocationList) string {
+	var b strings.Builder
+	b.WriteString("Certificate Revocation List (CRL):\n")
+	if len(crl.Extensions) > 0 {
+		b.WriteString("        Version 2 (0x1)\n")
+	} else {
+		b.WriteString("        Version 1 (0x0)\n")
+	}
+	fmt.Fprintf(&b, "        Signature Algorithm: %s\n", opensslSigAlgName(crl.SignatureAlgorithm))
+	fmt.Fprintf(&b, "        Issuer: %s\n", opensslName(crl.Issuer))
+	fmt.Fprintf(&b, "        Last Update: %s\n", formatOpenSSLTime(crl.ThisUpdate))
+	if crl.NextUpdate.IsZero() {
+		b.WriteString("        Next Update: NONE\n")
+	} else {
+		fmt.Fprintf(&b, "        Next Update: %s\n", formatOpenSSLTime(crl.NextUpdate))
+	}
+	if len(crl.Extensions) > 0 {
+		b.WriteString("        CRL extensions:\n")
+		c := &x509.Certificate{AuthorityKeyId: crl.AuthorityKeyId}
+		for _, ext := range crl.Extensions {
+			if ext.Id.Equal(oidExtCRLNumber) && crl.Number != nil {
+				b.WriteString("            " + extensionDisplayName(ext.Id) + ":\n")
+				b.WriteString("                " + crl.Number.String() + "\n")
+				continue
+			}
+			writeExtensionText(&b, c, ext)
+		}
+	}
+	if len(crl.RevokedCertificateEntries) == 0 {
+		b.WriteString("No Revoked Certificates.\n")
+	} else {
+		b.WriteString("Revoked Certificates:\n")
+		for _, e := range crl.RevokedCertificateEntries {
+			fmt.Fprintf(&b, "    Serial Number: %s\n", opensslSerial(e.SerialNumber))
+			fmt.Fprintf(&b, "        Revocation Date: %s\n", formatOpenSSLTime(e.RevocationTime))
+			if reason := crlEntryReason(e); reason != "" {
+				b.WriteString("        CRL entry extensions:\n")
+				b.WriteString("            X509v3 CRL Reason Code:\n")
+				b.WriteString("                " + reason + "\n")
+			}
+		}
+	}
+	fmt.Fprintf(&b, "    Signature Algorithm: %s\n", opensslSigAlgName(crl.SignatureAlgorithm))
+	b.WriteString("    Signature Value:\n")
+	b.WriteString(colonHex(crl.Signature, 18, "        ") + "\n")
+	return b.String()
+}
+
+// findRevocation returns the entry revoking c in any CRL from c's issuer, or
+// nil. CRL signatures are not checked here; see checkCRLRevocation.
+func findRevocation(c *x509.Certificate, crls []*x509.RevocationList) *x509.RevocationListEntry {
+	for _, crl := range crls {
+		if !bytes.Equal(crl.RawIssuer, c.RawIssuer) {
+			continue
+		}
+		for i := range crl.RevokedCertificateEntries {
+			e := &crl.RevokedCertificateEntries[i]
+			if e.SerialNumber != nil && e.SerialNumber.Cmp(c.SerialNumber) == 0 {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+func revocationDetail(c *x509.Certificate, e *x509.RevocationListEntry) string {
+	d := fmt.Sprintf("Certificate serial %s was revoked on %s", opensslSerial(c.SerialNumber), formatOpenSSLTime(e.RevocationTime))
+	if reason := crlEntryReason(*e); reason != "" {
+		d += " (reason: " + reason + ")"
+	}
+	return d
+}
+
+// checkCRLRevocation checks leaf against the CRLs signed by issuer, the way
+// "openssl verify -crl_check" does: a current CRL from the issuer must be
+// present, and must not list the leaf. It returns a failure reason and a
+// diagnostic, or two empty strings when the leaf is good.
+func checkCRLRevocation(leaf, issuer *x509.Certificate, crls []*x509.RevocationList, now time.Time) (reason VerifyReason, detail string) {
+	var current []*x509.RevocationList
+	var expired *x509.RevocationList
+	for _, crl := range crls {
+		if !bytes.Equal(crl.RawIssuer, issuer.RawSubject) || crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+		if now.Before(crl.ThisUpdate) {
+			continue
+		}
+		if !crl.NextUpdate.IsZero() && now.After(crl.NextUpdate) {
+			expired = crl
+			continue
+		}
+		current = append(current, crl)
+	}
+	if len(current) == 0 {
+		if expired != nil {
+			return VerifyReasonCRLExpired, fmt.Sprintf("CRL from %s expired on %s", opensslName(issuer.Subject), formatOpenSSLTime(expired.NextUpdate))
+		}
+		return VerifyReasonCRLUnavailable, fmt.Sprintf("No valid CRL from %s in the supplied CRL files", opensslName(issuer.Subject))
+	}
+	if e := findRevocation(leaf, current); e != nil {
+		return VerifyReasonRevoked, revocationDetail(leaf, e)
+	}
+	return "", ""
+}
@@ -0,0 +1,101 @@
+package cert
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+func TestDetectType_CRL(t *testing.T) {
+	set := testutil.MakeCRLSet(t, time.Now().Add(24*time.Hour))
+
+	for _, p := range []string{set.CRLPath, set.DERCRLPath} {
+		if ft, err := DetectType(p); err != nil || ft != FileTypeCRL {
+			t.Errorf("DetectType(%s) = %s, %v; want crl", p, ft, err)
+		}
+		data, _ := os.ReadFile(p)
+		if ft := DetectTypeFromNameAndBytes("list.crl", data); ft != FileTypeCRL {
+			t.Errorf("DetectTypeFromNameAndBytes(%s) = %s; want crl", p, ft)
+		}
+	}
+
+	// PEM CRLs are recognised by their armour whatever the extension.
+	data, _ := os.ReadFile(set.CRLPath)
+	if ft := DetectTypeFromNameAndBytes("list.pem", data); ft != FileTypeCRL {
+		t.Errorf("DetectTypeFromNameAndBytes(list.pem) = %s; want crl", ft)
+	}
+}
+
+func TestCRL_SummaryDetails(t *testing.T) {
+	set := testutil.MakeCRLSet(t, time.Now().Add(24*time.Hour))
+
+	// CRL summaries never shell out, even on the openssl backend.
+	s, err := NewEngine(refusingExec{t: t}).Summary(context.Background(), set.DERCRLPath, "")
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if s.FileType != FileTypeCRL || s.Issuer != "CN = CertConv Test CA" || s.CRLNumber != "42" {
+		t.Errorf("summary = %+v", s)
+	}
+	if s.ThisUpdate == "" || s.NextUpdate == "" {
+		t.Errorf("ThisUpdate/NextUpdate = %q/%q", s.ThisUpdate, s.NextUpdate)
+	}
+	if len(s.Revoked) != 1 || s.Revoked[0].Serial != "1001" || s.Revoked[0].Reason != "Key Compromise" {
+		t.Errorf("Revoked = %+v", s.Revoked)
+	}
+
+	d, err := newNativeTestEngine(t).Details(context.Background(), set.CRLPath, "")
+	if err != nil {
+		t.Fatalf("Details() error = %v", err)
+	}
+	for _, w := range []string{"Certificate Revocation List (CRL):", "X509v3 CRL Number:\n                42", "Serial Number: 1001", "Key Compromise"} {
+		if !strings.Contains(d.RawText, w) {
+			t.Errorf("Details missing %q:\n%s", w, d.RawText)
+		}
+	}
+}
+
+func TestVerifyChain_CRL(t *testing.T) {
+	set := testutil.MakeCRLSet(t, time.Now().Add(24*time.Hour))
+	expired := testutil.MakeCRLSet(t, time.Now().Add(-time.Hour))
+	ctx := context.Background()
+
+	engines := map[string]*Engine{"go": newNativeTestEngine(t)}
+	if _, err := exec.LookPath("openssl"); err == nil {
+		engines["openssl"] = NewEngine(&OSExecutor{})
+	}
+	for name, eng := range engines {
+		verify := func(certPath, caPath string, crls ...string) *VerifyResult {
+			t.Helper()
+			r, err := eng.VerifyChainWithOptions(ctx, certPath, caPath, VerifyOptions{CRLFiles: crls})
+			if err != nil {
+				t.Fatalf("%s: VerifyChainWithOptions() error = %v", name, err)
+			}
+			return r
+		}
+
+		if r := verify(set.GoodPath, set.CAPath, set.CRLPath); !r.Valid {
+			t.Errorf("%s: good leaf = %+v, want valid", name, r)
+		}
+		r := verify(set.RevokedPath, set.CAPath, set.DERCRLPath)
+		if r.Valid || !strings.Contains(r.Details, "serial 1001 was revoked on") || !strings.Contains(r.Details, "Key Compromise") {
+			t.Errorf("%s: revoked leaf = %+v", name, r)
+		}
+		// A CRL from some other CA does not cover the leaf.
+		if r := verify(set.GoodPath, set.CAPath, expired.CRLPath); r.Valid {
+			t.Errorf("%s: unrelated CRL = %+v, want failure", name, r)
+		}
+		if r := verify(expired.GoodPath, expired.CAPath, expired.CRLPath); r.Valid || !strings.Contains(r.Details, "expired") {
+			t.Errorf("%s: expired CRL = %+v, want failure", name, r)
+		}
+		// Without --crl, revocation is not checked.
+		if r := verify(set.RevokedPath, set.CAPath); !r.Valid {
+			t.Errorf("%s: no CRL = %+v, want valid", name, r)
+		}
+	}
+}
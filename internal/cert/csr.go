@@ -0,0 +1,260 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var csrHeaders = []string{"-----BEGIN CERTIFICATE REQUEST-----", "-----BEGIN NEW CERTIFICATE REQUEST-----"}
+
+// ParseCSRFile reads a PEM or DER PKCS#10 certificate signing request.
+func ParseCSRFile(path string) (*x509.CertificateRequest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCSRBytes(data)
+}
+
+// ParseCSRBytes parses a PEM ("CERTIFICATE REQUEST" or the older "NEW
+// CERTIFICATE REQUEST" armour) or DER certificate signing request.
+func ParseCSRBytes(data []byte) (*x509.CertificateRequest, error) {
+	rest := data
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		rest = r
+		if block.Type == "CERTIFICATE REQUEST" || block.Type == "NEW CERTIFICATE REQUEST" {
+			return x509.ParseCertificateRequest(block.Bytes)
+		}
+	}
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		return nil, errors.New("no certificate request found")
+	}
+	return x509.ParseCertificateRequest(data)
+}
+
+func hasCSRMarkerBytes(data []byte) bool {
+	for _, h := range csrHeaders {
+		if bytes.Contains(data, []byte(h)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDERCSR(data []byte) bool {
+	if len(data) == 0 || data[0] != 0x30 {
+		return false
+	}
+	_, err := x509.ParseCertificateRequest(data)
+	return err == nil
+}
+
+// populateSummaryFromCSR fills the summary fields that make sense for a
+// request: there is no issuer, validity or serial yet.
+func populateSummaryFromCSR(s *CertSummary, csr *x509.CertificateRequest) {
+	c := csrAsCertificate(csr)
+	s.Subject = csr.Subject.String()
+	s.SANs = collectSANs(c)
+	s.SignatureAlgorithm = csr.SignatureAlgorithm.String()
+	s.PublicKeyInfo = describePublicKey(c)
+	s.KeyUsage = describeKeyUsage(c.KeyUsage)
+	s.ExtKeyUsage = describeExtKeyUsage(c.ExtKeyUsage)
+	s.IsCA = c.IsCA
+	for _, ext := range csr.Extensions {
+		s.RequestedExtensions = append(s.RequestedExtensions, extensionDisplayName(ext.Id))
+	}
+	valid := csr.CheckSignature() == nil
+	s.SignatureValid = &valid
+
+	fp := sha256.Sum256(csr.Raw)
+	s.Fingerprint = formatFingerprint(hex.EncodeToString(fp[:]))
+}
+
+func nativeCSRSummary(path string) (*CertSummary, error) {
+	s := &CertSummary{File: path, FileType: FileTypeCSR}
+	csr, err := ParseCSRFile(path)
+	if err != nil {
+		return s, fmt.Errorf("read csr: %w", err)
+	}
+	populateSummaryFromCSR(s, csr)
+	s.Subject = opensslName(csr.Subject)
+	return s, nil
+}
+
+// csrAsCertificate copies the requested extensions of a CSR onto a
+// certificate-shaped value so the certificate renderers and lint checks can
+// be reused. Only the fields those helpers read are populated.
+func csrAsCertificate(csr *x509.CertificateRequest) *x509.Certificate {
+	c := &x509.Certificate{
+		PublicKey:          csr.PublicKey,
+		PublicKeyAlgorithm: csr.PublicKeyAlgorithm,
+		SignatureAlgorithm: csr.SignatureAlgorithm,
+		Subject:            csr.Subject,
+		DNSNames:           csr.DNSNames,
+		EmailAddresses:     csr.EmailAddresses,
+		IPAddresses:        csr.IPAddresses,
+		URIs:               csr.URIs,
+		Extensions:         csr.Extensions,
+	}
+	for _, ext := range csr.Extensions {
+		switch {
+		case ext.Id.Equal(oidExtKeyUsage):
+			var bits asn1.BitString
+			if _, err := asn1.Unmarshal(ext.Value, &bits); err == nil {
+				for i := 0; i < 9; i++ {
+					if bits.At(i) != 0 {
+						c.KeyUsage |= 1 << uint(i)
+					}
+				}
+			}
+		case ext.Id.Equal(oidExtExtendedKeyUsage):
+			var oids []asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(ext.Value, &oids); err == nil {
+				for _, oid := range oids {
+					if eku, ok := extKeyUsageFromOID(oid); ok {
+						c.ExtKeyUsage = append(c.ExtKeyUsage, eku)
+					} else {
+						c.UnknownExtKeyUsage = append(c.UnknownExtKeyUsage, oid)
+					}
+				}
+			}
+		case ext.Id.Equal(oidExtBasicConstraints):
+			var bc struct {
+				IsCA       bool `asn1:"optional"`
+				MaxPathLen int  `asn1:"optional,default:-1"`
+			}
+			if _, err := asn1.Unmarshal(ext.Value, &bc); err == nil {
+				c.BasicConstraintsValid = true
+				c.IsCA = bc.IsCA
+				c.MaxPathLen = bc.MaxPathLen
+				c.MaxPathLenZero = bc.MaxPathLen == 0
+			}
+		case ext.Id.Equal(oidExtSubjectKeyID):
+			var ski []byte
+			if _, err := asn1.Unmarshal(ext.Value, &ski); err == nil {
+				c.SubjectKeyId = ski
+			}
+		}
+	}
+	return c
+}
+
+var extKeyUsageOIDs = map[string]x509.ExtKeyUsage{
+	"2.5.29.37.0":            x509.ExtKeyUsageAny,
+	"1.3.6.1.5.5.7.3.1":      x509.ExtKeyUsageServerAuth,
+	"1.3.6.1.5.5.7.3.2":      x509.ExtKeyUsageClientAuth,
+	"1.3.6.1.5.5.7.3.3":      x509.ExtKeyUsageCodeSigning,
+	"1.3.6.1.5.5.7.3.4":      x509.ExtKeyUsageEmailProtection,
+	"1.3.6.1.5.5.7.3.5":      x509.ExtKeyUsageIPSECEndSystem,
+	"1.3.6.1.5.5.7.3.6":      x509.ExtKeyUsageIPSECTunnel,
+	"1.3.6.1.5.5.7.3.7":      x509.ExtKeyUsageIPSECUser,
+	"1.3.6.1.5.5.7.3.8":      x509.ExtKeyUsageTimeStamping,
+	"1.3.6.1.5.5.7.3.9":      x509.ExtKeyUsageOCSPSigning,
+	"1.3.6.1.4.1.311.10.3.3": x509.ExtKeyUsageMicrosoftServerGatedCrypto,
+	"2.16.840.1.113730.4.1":  x509.ExtKeyUsageNetscapeServerGatedCrypto,
+	"1.3.6.1.4.1.311.2.1.22": x509.ExtKeyUsageMicrosoftCommercialCodeSigning,
+	"1.3.6.1.4.1.311.61.1.1": x509.ExtKeyUsageMicrosoftKernelCodeSigning,
+}
+
+func extKeyUsageFromOID(oid asn1.ObjectIdentifier) (x509.ExtKeyUsage, bool) {
+	eku, ok := extKeyUsageOIDs[oid.String()]
+	return eku, ok
+}
+
+// RenderCSRText approximates "openssl req -text -noout".
+func RenderCSRText(csr *x509.CertificateRequest) string {
+	c := csrAsCertificate(csr)
+
+	var b strings.Builder
+	b.WriteString("Certificate Request:\n")
+	b.WriteString("    Data:\n")
+	fmt.Fprintf(&b, "        Version: %d (0x%x)\n", csr.Version+1, csr.Version)
+	fmt.Fprintf(&b, "        Subject: %s\n", opensslName(csr.Subject))
+	b.WriteString("        Subject Public Key Info:\n")
+	fmt.Fprintf(&b, "            Public Key Algorithm: %s\n", publicKeyAlgorithmName(csr.PublicKey))
+	b.WriteString(renderPublicKeyText(csr.PublicKey, "                "))
+	b.WriteString("        Attributes:\n")
+	if len(csr.Extensions) == 0 {
+		b.WriteString("            (none)\n")
+	} else {
+		b.WriteString("            Requested Extensions:\n")
+		for _, ext := range csr.Extensions {
+			writeExtensionTextIndent(&b, c, ext, "                ")
+		}
+	}
+	fmt.Fprintf(&b, "    Signature Algorithm: %s\n", opensslSigAlgName(csr.SignatureAlgorithm))
+	b.WriteString("    Signature Value:\n")
+	b.WriteString(colonHex(csr.Signature, 18, "        ") + "\n")
+	return b.String()
+}
+
+// LintCSR runs the lint checks that apply before issuance, plus a
+// self-signature check.
+func LintCSR(csr *x509.CertificateRequest) []LintIssue {
+	return LintCSRWithConfig(csr, LintConfig{})
+}
+
+// LintCSRWithConfig runs the CSR rules enabled in cfg.
+func LintCSRWithConfig(csr *x509.CertificateRequest, cfg LintConfig) []LintIssue {
+	c := csrAsCertificate(csr)
+	var issues []LintIssue
+	for _, r := range lintRules {
+		if !r.CSR || !cfg.RuleEnabled(r) {
+			continue
+		}
+		var msg string
+		if r.csrCheck != nil {
+			msg = r.csrCheck(csr)
+		} else {
+			msg = r.check(c)
+		}
+		if msg != "" {
+			issues = append(issues, cfg.issue(r, msg))
+		}
+	}
+	return issues
+}
+
+func checkCSRSignature(csr *x509.CertificateRequest) string {
+	if err := csr.CheckSignature(); err != nil {
+		return "CSR self-signature does not verify: " + err.Error()
+	}
+	return ""
+}
+
+func lintCSRBytes(name string, data []byte, cfg LintConfig) (*LintResult, error) {
+	csr, err := ParseCSRBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	issues := LintCSRWithConfig(csr, cfg)
+	return &LintResult{
+		File:   name,
+		Issues: issues,
+		Clean:  len(issues) == 0,
+	}, nil
+}
+
+func nativeMatchKeyToCSR(csrPath, keyPath, keyPassword string) (*MatchResult, error) {
+	csr, err := ParseCSRFile(csrPath)
+	if err != nil {
+		return nil, fmt.Errorf("read csr public key: %w", err)
+	}
+	keyPub, err := privateKeyFilePublic(keyPath, keyPassword)
+	if err != nil {
+		return nil, fmt.Errorf("read key public key: %w", err)
+	}
+	return &MatchResult{Match: publicKeysEqual(csr.PublicKey, keyPub)}, nil
+}
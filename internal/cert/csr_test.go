@@ -0,0 +1,168 @@
+package cert
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+// writeTestCSR creates a CSR for key with two SANs and a requested
+// serverAuth EKU, written as PEM to dir/name (or DER when der is true).
+func writeTestCSR(t *testing.T, key crypto.Signer, dir, name string, der bool) string {
+	t.Helper()
+	ekuDER, err := asn1.Marshal([]asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:         pkix.Name{CommonName: "app.example.com", Organization: []string{"Example"}},
+		DNSNames:        []string{"app.example.com", "www.example.com"},
+		ExtraExtensions: []pkix.Extension{{Id: oidExtExtendedKeyUsage, Value: ekuDER}},
+	}, key)
+	if err != nil {
+		t.Fatalf("create csr: %v", err)
+	}
+	data := raw
+	if !der {
+		data = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: raw})
+	}
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDetectType_CSR(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	_, key := loadTestPair(t, pair)
+
+	for _, tc := range []struct {
+		name string
+		der  bool
+	}{
+		{"req.csr", false},
+		{"req.pem", false},
+		{"req.req", true},
+		{"req.bin", true},
+	} {
+		p := writeTestCSR(t, key, pair.Dir, tc.name, tc.der)
+		if ft, err := DetectType(p); err != nil || ft != FileTypeCSR {
+			t.Errorf("DetectType(%s) = %s, %v; want csr", tc.name, ft, err)
+		}
+		data, _ := os.ReadFile(p)
+		if ft := DetectTypeFromNameAndBytes(tc.name, data); ft != FileTypeCSR {
+			t.Errorf("DetectTypeFromNameAndBytes(%s) = %s; want csr", tc.name, ft)
+		}
+	}
+}
+
+func TestCSR_SummaryDetailsLint(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	_, key := loadTestPair(t, pair)
+	p := writeTestCSR(t, key, pair.Dir, "app.csr", false)
+
+	// CSR summaries never shell out, even on the openssl backend.
+	s, err := NewEngine(refusingExec{t: t}).Summary(context.Background(), p, "")
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if s.FileType != FileTypeCSR || !strings.Contains(s.Subject, "CN = app.example.com") {
+		t.Errorf("summary = %+v", s)
+	}
+	if strings.Join(s.SANs, ",") != "app.example.com,www.example.com" {
+		t.Errorf("SANs = %v", s.SANs)
+	}
+	if s.PublicKeyInfo != "RSA 2048" {
+		t.Errorf("PublicKeyInfo = %q", s.PublicKeyInfo)
+	}
+	if s.SignatureValid == nil || !*s.SignatureValid {
+		t.Errorf("SignatureValid = %v, want true", s.SignatureValid)
+	}
+	if len(s.ExtKeyUsage) != 1 || s.ExtKeyUsage[0] != "Server Auth" {
+		t.Errorf("ExtKeyUsage = %v", s.ExtKeyUsage)
+	}
+	want := []string{"X509v3 Subject Alternative Name", "X509v3 Extended Key Usage"}
+	if strings.Join(s.RequestedExtensions, "|") != strings.Join(want, "|") {
+		t.Errorf("RequestedExtensions = %v, want %v", s.RequestedExtensions, want)
+	}
+
+	d, err := newNativeTestEngine(t).Details(context.Background(), p, "")
+	if err != nil {
+		t.Fatalf("Details() error = %v", err)
+	}
+	for _, w := range []string{"Certificate Request:", "Requested Extensions:", "DNS:app.example.com, DNS:www.example.com", "TLS Web Server Authentication"} {
+		if !strings.Contains(d.RawText, w) {
+			t.Errorf("Details missing %q:\n%s", w, d.RawText)
+		}
+	}
+
+	lr, err := LintFile(p)
+	if err != nil {
+		t.Fatalf("LintFile() error = %v", err)
+	}
+	if !lr.Clean {
+		t.Errorf("expected clean CSR, got %+v", lr.Issues)
+	}
+}
+
+func TestCSR_LintFlagsWeakKeyAndBadSignature(t *testing.T) {
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	p := writeTestCSR(t, weak, dir, "weak.csr", true)
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0xFF // corrupt the signature
+
+	lr, err := LintBytes("weak.csr", data)
+	if err != nil {
+		t.Fatalf("LintBytes() error = %v", err)
+	}
+	codes := map[string]bool{}
+	for _, issue := range lr.Issues {
+		codes[issue.Code] = true
+	}
+	if !codes["weak-key"] || !codes["bad-csr-signature"] {
+		t.Errorf("issues = %+v, want weak-key and bad-csr-signature", lr.Issues)
+	}
+}
+
+func TestMatchKeyToCSR(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	other := testutil.MakeECCertPair(t)
+	_, key := loadTestPair(t, pair)
+	p := writeTestCSR(t, key, pair.Dir, "app.csr", false)
+	ctx := context.Background()
+
+	engines := map[string]*Engine{"go": newNativeTestEngine(t)}
+	if _, err := exec.LookPath("openssl"); err == nil {
+		engines["openssl"] = NewEngine(&OSExecutor{})
+	}
+	for name, eng := range engines {
+		m, err := eng.MatchKeyToCert(ctx, p, pair.KeyPath, "")
+		if err != nil || !m.Match {
+			t.Errorf("%s: match = %+v, %v; want match", name, m, err)
+		}
+		m, err = eng.MatchKeyToCert(ctx, p, other.KeyPath, "")
+		if err != nil || m.Match {
+			t.Errorf("%s: mismatched key = %+v, %v; want no match", name, m, err)
+		}
+	}
+}
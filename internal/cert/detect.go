@@ -2,13 +2,14 @@ package cert
 
 import (
 	"bufio"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 )
 
-var keyHeaderRE = regexp.MustCompile(`^-----BEGIN (RSA |EC |ENCRYPTED )?PRIVATE KEY-----$`)
+var keyHeaderRE = regexp.MustCompile(`^-----BEGIN (RSA |EC |ENCRYPTED |OPENSSH )?PRIVATE KEY-----$`)
 var publicKeyHeaderRE = regexp.MustCompile(`^-----BEGIN (RSA )?PUBLIC KEY-----$`)
 
 // DetectType determines the FileType of a file by extension and content inspection.
@@ -19,7 +20,10 @@ func DetectType(path string) (FileType, error) {
 	case ".pfx", ".p12":
 		return FileTypePFX, nil
 	case ".pub":
-		// Common for OpenSSH public keys.
+		// Common for OpenSSH public keys, and certificates (*-cert.pub).
+		if hasSSHCertMarker(path) {
+			return FileTypeSSHCert, nil
+		}
 		return FileTypePublicKey, nil
 	case ".der":
 		return FileTypeDER, nil
@@ -27,6 +31,35 @@ func DetectType(path string) (FileType, error) {
 		return FileTypeBase64, nil
 	case ".p7b", ".p7c":
 		return FileTypeP7B, nil
+	case ".csr", ".req":
+		if hasCSRMarker(path) || hasDERCSR(path) {
+			return FileTypeCSR, nil
+		}
+	case ".crl":
+		if hasCRLMarker(path) || hasDERCRL(path) {
+			return FileTypeCRL, nil
+		}
+	case ".jks", ".jceks":
+		// Java 9+ keytool writes PKCS#12 by default, even with a .jks name.
+		if !hasJKSMagic(path) {
+			if isDER, _ := IsDEREncoded(path); isDER {
+				return FileTypePFX, nil
+			}
+		}
+		return FileTypeJKS, nil
+	}
+
+	// Kubernetes Secret manifests may embed PEM in stringData, so look for
+	// them before scanning for PEM markers.
+	if ext != ".key" && hasK8sSecret(path) {
+		return FileTypeK8sSecret, nil
+	}
+	// kubectl's default ~/.kube/config has no extension at all.
+	if ext != ".key" && hasKubeconfig(path) {
+		return FileTypeKubeconfig, nil
+	}
+	if ext != ".key" && hasJWK(path) {
+		return FileTypeJWK, nil
 	}
 
 	// For .key extension, check content first; if it has cert markers too, it's combined
@@ -62,13 +95,80 @@ func DetectType(path string) (FileType, error) {
 	if hasPublicKeyMarker(path) {
 		return FileTypePublicKey, nil
 	}
+	if hasSSHCertMarker(path) {
+		return FileTypeSSHCert, nil
+	}
 	if hasOpenSSHPublicKeyMarker(path) {
 		return FileTypePublicKey, nil
 	}
+	if hasCSRMarker(path) || hasDERCSR(path) {
+		return FileTypeCSR, nil
+	}
+	if hasCRLMarker(path) {
+		return FileTypeCRL, nil
+	}
+	if hasJKSMagic(path) {
+		return FileTypeJKS, nil
+	}
 
 	return FileTypeUnknown, nil
 }
 
+func hasCSRMarker(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), "CERTIFICATE REQUEST-----") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDERCSR(path string) bool {
+	data, err := readHead(path, maxDERCSRSize)
+	if err != nil {
+		return false
+	}
+	return isDERCSR(data)
+}
+
+// maxDERCSRSize bounds how much of an unknown binary file is read when probing
+// for a DER CSR. Real requests are a few KiB at most.
+const maxDERCSRSize = 64 << 10
+
+func readHead(path string, n int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return io.ReadAll(io.LimitReader(f, n))
+}
+
+// isCertPEMHeader reports whether line opens a CERTIFICATE block (and not,
+// say, a CERTIFICATE REQUEST).
+func isCertPEMHeader(line string) bool {
+	return strings.Contains(line, "BEGIN CERTIFICATE") && !strings.Contains(line, "REQUEST")
+}
+
+func hasJKSMagic(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := make([]byte, 4)
+	n, _ := f.Read(buf)
+	return IsJKSBytes(buf[:n])
+}
+
 // scanPEMMarkers scans a file for BEGIN CERTIFICATE and BEGIN PRIVATE KEY markers.
 func scanPEMMarkers(path string) (hasCert, hasKey bool, err error) {
 	f, err := os.Open(path)
@@ -80,7 +180,7 @@ func scanPEMMarkers(path string) (hasCert, hasKey bool, err error) {
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		line := scanner.Text()
-		if strings.Contains(line, "BEGIN CERTIFICATE") {
+		if isCertPEMHeader(line) {
 			hasCert = true
 		}
 		if keyHeaderRE.MatchString(line) {
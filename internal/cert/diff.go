@@ -0,0 +1,171 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CertDiffField is one attribute compared between two certificates.
+type CertDiffField struct {
+	Name    string `json:"name"`
+	Old     string `json:"old"`
+	New     string `json:"new"`
+	Changed bool   `json:"changed"`
+}
+
+// CertDiff describes what changed between an old certificate and its
+// replacement. SANs, EKUs and extensions are compared as sets; SameKey is set
+// when both certificates carry the same public key.
+type CertDiff struct {
+	OldFile           string          `json:"old_file"`
+	NewFile           string          `json:"new_file"`
+	Fields            []CertDiffField `json:"fields"`
+	SANsAdded         []string        `json:"sans_added"`
+	SANsRemoved       []string        `json:"sans_removed"`
+	EKUsAdded         []string        `json:"ekus_added"`
+	EKUsRemoved       []string        `json:"ekus_removed"`
+	ExtensionsAdded   []string        `json:"extensions_added"`
+	ExtensionsRemoved []string        `json:"extensions_removed"`
+	SameKey           bool            `json:"same_key"`
+	Identical         bool            `json:"identical"`
+}
+
+// SANSuperset reports whether the new certificate kept every SAN of the old.
+func (d *CertDiff) SANSuperset() bool {
+	return len(d.SANsRemoved) == 0
+}
+
+// DiffFiles compares the leaf certificates of two files of any certificate
+// type DetectType recognises. password unlocks either file if it is a PFX or
+// keystore.
+func DiffFiles(oldPath, newPath, password string) (*CertDiff, error) {
+	oldCert, err := loadLeafCertificate(oldPath, password)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", oldPath, err)
+	}
+	newCert, err := loadLeafCertificate(newPath, password)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", newPath, err)
+	}
+	d := DiffCertificates(oldCert, newCert)
+	d.OldFile, d.NewFile = oldPath, newPath
+	return d, nil
+}
+
+// loadLeafCertificate returns the leaf of the chain in path, whatever order
+// the file holds it in.
+func loadLeafCertificate(path, password string) (*x509.Certificate, error) {
+	ft, err := DetectType(path)
+	if err != nil {
+		return nil, err
+	}
+	if !isCertFileType(ft) {
+		return nil, fmt.Errorf("not a certificate file (detected %s)", ft)
+	}
+	certs, err := loadCertificates(path, ft, password)
+	if err != nil {
+		return nil, err
+	}
+	ordered, _ := orderCerts(certs, nil)
+	return certs[ordered[0]], nil
+}
+
+// DiffCertificates compares two certificates attribute by attribute.
+func DiffCertificates(oldCert, newCert *x509.Certificate) *CertDiff {
+	d := &CertDiff{
+		SameKey:   bytes.Equal(oldCert.RawSubjectPublicKeyInfo, newCert.RawSubjectPublicKeyInfo),
+		Identical: oldCert.Equal(newCert),
+	}
+
+	field := func(name, oldVal, newVal string) {
+		d.Fields = append(d.Fields, CertDiffField{Name: name, Old: oldVal, New: newVal, Changed: oldVal != newVal})
+	}
+	field("Subject", opensslName(oldCert.Subject), opensslName(newCert.Subject))
+	field("Issuer", opensslName(oldCert.Issuer), opensslName(newCert.Issuer))
+	field("Serial", opensslSerial(oldCert.SerialNumber), opensslSerial(newCert.SerialNumber))
+	field("Key", describePublicKeyValue(oldCert.PublicKey), describePublicKeyValue(newCert.PublicKey))
+	field("Signature Algorithm", opensslSigAlgName(oldCert.SignatureAlgorithm), opensslSigAlgName(newCert.SignatureAlgorithm))
+	field("Not Before", formatOpenSSLTime(oldCert.NotBefore), formatOpenSSLTime(newCert.NotBefore))
+	field("Not After", formatOpenSSLTime(oldCert.NotAfter), formatOpenSSLTime(newCert.NotAfter))
+	field("Validity", validityPeriod(oldCert), validityPeriod(newCert))
+	field("Key Usage", joinOrNone(describeKeyUsage(oldCert.KeyUsage)), joinOrNone(describeKeyUsage(newCert.KeyUsage)))
+	field("Basic Constraints", basicConstraints(oldCert), basicConstraints(newCert))
+
+	d.SANsAdded, d.SANsRemoved = setDiff(diffSANs(oldCert), diffSANs(newCert))
+	d.EKUsAdded, d.EKUsRemoved = setDiff(certEKUNames(oldCert), certEKUNames(newCert))
+	d.ExtensionsAdded, d.ExtensionsRemoved = setDiff(extensionNames(oldCert), extensionNames(newCert))
+	return d
+}
+
+func validityPeriod(c *x509.Certificate) string {
+	days := int(c.NotAfter.Sub(c.NotBefore).Round(time.Hour).Hours() / 24)
+	return fmt.Sprintf("%d days", days)
+}
+
+func basicConstraints(c *x509.Certificate) string {
+	switch {
+	case !c.BasicConstraintsValid:
+		return "(none)"
+	case !c.IsCA:
+		return "CA:FALSE"
+	case c.MaxPathLen > 0 || c.MaxPathLenZero:
+		return fmt.Sprintf("CA:TRUE, pathlen:%d", c.MaxPathLen)
+	}
+	return "CA:TRUE"
+}
+
+// diffSANs is FormatSANsList with DNS names normalised: they compare
+// case-insensitively, and a trailing dot names the same host.
+func diffSANs(c *x509.Certificate) []string {
+	sans := FormatSANsList(c)
+	for i, san := range sans {
+		if name, ok := strings.CutPrefix(san, "DNS: "); ok {
+			sans[i] = "DNS: " + strings.TrimSuffix(strings.ToLower(name), ".")
+		}
+	}
+	return sans
+}
+
+func certEKUNames(c *x509.Certificate) []string {
+	names := describeExtKeyUsage(c.ExtKeyUsage)
+	for _, oid := range c.UnknownExtKeyUsage {
+		names = append(names, oid.String())
+	}
+	return names
+}
+
+func extensionNames(c *x509.Certificate) []string {
+	names := make([]string, 0, len(c.Extensions))
+	for _, ext := range c.Extensions {
+		names = append(names, extensionDisplayName(ext.Id))
+	}
+	return names
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "(none)"
+	}
+	return strings.Join(items, ", ")
+}
+
+// setDiff returns the entries only in newList and only in oldList, each in
+// its list's order. Both results are non-nil so they encode as JSON arrays.
+func setDiff(oldList, newList []string) (added, removed []string) {
+	added, removed = []string{}, []string{}
+	for _, v := range newList {
+		if !slices.Contains(oldList, v) {
+			added = append(added, v)
+		}
+	}
+	for _, v := range oldList {
+		if !slices.Contains(newList, v) {
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
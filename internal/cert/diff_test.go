@@ -0,0 +1,103 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+func TestDiffCertificates_Renewal(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue := func(serial int64, days int, sans []string, ekus []x509.ExtKeyUsage) *x509.Certificate {
+		t.Helper()
+		now := time.Now().Truncate(time.Second)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "app.example.com"},
+			NotBefore:    now,
+			NotAfter:     now.Add(time.Duration(days) * 24 * time.Hour),
+			DNSNames:     sans,
+			ExtKeyUsage:  ekus,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	oldCert := issue(1, 90, []string{"app.example.com", "www.example.com"}, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth})
+	newCert := issue(2, 47, []string{"app.example.com", "api.example.com"}, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth})
+
+	d := DiffCertificates(oldCert, newCert)
+	if d.Identical || !d.SameKey {
+		t.Fatalf("want a renewal reusing the key, got identical=%v same_key=%v", d.Identical, d.SameKey)
+	}
+	if !slices.Equal(d.SANsAdded, []string{"DNS: api.example.com"}) || !slices.Equal(d.SANsRemoved, []string{"DNS: www.example.com"}) || d.SANSuperset() {
+		t.Fatalf("SANs added %v removed %v", d.SANsAdded, d.SANsRemoved)
+	}
+	if len(d.EKUsAdded) != 0 || !slices.Equal(d.EKUsRemoved, []string{"Client Auth"}) {
+		t.Fatalf("EKUs added %v removed %v", d.EKUsAdded, d.EKUsRemoved)
+	}
+
+	changed := map[string]CertDiffField{}
+	for _, f := range d.Fields {
+		if f.Changed {
+			changed[f.Name] = f
+		}
+	}
+	if f := changed["Validity"]; f.Old != "90 days" || f.New != "47 days" {
+		t.Fatalf("validity: %+v", f)
+	}
+	for _, name := range []string{"Subject", "Key", "Issuer"} {
+		if _, ok := changed[name]; ok {
+			t.Fatalf("%s reported changed: %+v", name, changed[name])
+		}
+	}
+
+	// DNS names are case-insensitive and may carry a trailing dot.
+	recased := issue(3, 90, []string{"App.Example.com", "www.example.com."}, nil)
+	if d := DiffCertificates(oldCert, recased); len(d.SANsAdded) != 0 || len(d.SANsRemoved) != 0 || !d.SANSuperset() {
+		t.Fatalf("SANs added %v removed %v", d.SANsAdded, d.SANsRemoved)
+	}
+}
+
+func TestDiffFiles_AcrossEncodings(t *testing.T) {
+	chain := testutil.MakeChain(t)
+	der := testutil.MakeDERCert(t, chain.LeafPath)
+
+	d, err := DiffFiles(chain.LeafPath, der, "")
+	if err != nil {
+		t.Fatalf("DiffFiles: %v", err)
+	}
+	if !d.Identical || !d.SameKey {
+		t.Fatalf("PEM and DER of one cert should be identical: %+v", d)
+	}
+
+	d, err = DiffFiles(chain.LeafPath, chain.IntermediatePath, "")
+	if err != nil {
+		t.Fatalf("DiffFiles: %v", err)
+	}
+	if d.Identical || d.SameKey || !slices.Equal(d.SANsRemoved, []string{"DNS: app.test.local"}) {
+		t.Fatalf("leaf vs intermediate: %+v", d)
+	}
+
+	if _, err := DiffFiles(chain.LeafPath, chain.LeafKeyPath, ""); err == nil {
+		t.Fatalf("expected an error diffing against a key file")
+	}
+}
@@ -0,0 +1,188 @@
+package cert
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ExpiryStatus is a monitoring state for a certificate, named and numbered
+// as in Nagios plugins.
+type ExpiryStatus string
+
+const (
+	ExpiryOK       ExpiryStatus = "OK"
+	ExpiryWarning  ExpiryStatus = "WARNING"
+	ExpiryCritical ExpiryStatus = "CRITICAL"
+	ExpiryUnknown  ExpiryStatus = "UNKNOWN"
+)
+
+// ExitCode returns the Nagios plugin exit code for s: 0 OK, 1 WARNING,
+// 2 CRITICAL, 3 UNKNOWN.
+func (s ExpiryStatus) ExitCode() int {
+	switch s {
+	case ExpiryOK:
+		return 0
+	case ExpiryWarning:
+		return 1
+	case ExpiryCritical:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// expirySeverity orders states for rolling entries up into a report status.
+// UNKNOWN ranks below CRITICAL, so one unreadable file does not mask an
+// expiring certificate.
+var expirySeverity = map[ExpiryStatus]int{ExpiryOK: 0, ExpiryWarning: 1, ExpiryUnknown: 2, ExpiryCritical: 3}
+
+// ExpiryThresholds are the day counts at which a certificate becomes WARNING
+// and CRITICAL. CritDays must not exceed WarnDays.
+type ExpiryThresholds struct {
+	WarnDays int
+	CritDays int
+}
+
+// Validate reports thresholds that could never classify consistently.
+func (t ExpiryThresholds) Validate() error {
+	if t.WarnDays < 0 || t.CritDays < 0 {
+		return fmt.Errorf("thresholds must not be negative")
+	}
+	if t.CritDays > t.WarnDays {
+		return fmt.Errorf("critical threshold (%d days) exceeds warning threshold (%d days)", t.CritDays, t.WarnDays)
+	}
+	return nil
+}
+
+// ClassifyExpiry reports the state of a certificate expiring at notAfter. As
+// with Expiry, a certificate is OK for a threshold when it is still valid
+// that many days from now; an expired certificate is always CRITICAL.
+func ClassifyExpiry(notAfter, now time.Time, t ExpiryThresholds) ExpiryStatus {
+	days := func(n int) time.Time { return now.Add(time.Duration(n) * 24 * time.Hour) }
+	switch {
+	case !days(t.CritDays).Before(notAfter):
+		return ExpiryCritical
+	case !days(t.WarnDays).Before(notAfter):
+		return ExpiryWarning
+	default:
+		return ExpiryOK
+	}
+}
+
+// ExpiryReportOptions controls ExpiryReport.
+type ExpiryReportOptions struct {
+	ExpiryThresholds
+	// Recursive descends into subdirectories of directory arguments.
+	Recursive bool
+}
+
+// ExpiryReportEntry is the expiry state of one file. Error is set, and Status
+// is UNKNOWN, when the file could not be read.
+type ExpiryReportEntry struct {
+	Path      string       `json:"path"`
+	Subject   string       `json:"subject,omitempty"`
+	NotAfter  string       `json:"not_after,omitempty"`
+	DaysLeft  *int         `json:"days_left,omitempty"` // nil when valid forever
+	Status    ExpiryStatus `json:"status"`
+	Error     string       `json:"error,omitempty"`
+	ExpiresAt time.Time    `json:"-"`
+}
+
+// ExpiryReport is the expiry state of a set of files, rolled up to the worst
+// entry.
+type ExpiryReport struct {
+	Status   ExpiryStatus         `json:"status"`
+	WarnDays int                  `json:"warn_days"`
+	CritDays int                  `json:"crit_days"`
+	Counts   map[ExpiryStatus]int `json:"counts"`
+	Entries  []ExpiryReportEntry  `json:"entries"`
+}
+
+// expiryReportTypes are the file types that carry a certificate with an
+// expiry date; other files found in directories are ignored.
+var expiryReportTypes = map[FileType]bool{
+	FileTypeCert:       true,
+	FileTypeCombined:   true,
+	FileTypeDER:        true,
+	FileTypePFX:        true,
+	FileTypeP7B:        true,
+	FileTypeJKS:        true,
+	FileTypeK8sSecret:  true,
+	FileTypeKubeconfig: true,
+	FileTypeSSHCert:    true,
+}
+
+// ExpiryReport checks every certificate file named in paths, and those found
+// in any directories, against the thresholds. Files named explicitly are
+// always reported; files found in directories only when their type carries a
+// certificate. Each file contributes the certificate Expiry reads from it.
+func (e *Engine) ExpiryReport(ctx context.Context, paths []string, opts ExpiryReportOptions) (*ExpiryReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	report := &ExpiryReport{
+		Status:   ExpiryOK,
+		WarnDays: opts.WarnDays,
+		CritDays: opts.CritDays,
+		Counts:   map[ExpiryStatus]int{},
+		Entries:  []ExpiryReportEntry{},
+	}
+	add := func(entry ExpiryReportEntry) {
+		report.Entries = append(report.Entries, entry)
+		report.Counts[entry.Status]++
+		if expirySeverity[entry.Status] > expirySeverity[report.Status] {
+			report.Status = entry.Status
+		}
+	}
+
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			add(ExpiryReportEntry{Path: p, Status: ExpiryUnknown, Error: err.Error()})
+			continue
+		}
+		if !info.IsDir() {
+			add(e.expiryReportEntry(ctx, p, opts.ExpiryThresholds))
+			continue
+		}
+		err = walkScanFiles(p, ScanOptions{Recursive: opts.Recursive}, func(fp, _ string, err error) {
+			if err != nil {
+				add(ExpiryReportEntry{Path: fp, Status: ExpiryUnknown, Error: err.Error()})
+				return
+			}
+			if ft, err := DetectType(fp); err != nil || !expiryReportTypes[ft] {
+				return
+			}
+			add(e.expiryReportEntry(ctx, fp, opts.ExpiryThresholds))
+		})
+		if err != nil {
+			add(ExpiryReportEntry{Path: p, Status: ExpiryUnknown, Error: err.Error()})
+		}
+	}
+	return report, nil
+}
+
+func (e *Engine) expiryReportEntry(ctx context.Context, path string, t ExpiryThresholds) ExpiryReportEntry {
+	entry := ExpiryReportEntry{Path: path, Status: ExpiryUnknown}
+	r, err := e.Expiry(ctx, path, t.WarnDays)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+	if r.ExpiresAt.IsZero() {
+		entry.Error = "could not read certificate expiry date"
+		return entry
+	}
+	entry.Subject = r.Subject
+	if r.ExpiryDate == ExpiryForever {
+		entry.NotAfter = ExpiryForever
+	} else {
+		entry.NotAfter = r.ExpiresAt.UTC().Format(time.RFC3339)
+		entry.DaysLeft = &r.DaysLeft
+	}
+	entry.ExpiresAt = r.ExpiresAt
+	entry.Status = ClassifyExpiry(r.ExpiresAt, time.Now(), t)
+	return entry
+}
@@ -0,0 +1,77 @@
+package cert
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+func TestClassifyExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	th := ExpiryThresholds{WarnDays: 30, CritDays: 7}
+	for _, tc := range []struct {
+		notAfter time.Time
+		want     ExpiryStatus
+	}{
+		{now.AddDate(0, 0, 31), ExpiryOK},
+		{now.AddDate(0, 0, 30), ExpiryWarning},
+		{now.AddDate(0, 0, 8), ExpiryWarning},
+		{now.AddDate(0, 0, 7), ExpiryCritical},
+		{now.Add(-time.Hour), ExpiryCritical},
+	} {
+		if got := ClassifyExpiry(tc.notAfter, now, th); got != tc.want {
+			t.Errorf("ClassifyExpiry(%s) = %s, want %s", tc.notAfter, got, tc.want)
+		}
+	}
+	if (ExpiryThresholds{WarnDays: 5, CritDays: 10}).Validate() == nil {
+		t.Error("Validate() accepted crit > warn")
+	}
+}
+
+func TestExpiryReport(t *testing.T) {
+	chain := testutil.MakeChain(t) // root 10y, intermediate 60d, leaf 90d
+	ctx := context.Background()
+	opts := ExpiryReportOptions{ExpiryThresholds: ExpiryThresholds{WarnDays: 95, CritDays: 70}}
+
+	engines := map[string]*Engine{"go": newNativeTestEngine(t)}
+	if _, err := exec.LookPath("openssl"); err == nil {
+		engines["openssl"] = NewEngine(&OSExecutor{})
+	}
+	for name, eng := range engines {
+		missing := filepath.Join(chain.Dir, "missing.pem")
+		r, err := eng.ExpiryReport(ctx, []string{chain.Dir, missing}, opts)
+		if err != nil {
+			t.Fatalf("%s: ExpiryReport() error = %v", name, err)
+		}
+		got := map[string]ExpiryStatus{}
+		for _, e := range r.Entries {
+			got[e.Path] = e.Status
+		}
+		want := map[string]ExpiryStatus{
+			chain.RootPath:         ExpiryOK,
+			chain.IntermediatePath: ExpiryCritical,
+			chain.LeafPath:         ExpiryWarning,
+			missing:                ExpiryUnknown,
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s: entries = %+v, want %v", name, r.Entries, want)
+		}
+		for p, s := range want {
+			if got[p] != s {
+				t.Errorf("%s: %s = %q, want %s", name, filepath.Base(p), got[p], s)
+			}
+		}
+		if r.Status != ExpiryCritical || r.Status.ExitCode() != 2 || r.Counts[ExpiryUnknown] != 1 {
+			t.Errorf("%s: report status = %s, counts %v", name, r.Status, r.Counts)
+		}
+		for _, e := range r.Entries {
+			if e.Path == chain.LeafPath && (e.Subject != "CN = app.test.local" || e.ExpiresAt.IsZero()) {
+				t.Errorf("%s: leaf entry = %+v", name, e)
+			}
+		}
+	}
+}
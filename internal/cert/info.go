@@ -18,6 +18,12 @@ func (e *Engine) Summary(ctx context.Context, path string, password string) (*Ce
 		return nil, fmt.Errorf("detect type: %w", err)
 	}
 
+	// openssl has no keystore or manifest support, so JKS, Kubernetes
+	// Secrets and kubeconfigs are always read natively.
+	if ft == FileTypeJKS || ft == FileTypeK8sSecret || ft == FileTypeKubeconfig || (e.native() && isCertFileType(ft)) {
+		return nativeSummary(path, ft, password)
+	}
+
 	s := &CertSummary{
 		File:     path,
 		FileType: ft,
@@ -27,6 +33,23 @@ func (e *Engine) Summary(ctx context.Context, path string, password string) (*Ce
 	case FileTypeP7B:
 		return e.P7BSummary(ctx, path)
 
+	case FileTypeCSR:
+		// Summary fields for requests come from crypto/x509 on both backends;
+		// openssl has no single command that reports them all.
+		return nativeCSRSummary(path)
+
+	case FileTypeCRL:
+		// Likewise for revocation lists.
+		return nativeCRLSummary(path)
+
+	case FileTypeSSHCert:
+		// openssl cannot read OpenSSH certificates.
+		return nativeSSHCertSummary(path)
+
+	case FileTypeJWK:
+		// Nor JSON Web Keys.
+		return nativeJWKSummary(path)
+
 	case FileTypePFX:
 		// Extract cert from PFX then parse
 		extra := []ExtraFile{{Data: []byte(password)}}
@@ -71,6 +94,13 @@ func (e *Engine) Summary(ctx context.Context, path string, password string) (*Ce
 
 	case FileTypeKey:
 		s.KeyType = detectKeyType(path)
+		if s.KeyType == KeyTypeOpenSSH {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return s, err
+			}
+			openSSHKeySummary(s, data)
+		}
 		return s, nil
 
 	case FileTypePublicKey:
@@ -117,6 +147,18 @@ func (e *Engine) parseCertSummaryFromPEM(ctx context.Context, s *CertSummary, pe
 // PFXCertsPEM extracts all certificates from a PFX (including chain when present)
 // as PEM bytes. The password may be empty.
 func (e *Engine) PFXCertsPEM(ctx context.Context, path string, password string) ([]byte, error) {
+	if e.native() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read pfx certificates: %w", err)
+		}
+		_, certs, err := ParsePFXCertificates(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("read pfx certificates: %w", err)
+		}
+		return encodeCertsPEM(certs), nil
+	}
+
 	extra := []ExtraFile{{Data: []byte(password)}}
 	stdout, stderr, err := e.runPKCS12WithExtraFiles(ctx, extra,
 		"pkcs12", "-in", path, "-nokeys",
@@ -159,6 +201,9 @@ func detectKeyType(path string) KeyType {
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		line := scanner.Text()
+		if strings.Contains(line, "OPENSSH PRIVATE KEY") {
+			return KeyTypeOpenSSH
+		}
 		if strings.Contains(line, "RSA PRIVATE KEY") {
 			return KeyTypeRSA
 		}
@@ -176,6 +221,10 @@ func (e *Engine) Details(ctx context.Context, path string, password string) (*Ce
 		return nil, fmt.Errorf("detect type: %w", err)
 	}
 
+	if ft == FileTypeJKS || ft == FileTypeK8sSecret || ft == FileTypeKubeconfig || (e.native() && isCertFileType(ft)) {
+		return nativeDetails(path, ft, password)
+	}
+
 	d := &CertDetails{
 		File:     path,
 		FileType: ft,
@@ -223,6 +272,72 @@ func (e *Engine) Details(ctx context.Context, path string, password string) (*Ce
 			return d, err
 		}
 
+	case FileTypeCSR:
+		if e.native() {
+			csr, err := ParseCSRFile(path)
+			if err != nil {
+				return d, fmt.Errorf("read csr: %w", err)
+			}
+			d.RawText = RenderCSRText(csr)
+			return d, nil
+		}
+		args := []string{"req", "-in", path, "-text", "-noout"}
+		if !hasCSRMarker(path) {
+			args = append(args, "-inform", "DER")
+		}
+		stdout, _, err = e.exec.Run(ctx, args...)
+		if err != nil {
+			return d, err
+		}
+
+	case FileTypeCRL:
+		if e.native() {
+			crl, err := ParseCRLFile(path)
+			if err != nil {
+				return d, fmt.Errorf("read crl: %w", err)
+			}
+			d.RawText = RenderCRLText(crl)
+			return d, nil
+		}
+		args := []string{"crl", "-in", path, "-text", "-noout"}
+		if !hasCRLMarker(path) {
+			args = append(args, "-inform", "DER")
+		}
+		stdout, _, err = e.exec.Run(ctx, args...)
+		if err != nil {
+			return d, err
+		}
+
+	case FileTypeSSHCert:
+		c, comment, err := readSSHCertificate(path)
+		if err != nil {
+			return d, err
+		}
+		d.RawText = RenderSSHCertText(c, comment)
+		return d, nil
+
+	case FileTypeJWK:
+		keys, err := readJWKs(path)
+		if err != nil {
+			return d, err
+		}
+		d.RawText = JWKDetailsText(keys)
+		return d, nil
+
+	case FileTypeKey:
+		// Only OpenSSH keys have a description worth showing; it comes
+		// from the cleartext header, so no password is needed.
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return d, err
+		}
+		info := readOpenSSHKeyInfo(data)
+		if info == nil {
+			return d, fmt.Errorf("cannot show full details for file type: %s", ft)
+		}
+		d.RawText = RenderOpenSSHKeyText(info)
+		return d, nil
+
 	case FileTypePublicKey:
 		// Prefer OpenSSH formatting (common: ssh-ed25519 ...).
 		line, lerr := ReadFirstNonEmptyLine(path)
@@ -251,6 +366,14 @@ func (e *Engine) Details(ctx context.Context, path string, password string) (*Ce
 
 		// PEM public key: try to render using openssl.
 		if hasPublicKeyMarker(path) {
+			if e.native() {
+				text, err := nativePublicKeyText(path)
+				if err != nil {
+					return d, err
+				}
+				d.RawText = text
+				return d, nil
+			}
 			stdout, _, err = e.exec.Run(ctx, "pkey", "-pubin", "-in", path, "-text", "-noout")
 			if err != nil {
 				return d, err
@@ -276,15 +399,21 @@ func (e *Engine) Expiry(ctx context.Context, path string, days int) (*ExpiryResu
 		return nil, fmt.Errorf("detect type: %w", err)
 	}
 
+	// "openssl x509" reads neither containers, keystores, manifests nor
+	// SSH certificates.
+	if e.native() || ft == FileTypeJKS || ft == FileTypePFX || ft == FileTypeP7B || ft == FileTypeK8sSecret || ft == FileTypeKubeconfig || ft == FileTypeSSHCert || ft == FileTypeJWK {
+		return nativeExpiry(path, ft, days)
+	}
+
 	result := &ExpiryResult{}
 
 	// Get the expiry date
 	var args []string
 	switch ft {
 	case FileTypeDER:
-		args = []string{"x509", "-in", path, "-inform", "DER", "-noout", "-enddate"}
+		args = []string{"x509", "-in", path, "-inform", "DER", "-noout", "-subject", "-enddate"}
 	default:
-		args = []string{"x509", "-in", path, "-noout", "-enddate"}
+		args = []string{"x509", "-in", path, "-noout", "-subject", "-enddate"}
 	}
 
 	stdout, _, err := e.exec.Run(ctx, args...)
@@ -292,11 +421,18 @@ func (e *Engine) Expiry(ctx context.Context, path string, days int) (*ExpiryResu
 		return nil, fmt.Errorf("read certificate expiry: %w", err)
 	}
 
-	// Parse "notAfter=Mon DD HH:MM:SS YYYY GMT"
-	line := strings.TrimSpace(string(stdout))
-	parts := strings.SplitN(line, "=", 2)
-	if len(parts) == 2 {
-		result.ExpiryDate = strings.TrimSpace(parts[1])
+	// Parse "subject=..." and "notAfter=Mon DD HH:MM:SS YYYY GMT"
+	for _, line := range strings.Split(string(stdout), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "subject":
+			result.Subject = strings.TrimSpace(value)
+		case "notAfter":
+			result.ExpiryDate = strings.TrimSpace(value)
+		}
 	}
 
 	// Parse the date
@@ -0,0 +1,494 @@
+package cert
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf16"
+)
+
+// Java keystore (JKS / JCEKS) support. Both formats share the same layout:
+//
+//	magic | version | count | entries... | SHA-1 integrity digest
+//
+// Certificates are stored in the clear, so aliases and chains can be listed
+// without a password. Private keys are protected with Sun's proprietary
+// KeyProtector (JKS) or PBEWithMD5AndTripleDES (JCEKS).
+
+const (
+	jksMagic   uint32 = 0xFEEDFEED
+	jceksMagic uint32 = 0xCECECECE
+
+	jksTagPrivateKey  uint32 = 1
+	jksTagTrustedCert uint32 = 2
+	jksTagSecretKey   uint32 = 3
+
+	jksIntegritySalt = "Mighty Aphrodite"
+)
+
+var (
+	oidJKSKeyProtector   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1}
+	oidJCEKSKeyProtector = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 19, 1}
+)
+
+var (
+	// ErrJKSIncorrectPassword indicates the keystore integrity check failed.
+	ErrJKSIncorrectPassword = errors.New("keystore was tampered with, or password was incorrect")
+
+	// ErrNotJKS indicates the data is not a JKS or JCEKS keystore.
+	ErrNotJKS = errors.New("file is not a JKS or JCEKS keystore")
+
+	// ErrKeystorePasswordRequired is returned when writing a keystore without
+	// a password; Java tooling refuses empty store passwords.
+	ErrKeystorePasswordRequired = errors.New("keystore password is required")
+)
+
+// KeystoreEntryType describes what a keystore alias holds.
+type KeystoreEntryType string
+
+const (
+	KeystoreEntryPrivateKey  KeystoreEntryType = "private-key"
+	KeystoreEntryTrustedCert KeystoreEntryType = "trusted-cert"
+	KeystoreEntrySecretKey   KeystoreEntryType = "secret-key"
+)
+
+// KeystoreEntry is a single alias from a Java keystore.
+type KeystoreEntry struct {
+	Alias   string
+	Type    KeystoreEntryType
+	Created time.Time
+	// Chain holds the certificate chain (leaf first) for private-key entries,
+	// or the single certificate for trusted-cert entries.
+	Chain []*x509.Certificate
+
+	protectedKey []byte
+}
+
+// Keystore is a parsed JKS or JCEKS keystore.
+type Keystore struct {
+	Format  string // "JKS" or "JCEKS"
+	Entries []KeystoreEntry
+}
+
+// IsJKSBytes reports whether data starts with a JKS or JCEKS magic number.
+func IsJKSBytes(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	m := binary.BigEndian.Uint32(data)
+	return m == jksMagic || m == jceksMagic
+}
+
+// ParseKeystore parses a JKS or JCEKS keystore. When password is non-empty the
+// integrity digest is verified; with an empty password the entries are still
+// listed (as "keytool -list" does) but the keystore is not authenticated.
+func ParseKeystore(data []byte, password string) (*Keystore, error) {
+	if !IsJKSBytes(data) {
+		return nil, ErrNotJKS
+	}
+	if len(data) < 12+sha1.Size {
+		return nil, fmt.Errorf("%w: truncated", ErrNotJKS)
+	}
+
+	body := data[:len(data)-sha1.Size]
+	if password != "" {
+		want := data[len(data)-sha1.Size:]
+		if subtle.ConstantTimeCompare(jksIntegrityDigest(body, password), want) != 1 {
+			return nil, ErrJKSIncorrectPassword
+		}
+	}
+
+	r := &jksReader{buf: body}
+	magic := r.u32()
+	version := r.u32()
+	count := r.u32()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if version != 1 && version != 2 {
+		return nil, fmt.Errorf("unsupported keystore version %d", version)
+	}
+
+	ks := &Keystore{Format: "JKS"}
+	if magic == jceksMagic {
+		ks.Format = "JCEKS"
+	}
+
+	for i := uint32(0); i < count; i++ {
+		tag := r.u32()
+		e := KeystoreEntry{Alias: r.utf()}
+		e.Created = time.UnixMilli(int64(r.u64())).UTC()
+
+		switch tag {
+		case jksTagPrivateKey:
+			e.Type = KeystoreEntryPrivateKey
+			e.protectedKey = r.bytes(int(r.u32()))
+			n := r.u32()
+			for j := uint32(0); j < n && r.err == nil; j++ {
+				c, err := r.cert(version)
+				if err != nil {
+					return nil, fmt.Errorf("alias %q: %w", e.Alias, err)
+				}
+				e.Chain = append(e.Chain, c)
+			}
+		case jksTagTrustedCert:
+			e.Type = KeystoreEntryTrustedCert
+			c, err := r.cert(version)
+			if err != nil {
+				return nil, fmt.Errorf("alias %q: %w", e.Alias, err)
+			}
+			e.Chain = []*x509.Certificate{c}
+		case jksTagSecretKey:
+			// JCEKS secret keys are Java-serialised objects with no length
+			// prefix. We can only step over one when it is the final entry.
+			e.Type = KeystoreEntrySecretKey
+			if i != count-1 {
+				return nil, fmt.Errorf("alias %q: JCEKS secret-key entries are only supported as the last entry", e.Alias)
+			}
+			r.off = len(r.buf)
+		default:
+			return nil, fmt.Errorf("unknown keystore entry tag %d", tag)
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		ks.Entries = append(ks.Entries, e)
+	}
+	return ks, nil
+}
+
+// Certificates returns every certificate in the keystore, in entry order.
+func (ks *Keystore) Certificates() []*x509.Certificate {
+	var out []*x509.Certificate
+	for _, e := range ks.Entries {
+		out = append(out, e.Chain...)
+	}
+	return out
+}
+
+// Entry returns the entry with the given alias (case-insensitive, as Java does).
+func (ks *Keystore) Entry(alias string) (*KeystoreEntry, bool) {
+	for i := range ks.Entries {
+		if equalFoldASCII(ks.Entries[i].Alias, alias) {
+			return &ks.Entries[i], true
+		}
+	}
+	return nil, false
+}
+
+// PrivateKey decrypts the private key of a private-key entry.
+func (e *KeystoreEntry) PrivateKey(password string) (crypto.Signer, error) {
+	if e.Type != KeystoreEntryPrivateKey {
+		return nil, fmt.Errorf("alias %q is not a private-key entry", e.Alias)
+	}
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(e.protectedKey, &info); err != nil {
+		return nil, fmt.Errorf("alias %q: parse protected key: %w", e.Alias, err)
+	}
+
+	var (
+		plain []byte
+		err   error
+	)
+	switch {
+	case info.Algorithm.Algorithm.Equal(oidJKSKeyProtector):
+		plain, err = jksUnprotectKey(info.EncryptedData, password)
+	case info.Algorithm.Algorithm.Equal(oidJCEKSKeyProtector):
+		plain, err = jceksUnprotectKey(info, password)
+	default:
+		return nil, fmt.Errorf("alias %q: unsupported key protection %s", e.Alias, info.Algorithm.Algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("alias %q: %w", e.Alias, err)
+	}
+	return parsePrivateKeyDER(plain)
+}
+
+// keystoreWriteEntry is one alias to be written by encodeJKS. A nil Key makes
+// it a trusted-cert entry holding Chain[0].
+type keystoreWriteEntry struct {
+	Alias string
+	Key   crypto.Signer
+	Chain []*x509.Certificate
+}
+
+// encodeJKS serialises entries as a version 2 JKS keystore. Private keys are
+// protected with the store password, which is what keytool does by default.
+func encodeJKS(entries []keystoreWriteEntry, password string, created time.Time) ([]byte, error) {
+	w := &jksWriter{}
+	w.u32(jksMagic)
+	w.u32(2)
+	w.u32(uint32(len(entries)))
+
+	ms := uint64(created.UnixMilli())
+	for _, e := range entries {
+		if len(e.Chain) == 0 {
+			return nil, fmt.Errorf("alias %q: no certificate", e.Alias)
+		}
+		if e.Key == nil {
+			w.u32(jksTagTrustedCert)
+			w.utf(e.Alias)
+			w.u64(ms)
+			w.cert(e.Chain[0])
+			continue
+		}
+
+		pkcs8, err := x509.MarshalPKCS8PrivateKey(e.Key)
+		if err != nil {
+			return nil, fmt.Errorf("alias %q: marshal private key: %w", e.Alias, err)
+		}
+		protected, err := jksProtectKey(pkcs8, password)
+		if err != nil {
+			return nil, fmt.Errorf("alias %q: %w", e.Alias, err)
+		}
+		w.u32(jksTagPrivateKey)
+		w.utf(e.Alias)
+		w.u64(ms)
+		w.u32(uint32(len(protected)))
+		w.buf.Write(protected)
+		w.u32(uint32(len(e.Chain)))
+		for _, c := range e.Chain {
+			w.cert(c)
+		}
+	}
+
+	w.buf.Write(jksIntegrityDigest(w.buf.Bytes(), password))
+	return w.buf.Bytes(), nil
+}
+
+// jksProtectKey applies Sun's KeyProtector to a PKCS#8 key.
+func jksProtectKey(plain []byte, password string) ([]byte, error) {
+	salt := make([]byte, sha1.Size)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	pw := utf16BEPassword(password)
+	enc := make([]byte, len(plain))
+	xorKeystream(enc, plain, pw, salt)
+
+	h := sha1.New()
+	h.Write(pw)
+	h.Write(plain)
+
+	data := make([]byte, 0, len(salt)+len(enc)+sha1.Size)
+	data = append(data, salt...)
+	data = append(data, enc...)
+	data = h.Sum(data)
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkixAlgorithm{Algorithm: oidJKSKeyProtector, Parameters: asn1.NullRawValue},
+		EncryptedData: data,
+	})
+}
+
+// jksUnprotectKey reverses Sun's KeyProtector: a SHA-1 keystream XORed with
+// the PKCS#8 key, framed by a 20-byte salt and a 20-byte check digest.
+func jksUnprotectKey(protected []byte, password string) ([]byte, error) {
+	if len(protected) < 2*sha1.Size {
+		return nil, errors.New("protected key is truncated")
+	}
+	pw := utf16BEPassword(password)
+	salt := protected[:sha1.Size]
+	enc := protected[sha1.Size : len(protected)-sha1.Size]
+	check := protected[len(protected)-sha1.Size:]
+
+	plain := make([]byte, len(enc))
+	xorKeystream(plain, enc, pw, salt)
+
+	h := sha1.New()
+	h.Write(pw)
+	h.Write(plain)
+	if subtle.ConstantTimeCompare(h.Sum(nil), check) != 1 {
+		return nil, ErrKeyIncorrectPassword
+	}
+	return plain, nil
+}
+
+func xorKeystream(dst, src, pw, salt []byte) {
+	digest := append([]byte(nil), salt...)
+	for off := 0; off < len(src); off += sha1.Size {
+		h := sha1.New()
+		h.Write(pw)
+		h.Write(digest)
+		digest = h.Sum(nil)
+		for i := 0; i < sha1.Size && off+i < len(src); i++ {
+			dst[off+i] = src[off+i] ^ digest[i]
+		}
+	}
+}
+
+type jceksPBEParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+// jceksUnprotectKey decrypts a PBEWithMD5AndTripleDES-protected key.
+func jceksUnprotectKey(info encryptedPrivateKeyInfo, password string) ([]byte, error) {
+	var p jceksPBEParams
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &p); err != nil {
+		return nil, fmt.Errorf("parse PBE parameters: %w", err)
+	}
+	key, iv, err := jceksDeriveKey([]byte(password), p.Salt, p.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	block, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(info.EncryptedData)%block.BlockSize() != 0 || len(info.EncryptedData) == 0 {
+		return nil, errors.New("malformed protected key")
+	}
+	plain := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, info.EncryptedData)
+	plain, err = pkcs7Unpad(plain, block.BlockSize())
+	if err != nil {
+		return nil, ErrKeyIncorrectPassword
+	}
+	return plain, nil
+}
+
+// jceksDeriveKey implements the SunJCE PBEWithMD5AndTripleDES key derivation,
+// including its historical salt-inversion quirk.
+func jceksDeriveKey(password, salt []byte, iterations int) (key, iv []byte, err error) {
+	if len(salt) != 8 {
+		return nil, nil, fmt.Errorf("unexpected PBE salt length %d", len(salt))
+	}
+	s := append([]byte(nil), salt...)
+	if bytes.Equal(s[:4], s[4:]) {
+		// Matches com.sun.crypto.provider.PBES1Core, bug and all.
+		for i := 0; i < 2; i++ {
+			tmp := s[i]
+			s[i] = s[3-i]
+			s[2] = tmp
+		}
+	}
+
+	out := make([]byte, 0, 32)
+	for i := 0; i < 2; i++ {
+		h := s[i*4 : (i+1)*4]
+		for j := 0; j < iterations; j++ {
+			d := md5.New()
+			d.Write(h)
+			d.Write(password)
+			h = d.Sum(nil)
+		}
+		out = append(out, h...)
+	}
+	return out[:24], out[24:32], nil
+}
+
+func jksIntegrityDigest(body []byte, password string) []byte {
+	h := sha1.New()
+	h.Write(utf16BEPassword(password))
+	h.Write([]byte(jksIntegritySalt))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+func utf16BEPassword(password string) []byte {
+	units := utf16.Encode([]rune(password))
+	out := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.BigEndian.PutUint16(out[2*i:], u)
+	}
+	return out
+}
+
+func equalFoldASCII(a, b string) bool {
+	return bytes.EqualFold([]byte(a), []byte(b))
+}
+
+// jksReader is a sticky-error big-endian reader over keystore bytes.
+type jksReader struct {
+	buf []byte
+	off int
+	err error
+}
+
+func (r *jksReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.off+n > len(r.buf) {
+		r.err = errors.New("keystore is truncated")
+		return nil
+	}
+	b := r.buf[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *jksReader) u16() uint16 {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (r *jksReader) u32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *jksReader) u64() uint64 {
+	b := r.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+// utf reads a Java DataInput "modified UTF-8" string. Aliases are ASCII in
+// practice, so it is decoded as plain UTF-8.
+func (r *jksReader) utf() string {
+	return string(r.bytes(int(r.u16())))
+}
+
+func (r *jksReader) cert(version uint32) (*x509.Certificate, error) {
+	if version == 2 {
+		if t := r.utf(); r.err == nil && t != "X.509" {
+			return nil, fmt.Errorf("unsupported certificate type %q", t)
+		}
+	}
+	der := r.bytes(int(r.u32()))
+	if r.err != nil {
+		return nil, r.err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// jksWriter is the big-endian counterpart of jksReader.
+type jksWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *jksWriter) u16(v uint16) { w.buf.Write(binary.BigEndian.AppendUint16(nil, v)) }
+func (w *jksWriter) u32(v uint32) { w.buf.Write(binary.BigEndian.AppendUint32(nil, v)) }
+func (w *jksWriter) u64(v uint64) { w.buf.Write(binary.BigEndian.AppendUint64(nil, v)) }
+
+func (w *jksWriter) utf(s string) {
+	w.u16(uint16(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *jksWriter) cert(c *x509.Certificate) {
+	w.utf("X.509")
+	w.u32(uint32(len(c.Raw)))
+	w.buf.Write(c.Raw)
+}
@@ -0,0 +1,503 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+type testKeystoreEntry struct {
+	alias string
+	key   crypto.Signer // nil for trusted-cert entries
+	chain []*x509.Certificate
+}
+
+// buildTestKeystore writes a JKS (or JCEKS) keystore the way keytool does, so
+// the parser can be exercised without a JDK on the test machine.
+func buildTestKeystore(t *testing.T, jceks bool, password string, entries []testKeystoreEntry) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	u16 := func(v uint16) { _ = binary.Write(&b, binary.BigEndian, v) }
+	u32 := func(v uint32) { _ = binary.Write(&b, binary.BigEndian, v) }
+	u64 := func(v uint64) { _ = binary.Write(&b, binary.BigEndian, v) }
+	utf := func(s string) { u16(uint16(len(s))); b.WriteString(s) }
+	writeCert := func(c *x509.Certificate) {
+		utf("X.509")
+		u32(uint32(len(c.Raw)))
+		b.Write(c.Raw)
+	}
+
+	if jceks {
+		u32(jceksMagic)
+	} else {
+		u32(jksMagic)
+	}
+	u32(2)
+	u32(uint32(len(entries)))
+
+	created := uint64(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC).UnixMilli())
+	for _, e := range entries {
+		if e.key == nil {
+			u32(jksTagTrustedCert)
+			utf(e.alias)
+			u64(created)
+			writeCert(e.chain[0])
+			continue
+		}
+
+		pkcs8, err := x509.MarshalPKCS8PrivateKey(e.key)
+		if err != nil {
+			t.Fatalf("marshal key: %v", err)
+		}
+		var protected []byte
+		if jceks {
+			protected = testJCEKSProtect(t, pkcs8, password)
+		} else if protected, err = jksProtectKey(pkcs8, password); err != nil {
+			t.Fatal(err)
+		}
+
+		u32(jksTagPrivateKey)
+		utf(e.alias)
+		u64(created)
+		u32(uint32(len(protected)))
+		b.Write(protected)
+		u32(uint32(len(e.chain)))
+		for _, c := range e.chain {
+			writeCert(c)
+		}
+	}
+
+	b.Write(jksIntegrityDigest(b.Bytes(), password))
+	return b.Bytes()
+}
+
+func testJCEKSProtect(t *testing.T, plain []byte, password string) []byte {
+	t.Helper()
+	salt := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	key, iv, err := jceksDeriveKey([]byte(password), salt, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pad := block.BlockSize() - len(plain)%block.BlockSize()
+	padded := append(append([]byte{}, plain...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	enc := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(enc, padded)
+
+	params, err := asn1.Marshal(jceksPBEParams{Salt: salt, Iterations: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := asn1.Marshal(struct {
+		Algorithm     pkixAlgorithm
+		EncryptedData []byte
+	}{
+		Algorithm:     pkixAlgorithm{Algorithm: oidJCEKSKeyProtector, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: enc,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func loadTestPair(t *testing.T, pair *testutil.CertPair) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+	c, err := ParseCertFile(pair.CertPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePrivateKeyFile(pair.KeyPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, key
+}
+
+func writeTestKeystore(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestParseKeystore_JKSAndJCEKS(t *testing.T) {
+	rsaCert, rsaKey := loadTestPair(t, testutil.MakeCertPair(t))
+	ecCert, _ := loadTestPair(t, testutil.MakeECCertPair(t))
+
+	for _, tc := range []struct {
+		name   string
+		jceks  bool
+		format string
+	}{
+		{"jks", false, "JKS"},
+		{"jceks", true, "JCEKS"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := buildTestKeystore(t, tc.jceks, "changeit", []testKeystoreEntry{
+				{alias: "server", key: rsaKey, chain: []*x509.Certificate{rsaCert, ecCert}},
+				{alias: "root", chain: []*x509.Certificate{ecCert}},
+			})
+
+			ks, err := ParseKeystore(data, "changeit")
+			if err != nil {
+				t.Fatalf("ParseKeystore() error = %v", err)
+			}
+			if ks.Format != tc.format {
+				t.Errorf("Format = %q, want %q", ks.Format, tc.format)
+			}
+			if len(ks.Entries) != 2 {
+				t.Fatalf("got %d entries, want 2", len(ks.Entries))
+			}
+			server, ok := ks.Entry("SERVER")
+			if !ok {
+				t.Fatal("Entry(SERVER) not found (aliases are case-insensitive)")
+			}
+			if server.Type != KeystoreEntryPrivateKey || len(server.Chain) != 2 {
+				t.Errorf("server entry = %s with %d certs", server.Type, len(server.Chain))
+			}
+			if got := server.Created.Format(time.RFC3339); got != "2024-05-01T12:00:00Z" {
+				t.Errorf("Created = %s", got)
+			}
+			if ks.Entries[1].Type != KeystoreEntryTrustedCert {
+				t.Errorf("root entry type = %s", ks.Entries[1].Type)
+			}
+
+			key, err := server.PrivateKey("changeit")
+			if err != nil {
+				t.Fatalf("PrivateKey() error = %v", err)
+			}
+			if !publicKeysEqual(key.Public(), rsaCert.PublicKey) {
+				t.Error("decrypted key does not match certificate")
+			}
+			if _, err := server.PrivateKey("wrong"); !errors.Is(err, ErrKeyIncorrectPassword) {
+				t.Errorf("PrivateKey(wrong) error = %v, want ErrKeyIncorrectPassword", err)
+			}
+		})
+	}
+}
+
+func TestParseKeystore_Passwords(t *testing.T) {
+	c, key := loadTestPair(t, testutil.MakeCertPair(t))
+	data := buildTestKeystore(t, false, "changeit", []testKeystoreEntry{
+		{alias: "server", key: key, chain: []*x509.Certificate{c}},
+	})
+
+	if _, err := ParseKeystore(data, "wrong"); !errors.Is(err, ErrJKSIncorrectPassword) {
+		t.Errorf("wrong password error = %v, want ErrJKSIncorrectPassword", err)
+	}
+	// Like "keytool -list" without -storepass: entries are listed unauthenticated.
+	if ks, err := ParseKeystore(data, ""); err != nil || len(ks.Entries) != 1 {
+		t.Errorf("empty password: ks=%v err=%v", ks, err)
+	}
+	if _, err := ParseKeystore(data[:30], ""); err == nil {
+		t.Error("expected error for truncated keystore")
+	}
+	if _, err := ParseKeystore([]byte("not a keystore at all, really"), ""); !errors.Is(err, ErrNotJKS) {
+		t.Errorf("non-keystore error = %v, want ErrNotJKS", err)
+	}
+}
+
+func TestDetectType_JKS(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	c, key := loadTestPair(t, pair)
+	data := buildTestKeystore(t, false, "changeit", []testKeystoreEntry{
+		{alias: "server", key: key, chain: []*x509.Certificate{c}},
+	})
+
+	for _, name := range []string{"store.jks", "store.jceks", "store.bin"} {
+		p := writeTestKeystore(t, name, data)
+		if ft, err := DetectType(p); err != nil || ft != FileTypeJKS {
+			t.Errorf("DetectType(%s) = %s, %v; want jks", name, ft, err)
+		}
+		if ft := DetectTypeFromNameAndBytes(name, data); ft != FileTypeJKS {
+			t.Errorf("DetectTypeFromNameAndBytes(%s) = %s; want jks", name, ft)
+		}
+	}
+
+	// Modern keytool writes PKCS#12 even when the file is called .jks.
+	pfx, err := os.ReadFile(testutil.MakePFX(t, pair, "changeit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := writeTestKeystore(t, "modern.jks", pfx)
+	if ft, _ := DetectType(p); ft != FileTypePFX {
+		t.Errorf("DetectType(PKCS#12 .jks) = %s, want pfx", ft)
+	}
+}
+
+func TestKeystore_SummaryDetailsLintExpiry(t *testing.T) {
+	c, key := loadTestPair(t, testutil.MakeCertPair(t))
+	data := buildTestKeystore(t, false, "changeit", []testKeystoreEntry{
+		{alias: "server", key: key, chain: []*x509.Certificate{c}},
+		{alias: "root", chain: []*x509.Certificate{c}},
+	})
+	p := writeTestKeystore(t, "store.jks", data)
+
+	// JKS never shells out, even on the openssl backend.
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+
+	s, err := eng.Summary(ctx, p, "changeit")
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if s.KeystoreType != "JKS" || len(s.KeystoreEntries) != 2 {
+		t.Fatalf("summary keystore = %q with %d entries", s.KeystoreType, len(s.KeystoreEntries))
+	}
+	if e := s.KeystoreEntries[0]; e.Alias != "server" || e.Type != KeystoreEntryPrivateKey || len(e.Chain) != 1 {
+		t.Errorf("entry[0] = %+v", e)
+	}
+	if !strings.Contains(s.Subject, "test.local") {
+		t.Errorf("Subject = %q", s.Subject)
+	}
+
+	d, err := eng.Details(ctx, p, "")
+	if err != nil {
+		t.Fatalf("Details() error = %v", err)
+	}
+	for _, want := range []string{"Your keystore contains 2 entries", "Alias name: server", "Entry type: PrivateKeyEntry", "Entry type: trustedCertEntry", "Certificate:"} {
+		if !strings.Contains(d.RawText, want) {
+			t.Errorf("Details missing %q", want)
+		}
+	}
+
+	exp, err := eng.Expiry(ctx, p, 30)
+	if err != nil {
+		t.Fatalf("Expiry() error = %v", err)
+	}
+	if !exp.Valid {
+		t.Errorf("Expiry() = %+v, want valid", exp)
+	}
+
+	lr, err := LintFile(p)
+	if err != nil {
+		t.Fatalf("LintFile() error = %v", err)
+	}
+	for _, issue := range lr.Issues {
+		if !strings.HasPrefix(issue.Message, `alias "`) {
+			t.Errorf("lint message not alias-prefixed: %q", issue.Message)
+		}
+	}
+
+	fs, err := SummaryFromBytesWithPassword(p, data, "changeit")
+	if err != nil || fs.KeystoreType != "JKS" {
+		t.Errorf("SummaryFromBytesWithPassword() = %+v, %v", fs, err)
+	}
+}
+
+func TestFromJKS(t *testing.T) {
+	c, key := loadTestPair(t, testutil.MakeCertPair(t))
+	ecCert, _ := loadTestPair(t, testutil.MakeECCertPair(t))
+	data := buildTestKeystore(t, true, "changeit", []testKeystoreEntry{
+		{alias: "server", key: key, chain: []*x509.Certificate{c, ecCert}},
+		{alias: "Root CA", chain: []*x509.Certificate{ecCert}},
+	})
+	p := writeTestKeystore(t, "store.jceks", data)
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+
+	outDir := filepath.Join(t.TempDir(), "out")
+	res, err := eng.FromJKS(ctx, p, outDir, "changeit", "", "")
+	if err != nil {
+		t.Fatalf("FromJKS() error = %v", err)
+	}
+	if len(res.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(res.Entries))
+	}
+	srv := res.Entries[0]
+	if srv.CertFile != filepath.Join(outDir, "store-server.crt") || srv.CAFile != filepath.Join(outDir, "store-server-ca.crt") {
+		t.Errorf("server files = %+v", srv)
+	}
+	if _, err := ParsePrivateKeyFile(srv.KeyFile, ""); err != nil {
+		t.Errorf("extracted key unreadable: %v", err)
+	}
+	if info, err := os.Stat(srv.KeyFile); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("key file mode = %v, %v", info, err)
+	}
+	if got := res.Entries[1].CertFile; got != filepath.Join(outDir, "store-Root_CA.crt") {
+		t.Errorf("trusted cert file = %q", got)
+	}
+
+	// A single alias uses the from-pfx names, and existing files are not overwritten.
+	single := filepath.Join(t.TempDir(), "single")
+	res, err = eng.FromJKS(ctx, p, single, "changeit", "", "server")
+	if err != nil {
+		t.Fatalf("FromJKS(alias) error = %v", err)
+	}
+	if res.Entries[0].KeyFile != filepath.Join(single, "store.key") {
+		t.Errorf("single-entry key file = %q", res.Entries[0].KeyFile)
+	}
+	if _, err := eng.FromJKS(ctx, p, single, "changeit", "", "server"); err == nil {
+		t.Error("expected error when outputs already exist")
+	}
+
+	if _, err := eng.FromJKS(ctx, p, t.TempDir(), "changeit", "", "missing"); err == nil {
+		t.Error("expected error for unknown alias")
+	}
+	if _, err := eng.FromJKS(ctx, p, t.TempDir(), "changeit", "wrong", ""); !errors.Is(err, ErrKeyIncorrectPassword) {
+		t.Errorf("wrong key password error = %v", err)
+	}
+
+	// Every output is checked before any is written: an existing CA file,
+	// or two aliases that sanitise to the same name, leave nothing behind.
+	clash := t.TempDir()
+	if err := os.WriteFile(filepath.Join(clash, "store-server-ca.crt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eng.FromJKS(ctx, p, clash, "changeit", "", ""); err == nil {
+		t.Error("expected error when the CA file already exists")
+	}
+	collide := writeTestKeystore(t, "collide.jks", buildTestKeystore(t, false, "changeit", []testKeystoreEntry{
+		{alias: "server", key: key, chain: []*x509.Certificate{c}},
+		{alias: "root ca", chain: []*x509.Certificate{ecCert}},
+		{alias: "root/ca", chain: []*x509.Certificate{ecCert}},
+	}))
+	if _, err := eng.FromJKS(ctx, collide, clash, "changeit", "", ""); err == nil || !strings.Contains(err.Error(), "root_ca") {
+		t.Errorf("expected an alias collision error, got %v", err)
+	}
+	if names, _ := os.ReadDir(clash); len(names) != 1 {
+		t.Errorf("failed extractions wrote files: %v", names)
+	}
+}
+
+func TestToJKS_RoundTrip(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	c, _ := loadTestPair(t, pair)
+	ca := testutil.MakeECCertPair(t)
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+
+	out := filepath.Join(t.TempDir(), "app.jks")
+	res, err := eng.ToJKS(ctx, pair.CertPath, pair.KeyPath, out, "changeit", ca.CertPath, "", "")
+	if err != nil {
+		t.Fatalf("ToJKS() error = %v", err)
+	}
+	if len(res.Aliases) != 1 || res.Aliases[0] != "test.local" {
+		t.Errorf("Aliases = %v, want [test.local]", res.Aliases)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := ParseKeystore(data, "changeit")
+	if err != nil {
+		t.Fatalf("ParseKeystore() error = %v", err)
+	}
+	entry, ok := ks.Entry("test.local")
+	if !ok || entry.Type != KeystoreEntryPrivateKey || len(entry.Chain) != 2 {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if !entry.Chain[0].Equal(c) {
+		t.Error("leaf certificate mismatch")
+	}
+	key, err := entry.PrivateKey("changeit")
+	if err != nil {
+		t.Fatalf("PrivateKey() error = %v", err)
+	}
+	if !publicKeysEqual(key.Public(), c.PublicKey) {
+		t.Error("stored key does not match certificate")
+	}
+
+	if _, err := eng.ToJKS(ctx, pair.CertPath, pair.KeyPath, out, "changeit", "", "", "x"); !IsOutputExists(err) {
+		t.Errorf("expected OutputExistsError, got %v", err)
+	}
+	other := filepath.Join(t.TempDir(), "other.jks")
+	res, err = eng.ToJKS(ctx, pair.CertPath, pair.KeyPath, other, "changeit", "", "", "My Server")
+	if err != nil || res.Aliases[0] != "my server" {
+		t.Errorf("explicit alias: res=%+v err=%v", res, err)
+	}
+	if _, err := eng.ToJKS(ctx, pair.CertPath, pair.KeyPath, filepath.Join(t.TempDir(), "x.jks"), "", "", "", ""); !errors.Is(err, ErrKeystorePasswordRequired) {
+		t.Errorf("empty password error = %v", err)
+	}
+	if _, err := eng.ToJKS(ctx, pair.CertPath, ca.KeyPath, filepath.Join(t.TempDir(), "x.jks"), "changeit", "", "", ""); err == nil {
+		t.Error("expected mismatch error")
+	}
+}
+
+func TestToTruststore(t *testing.T) {
+	a := testutil.MakeCertPair(t)
+	b := testutil.MakeCertPair(t)
+	ec := testutil.MakeECCertPair(t)
+	bundle := filepath.Join(t.TempDir(), "bundle.pem")
+	var pem []byte
+	for _, p := range []string{a.CertPath, b.CertPath, ec.CertPath} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pem = append(pem, data...)
+	}
+	if err := os.WriteFile(bundle, pem, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+
+	out := filepath.Join(t.TempDir(), "trust.jks")
+	res, err := eng.ToTruststore(ctx, bundle, out, "changeit", nil)
+	if err != nil {
+		t.Fatalf("ToTruststore() error = %v", err)
+	}
+	// Two certs share CN=test.local; the second gets a suffix.
+	if strings.Join(res.Aliases, ",") != "test.local,test.local-2,"+strings.ToLower(mustCN(t, ec.CertPath)) {
+		t.Errorf("Aliases = %v", res.Aliases)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := ParseKeystore(data, "changeit")
+	if err != nil {
+		t.Fatalf("ParseKeystore() error = %v", err)
+	}
+	for _, e := range ks.Entries {
+		if e.Type != KeystoreEntryTrustedCert {
+			t.Errorf("alias %q type = %s", e.Alias, e.Type)
+		}
+	}
+
+	explicit := filepath.Join(t.TempDir(), "explicit.jks")
+	res, err = eng.ToTruststore(ctx, bundle, explicit, "changeit", []string{"A", "b", "c"})
+	if err != nil || strings.Join(res.Aliases, ",") != "a,b,c" {
+		t.Errorf("explicit aliases: res=%+v err=%v", res, err)
+	}
+	if _, err := eng.ToTruststore(ctx, bundle, filepath.Join(t.TempDir(), "x.jks"), "changeit", []string{"a"}); err == nil {
+		t.Error("expected alias count mismatch error")
+	}
+	if _, err := eng.ToTruststore(ctx, bundle, filepath.Join(t.TempDir(), "x.jks"), "changeit", []string{"a", "A", "c"}); err == nil {
+		t.Error("expected duplicate alias error")
+	}
+	if _, err := eng.ToTruststore(ctx, bundle, out, "changeit", nil); !IsOutputExists(err) {
+		t.Errorf("expected OutputExistsError, got %v", err)
+	}
+}
+
+func mustCN(t *testing.T, path string) string {
+	t.Helper()
+	c, err := ParseCertFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Subject.CommonName
+}
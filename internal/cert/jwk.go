@@ -0,0 +1,649 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxJWKSize bounds how much of a .json file is read when probing for a JWK
+// or JWKS. Published key sets are a few KiB.
+const maxJWKSize = 1 << 20
+
+// JWK is a JSON Web Key (RFC 7517) for an RSA, EC or Ed25519 key. Private
+// members are empty for public keys.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	D   string `json:"d,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+	DP  string `json:"dp,omitempty"`
+	DQ  string `json:"dq,omitempty"`
+	QI  string `json:"qi,omitempty"`
+	// X5C is the certificate chain as standard (not URL-safe) Base64 DER,
+	// the certificate for this key first.
+	X5C     []string `json:"x5c,omitempty"`
+	X5TS256 string   `json:"x5t#S256,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKInfo describes one key in a JWK or JWKS file for display.
+type JWKInfo struct {
+	Kid          string   `json:"kid,omitempty"`
+	Kty          string   `json:"kty"`
+	Alg          string   `json:"alg,omitempty"`
+	Use          string   `json:"use,omitempty"`
+	Key          string   `json:"key"` // e.g. "RSA 2048"
+	Private      bool     `json:"private"`
+	Thumbprint   string   `json:"thumbprint"` // RFC 7638, SHA-256
+	Certificates int      `json:"certificates"`
+	Subject      string   `json:"subject,omitempty"`
+	NotAfter     string   `json:"not_after,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// JWKOptions controls BuildJWK and BuildJWKS.
+type JWKOptions struct {
+	// IncludePrivate writes the private key members; without it only the
+	// public key is written, even when the input holds a private key.
+	IncludePrivate bool
+	KeyPassword    string
+	// KeyPath is a private key to pair with a certificate input.
+	KeyPath string
+	Use     string // "sig" or "enc"; omitted when empty
+	Alg     string // e.g. "RS256"; omitted when empty
+	Kid     string // overrides the RFC 7638 thumbprint
+}
+
+// ToJWKResult describes a JWK or JWKS written by ToJWK or ToJWKS.
+type ToJWKResult struct {
+	Output  string   `json:"output,omitempty"`
+	Kids    []string `json:"kids"`
+	Private bool     `json:"private"`
+}
+
+// jwkSource is a key read from an input file, with its certificate chain.
+type jwkSource struct {
+	pub   crypto.PublicKey
+	priv  crypto.Signer
+	certs []*x509.Certificate
+}
+
+// BuildJWK encodes the key in inputPath as a JWK. inputPath may be a PEM or
+// DER certificate (the chain is written as x5c), a combined PEM, a P7B, a
+// private key, or a PEM or OpenSSH public key.
+func BuildJWK(inputPath string, opts JWKOptions) ([]byte, *ToJWKResult, error) {
+	k, err := jwkFromFile(inputPath, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Kid != "" {
+		k.Kid = opts.Kid
+	}
+	data, err := marshalJWKJSON(k)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, &ToJWKResult{Kids: []string{k.Kid}, Private: k.D != ""}, nil
+}
+
+// BuildJWKS encodes the key in each input as a JWK Set, in input order. Each
+// key's kid is its RFC 7638 thumbprint.
+func BuildJWKS(inputs []string, opts JWKOptions) ([]byte, *ToJWKResult, error) {
+	if opts.KeyPath != "" || opts.Kid != "" {
+		return nil, nil, errors.New("a separate key and kid are only supported for a single JWK")
+	}
+	set := JWKSet{Keys: []JWK{}}
+	result := &ToJWKResult{Kids: []string{}}
+	for _, in := range inputs {
+		k, err := jwkFromFile(in, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", in, err)
+		}
+		for _, prev := range set.Keys {
+			if prev.Kid == k.Kid {
+				return nil, nil, fmt.Errorf("%s: duplicate key %s", in, k.Kid)
+			}
+		}
+		set.Keys = append(set.Keys, *k)
+		result.Kids = append(result.Kids, k.Kid)
+		result.Private = result.Private || k.D != ""
+	}
+	data, err := marshalJWKJSON(set)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, result, nil
+}
+
+func marshalJWKJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func jwkFromFile(path string, opts JWKOptions) (*JWK, error) {
+	src, err := readJWKSource(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	var priv crypto.Signer
+	if opts.IncludePrivate {
+		if src.priv == nil {
+			return nil, errors.New("no private key to include; pass the key file or a combined PEM")
+		}
+		priv = src.priv
+	}
+	k, err := NewJWK(src.pub, priv, src.certs)
+	if err != nil {
+		return nil, err
+	}
+	k.Use = opts.Use
+	k.Alg = opts.Alg
+	return k, nil
+}
+
+// readJWKSource reads the key and certificates in path. The private key is
+// only parsed when it will be written, so an encrypted key needs no
+// password for a public JWK.
+func readJWKSource(path string, opts JWKOptions) (*jwkSource, error) {
+	ft, err := DetectType(path)
+	if err != nil {
+		return nil, fmt.Errorf("detect type: %w", err)
+	}
+	src := &jwkSource{}
+	keyPath := opts.KeyPath
+	switch ft {
+	case FileTypeCert, FileTypeCombined, FileTypeDER, FileTypeP7B:
+		certs, err := loadCertificates(path, ft, "")
+		if err != nil {
+			return nil, err
+		}
+		ordered, _ := orderCerts(certs, nil)
+		for _, idx := range ordered {
+			src.certs = append(src.certs, certs[idx])
+		}
+		src.pub = src.certs[0].PublicKey
+		if ft == FileTypeCombined && keyPath == "" {
+			keyPath = path
+		}
+	case FileTypeKey:
+		if keyPath != "" {
+			return nil, errors.New("input is already a private key")
+		}
+		keyPath = path
+		if src.pub, err = privateKeyFilePublic(path, opts.KeyPassword); err != nil {
+			return nil, fmt.Errorf("read private key: %w", err)
+		}
+	case FileTypePublicKey:
+		if src.pub, err = readPublicKeyFile(path); err != nil {
+			return nil, fmt.Errorf("read public key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("expected a certificate or key file, got %s", ft)
+	}
+
+	if keyPath == "" || (!opts.IncludePrivate && keyPath == path) {
+		return src, nil
+	}
+	key, err := ParsePrivateKeyFile(keyPath, opts.KeyPassword)
+	if err != nil {
+		return nil, fmt.Errorf("read private key: %w", err)
+	}
+	if len(src.certs) > 0 {
+		// x5c must start with the certificate for this key.
+		leafIdx := -1
+		for i, c := range src.certs {
+			if publicKeysEqual(c.PublicKey, key.Public()) {
+				leafIdx = i
+				break
+			}
+		}
+		if leafIdx < 0 {
+			return nil, fmt.Errorf("private key does NOT match certificate")
+		}
+		chain := []*x509.Certificate{src.certs[leafIdx]}
+		for i, c := range src.certs {
+			if i != leafIdx {
+				chain = append(chain, c)
+			}
+		}
+		src.certs = chain
+	} else if !publicKeysEqual(src.pub, key.Public()) {
+		return nil, fmt.Errorf("private key does NOT match public key")
+	}
+	src.pub = key.Public()
+	src.priv = key
+	return src, nil
+}
+
+// NewJWK encodes pub as a JWK whose kid is its RFC 7638 thumbprint. The
+// private members are written when priv is set, and certs (the certificate
+// for pub first) become x5c and x5t#S256.
+func NewJWK(pub crypto.PublicKey, priv crypto.Signer, certs []*x509.Certificate) (*JWK, error) {
+	k := &JWK{}
+	switch pk := pub.(type) {
+	case *rsa.PublicKey:
+		k.Kty = "RSA"
+		k.N = b64url(pk.N.Bytes())
+		k.E = b64url(big.NewInt(int64(pk.E)).Bytes())
+		if sk, ok := priv.(*rsa.PrivateKey); ok {
+			if len(sk.Primes) != 2 {
+				return nil, errors.New("multi-prime RSA keys are not supported")
+			}
+			sk.Precompute()
+			k.D = b64url(sk.D.Bytes())
+			k.P = b64url(sk.Primes[0].Bytes())
+			k.Q = b64url(sk.Primes[1].Bytes())
+			k.DP = b64url(sk.Precomputed.Dp.Bytes())
+			k.DQ = b64url(sk.Precomputed.Dq.Bytes())
+			k.QI = b64url(sk.Precomputed.Qinv.Bytes())
+		}
+	case *ecdsa.PublicKey:
+		crv, err := jwkCurveName(pk.Curve)
+		if err != nil {
+			return nil, err
+		}
+		size := (pk.Curve.Params().BitSize + 7) / 8
+		k.Kty = "EC"
+		k.Crv = crv
+		k.X = b64url(pk.X.FillBytes(make([]byte, size)))
+		k.Y = b64url(pk.Y.FillBytes(make([]byte, size)))
+		if sk, ok := priv.(*ecdsa.PrivateKey); ok {
+			k.D = b64url(sk.D.FillBytes(make([]byte, size)))
+		}
+	case ed25519.PublicKey:
+		k.Kty = "OKP"
+		k.Crv = "Ed25519"
+		k.X = b64url(pk)
+		if sk, ok := priv.(ed25519.PrivateKey); ok {
+			k.D = b64url(sk.Seed())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %T for JWK", pub)
+	}
+	if priv != nil && k.D == "" {
+		return nil, fmt.Errorf("private key type %T does not match the public key", priv)
+	}
+	k.Kid = k.Thumbprint()
+	for _, c := range certs {
+		k.X5C = append(k.X5C, base64.StdEncoding.EncodeToString(c.Raw))
+	}
+	if len(certs) > 0 {
+		sum := sha256.Sum256(certs[0].Raw)
+		k.X5TS256 = b64url(sum[:])
+	}
+	return k, nil
+}
+
+func b64url(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func jwkCurveName(c elliptic.Curve) (string, error) {
+	switch c {
+	case elliptic.P256():
+		return "P-256", nil
+	case elliptic.P384():
+		return "P-384", nil
+	case elliptic.P521():
+		return "P-521", nil
+	}
+	return "", fmt.Errorf("unsupported curve %s for JWK", c.Params().Name)
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of the key: the hash of
+// its required public members in lexicographic order.
+func (k JWK) Thumbprint() string {
+	var canonical string
+	switch k.Kty {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, k.Crv, k.X, k.Y)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, k.Crv, k.X)
+	default:
+		return ""
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return b64url(sum[:])
+}
+
+// PublicKey decodes the key's public members.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	dec := func(name, v string) ([]byte, error) {
+		b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(v, "="))
+		if err != nil || len(b) == 0 {
+			return nil, fmt.Errorf("invalid %q member", name)
+		}
+		return b, nil
+	}
+	switch k.Kty {
+	case "RSA":
+		n, err := dec("n", k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := dec("e", k.E)
+		if err != nil {
+			return nil, err
+		}
+		eInt := new(big.Int).SetBytes(e)
+		if !eInt.IsInt64() || eInt.Int64() > 1<<31-1 {
+			return nil, errors.New(`invalid "e" member`)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(eInt.Int64())}, nil
+	case "EC":
+		var curve ecdh.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = ecdh.P256()
+		case "P-384":
+			curve = ecdh.P384()
+		case "P-521":
+			curve = ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := dec("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := dec("y", k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != len(y) {
+			return nil, errors.New("x and y differ in length")
+		}
+		point := append(append([]byte{4}, x...), y...)
+		pk, err := curve.NewPublicKey(point)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC point: %w", err)
+		}
+		// Round-trip through PKIX to get an *ecdsa.PublicKey.
+		der, err := x509.MarshalPKIXPublicKey(pk)
+		if err != nil {
+			return nil, err
+		}
+		return x509.ParsePKIXPublicKey(der)
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := dec("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New(`invalid "x" member`)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// Certificates decodes the x5c chain.
+func (k JWK) Certificates() ([]*x509.Certificate, error) {
+	var out []*x509.Certificate
+	for i, s := range k.X5C {
+		der, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("x5c[%d]: invalid base64", i)
+		}
+		c, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("x5c[%d]: %w", i, err)
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// ParseJWKs reads a single JWK or every key in a JWK Set.
+func ParseJWKs(data []byte) ([]JWK, error) {
+	var probe struct {
+		Kty  *string         `json:"kty"`
+		Keys json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("parse JWK: %w", err)
+	}
+	switch {
+	case probe.Kty != nil:
+		var k JWK
+		if err := json.Unmarshal(data, &k); err != nil {
+			return nil, fmt.Errorf("parse JWK: %w", err)
+		}
+		return []JWK{k}, nil
+	case probe.Keys != nil:
+		var set JWKSet
+		if err := json.Unmarshal(data, &set); err != nil {
+			return nil, fmt.Errorf("parse JWKS: %w", err)
+		}
+		for i, k := range set.Keys {
+			if k.Kty == "" {
+				return nil, fmt.Errorf("parse JWKS: keys[%d] has no kty", i)
+			}
+		}
+		return set.Keys, nil
+	}
+	return nil, errors.New("not a JWK or JWK Set")
+}
+
+// isJWKBytes reports whether data is a JSON JWK or JWK Set.
+func isJWKBytes(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' || !bytes.Contains(trimmed, []byte(`"kty"`)) {
+		return false
+	}
+	keys, err := ParseJWKs(trimmed)
+	return err == nil && len(keys) > 0
+}
+
+func hasJWK(path string) bool {
+	data, err := readHead(path, maxJWKSize)
+	if err != nil {
+		return false
+	}
+	return isJWKBytes(data)
+}
+
+func readJWKs(path string) ([]JWK, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKs(data)
+}
+
+// JWKInfos summarises each key for display, flagging an x5c certificate that
+// does not hold the key and an x5t#S256 that does not match it.
+func JWKInfos(keys []JWK) []JWKInfo {
+	out := make([]JWKInfo, 0, len(keys))
+	for _, k := range keys {
+		info := JWKInfo{
+			Kid:        k.Kid,
+			Kty:        k.Kty,
+			Alg:        k.Alg,
+			Use:        k.Use,
+			Private:    k.D != "",
+			Thumbprint: k.Thumbprint(),
+		}
+		pub, err := k.PublicKey()
+		if err != nil {
+			info.Key = k.Kty
+			info.Warnings = append(info.Warnings, err.Error())
+		} else {
+			info.Key = describePublicKeyValue(pub)
+		}
+		certs, err := k.Certificates()
+		if err != nil {
+			info.Warnings = append(info.Warnings, err.Error())
+		}
+		info.Certificates = len(certs)
+		if len(certs) > 0 {
+			c := certs[0]
+			info.Subject = opensslName(c.Subject)
+			info.NotAfter = c.NotAfter.UTC().Format(time.RFC3339)
+			if pub != nil && !publicKeysEqual(pub, c.PublicKey) {
+				info.Warnings = append(info.Warnings, "x5c[0] does not hold this key")
+			}
+			sum := sha256.Sum256(c.Raw)
+			if k.X5TS256 != "" && strings.TrimRight(k.X5TS256, "=") != b64url(sum[:]) {
+				info.Warnings = append(info.Warnings, "x5t#S256 does not match x5c[0]")
+			}
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// jwkCertificates returns every x5c certificate, in key order. Invalid x5c
+// entries are skipped; JWKInfos reports them.
+func jwkCertificates(keys []JWK) []*x509.Certificate {
+	var out []*x509.Certificate
+	for _, k := range keys {
+		certs, _ := k.Certificates()
+		out = append(out, certs...)
+	}
+	return out
+}
+
+// jwkSummary fills s from the first x5c certificate, if any key has one.
+func jwkSummary(s *CertSummary, keys []JWK, fill func(*CertSummary, *x509.Certificate)) {
+	s.JWKs = JWKInfos(keys)
+	if certs := jwkCertificates(keys); len(certs) > 0 {
+		fill(s, certs[0])
+		return
+	}
+	if len(s.JWKs) > 0 {
+		s.PublicKeyInfo = s.JWKs[0].Key
+	}
+}
+
+func nativeJWKSummary(path string) (*CertSummary, error) {
+	s := &CertSummary{File: path, FileType: FileTypeJWK}
+	keys, err := readJWKs(path)
+	if err != nil {
+		return s, err
+	}
+	jwkSummary(s, keys, fillNativeSummary)
+	return s, nil
+}
+
+// jwkSoonestExpiring returns the x5c certificate that expires first.
+func jwkSoonestExpiring(keys []JWK) (*x509.Certificate, error) {
+	var out *x509.Certificate
+	for _, c := range jwkCertificates(keys) {
+		if out == nil || c.NotAfter.Before(out.NotAfter) {
+			out = c
+		}
+	}
+	if out == nil {
+		return nil, errors.New("JWK has no x5c certificates")
+	}
+	return out, nil
+}
+
+// JWKDetailsText renders each key followed by the full text of its x5c
+// certificates.
+func JWKDetailsText(keys []JWK) string {
+	var b strings.Builder
+	for i, info := range JWKInfos(keys) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "JWK[%d]: %s\n", i, info.Kid)
+		fmt.Fprintf(&b, "Key Type: %s (%s)\n", info.Kty, info.Key)
+		if info.Alg != "" {
+			fmt.Fprintf(&b, "Algorithm: %s\n", info.Alg)
+		}
+		if info.Use != "" {
+			fmt.Fprintf(&b, "Use: %s\n", info.Use)
+		}
+		fmt.Fprintf(&b, "Private: %t\n", info.Private)
+		fmt.Fprintf(&b, "Thumbprint (SHA-256): %s\n", info.Thumbprint)
+		for _, w := range info.Warnings {
+			fmt.Fprintf(&b, "Warning: %s\n", w)
+		}
+		certs, _ := keys[i].Certificates()
+		for j, c := range certs {
+			fmt.Fprintf(&b, "x5c[%d]:\n", j)
+			b.WriteString(RenderCertificateText(c))
+		}
+		b.WriteString("\n*******************************************\n")
+	}
+	return b.String()
+}
+
+// ToJWK writes the JWK for inputPath to outputPath.
+func (e *Engine) ToJWK(_ context.Context, inputPath, outputPath string, opts JWKOptions) (*ToJWKResult, error) {
+	if err := ensureNotExists(outputPath); err != nil {
+		return nil, err
+	}
+	data, result, err := BuildJWK(inputPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	return writeJWKFile(outputPath, data, result)
+}
+
+// ToJWKS writes a JWK Set for inputs to outputPath.
+func (e *Engine) ToJWKS(_ context.Context, inputs []string, outputPath string, opts JWKOptions) (*ToJWKResult, error) {
+	if err := ensureNotExists(outputPath); err != nil {
+		return nil, err
+	}
+	data, result, err := BuildJWKS(inputs, opts)
+	if err != nil {
+		return nil, err
+	}
+	return writeJWKFile(outputPath, data, result)
+}
+
+// writeJWKFile writes private JWKs with 0600 permissions and public ones
+// with 0644.
+func writeJWKFile(outputPath string, data []byte, result *ToJWKResult) (*ToJWKResult, error) {
+	perm := os.FileMode(0o644)
+	if result.Private {
+		perm = 0o600
+	}
+	if err := writeFileExclusive(outputPath, data, perm); err != nil {
+		return nil, err
+	}
+	result.Output = outputPath
+	return result, nil
+}
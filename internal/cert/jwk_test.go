@@ -0,0 +1,172 @@
+package cert
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickromney/certconv/test/testutil"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestJWKThumbprint_RFC7638(t *testing.T) {
+	// The example key from RFC 7638, section 3.1.
+	k := JWK{
+		Kty: "RSA",
+		E:   "AQAB",
+		N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn6" +
+			"4tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91Cb" +
+			"OpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+	}
+	if got := k.Thumbprint(); got != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Fatalf("Thumbprint = %s", got)
+	}
+}
+
+func TestBuildJWK_CertificateChain(t *testing.T) {
+	chain := testutil.MakeChain(t)
+	leaf, _ := os.ReadFile(chain.LeafPath)
+	inter, _ := os.ReadFile(chain.IntermediatePath)
+	// Intermediate first: x5c must still start with the leaf.
+	bundle := filepath.Join(chain.Dir, "bundle.pem")
+	if err := os.WriteFile(bundle, append(inter, leaf...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, res, err := BuildJWK(bundle, JWKOptions{Use: "sig", Alg: "ES256"})
+	if err != nil {
+		t.Fatalf("BuildJWK: %v", err)
+	}
+	if res.Private || len(res.Kids) != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	var k JWK
+	if err := json.Unmarshal(data, &k); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if k.Kty != "EC" || k.Crv != "P-256" || k.D != "" || k.Kid != k.Thumbprint() || k.Use != "sig" || k.Alg != "ES256" {
+		t.Fatalf("unexpected JWK: %+v", k)
+	}
+	certs, err := k.Certificates()
+	if err != nil || len(certs) != 2 || certs[0].Subject.CommonName != "app.test.local" {
+		t.Fatalf("x5c should start with the leaf: %v %v", certs, err)
+	}
+	pub, err := k.PublicKey()
+	if err != nil || !publicKeysEqual(pub, certs[0].PublicKey) {
+		t.Fatalf("PublicKey = %v, %v", pub, err)
+	}
+	if infos := JWKInfos([]JWK{k}); len(infos[0].Warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", infos[0].Warnings)
+	}
+
+	if _, _, err := BuildJWK(bundle, JWKOptions{IncludePrivate: true}); err == nil {
+		t.Fatal("expected an error for --include-private without a key")
+	}
+	data, res, err = BuildJWK(bundle, JWKOptions{IncludePrivate: true, KeyPath: chain.LeafKeyPath})
+	if err != nil || !res.Private || !strings.Contains(string(data), `"d": "`) {
+		t.Fatalf("private BuildJWK = %v:\n%s", err, data)
+	}
+
+	// Written JWKs are recognised and read without shelling out.
+	p := filepath.Join(chain.Dir, "leaf.jwk")
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+	r, err := eng.ToJWK(ctx, bundle, p, JWKOptions{})
+	if err != nil {
+		t.Fatalf("ToJWK: %v", err)
+	}
+	if info, _ := os.Stat(p); r.Private || info.Mode().Perm() != 0o644 {
+		t.Fatalf("unexpected result %+v, mode %v", r, info.Mode())
+	}
+	ft, err := DetectType(p)
+	if err != nil || ft != FileTypeJWK {
+		t.Fatalf("DetectType = %q, %v", ft, err)
+	}
+	s, err := eng.Summary(ctx, p, "")
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if len(s.JWKs) != 1 || s.JWKs[0].Certificates != 2 || !strings.Contains(s.Subject, "app.test.local") {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+	d, err := eng.Details(ctx, p, "")
+	if err != nil || !strings.Contains(d.RawText, "x5c[1]:") {
+		t.Fatalf("Details = %v:\n%s", err, d.RawText)
+	}
+	// The intermediate expires first (60 days), so a 75-day window fails.
+	exp, err := eng.Expiry(ctx, p, 75)
+	if err != nil || exp.Valid || !strings.Contains(exp.Subject, "Intermediate") {
+		t.Fatalf("Expiry = %+v, %v", exp, err)
+	}
+}
+
+func TestBuildJWKS_Keys(t *testing.T) {
+	dir := t.TempDir()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPath := writePKCS8Key(t, edKey)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPub := filepath.Join(dir, "id_rsa.pub")
+	if err := os.WriteFile(rsaPub, ssh.MarshalAuthorizedKey(sshPub), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, res, err := BuildJWKS([]string{edPath, rsaPub}, JWKOptions{})
+	if err != nil {
+		t.Fatalf("BuildJWKS: %v", err)
+	}
+	if res.Private || len(res.Kids) != 2 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if got := DetectTypeFromNameAndBytes("jwks.json", data); got != FileTypeJWK {
+		t.Fatalf("DetectTypeFromNameAndBytes = %q", got)
+	}
+	keys, err := ParseJWKs(data)
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("ParseJWKs = %+v, %v", keys, err)
+	}
+	if keys[0].Kty != "OKP" || keys[0].Crv != "Ed25519" || keys[0].D != "" || keys[1].Kty != "RSA" || keys[1].E != "AQAB" {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+	for i, want := range []any{edKey.Public(), &rsaKey.PublicKey} {
+		pub, err := keys[i].PublicKey()
+		if err != nil || !publicKeysEqual(pub, want) {
+			t.Fatalf("keys[%d].PublicKey = %v, %v", i, pub, err)
+		}
+	}
+
+	// Private output needs a private key in every input.
+	if _, _, err := BuildJWKS([]string{edPath, rsaPub}, JWKOptions{IncludePrivate: true}); err == nil || !strings.Contains(err.Error(), rsaPub) {
+		t.Fatalf("expected an error naming %s, got %v", rsaPub, err)
+	}
+	out := filepath.Join(dir, "private.json")
+	r, err := NewEngine(refusingExec{t: t}).ToJWKS(context.Background(), []string{edPath}, out, JWKOptions{IncludePrivate: true})
+	if err != nil || !r.Private {
+		t.Fatalf("ToJWKS = %+v, %v", r, err)
+	}
+	if info, _ := os.Stat(out); info.Mode().Perm() != 0o600 {
+		t.Fatalf("private JWKS mode = %v", info.Mode())
+	}
+	data, _ = os.ReadFile(out)
+	keys, _ = ParseJWKs(data)
+	if seed, _ := base64.RawURLEncoding.DecodeString(keys[0].D); string(seed) != string(edKey.Seed()) {
+		t.Fatal("d should be the Ed25519 seed")
+	}
+}
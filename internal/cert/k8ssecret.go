@@ -0,0 +1,416 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// K8sSecretTypeTLS is the Secret type kubectl writes for "create secret tls".
+const K8sSecretTypeTLS = "kubernetes.io/tls"
+
+// maxK8sManifestSize bounds how much of a file is read when probing for
+// Secret manifests. The API server caps a single Secret at 1 MiB; this
+// leaves room for multi-document files.
+const maxK8sManifestSize = 4 << 20
+
+// K8sSecret is a Kubernetes Secret carrying a TLS certificate in tls.crt.
+type K8sSecret struct {
+	Name      string
+	Namespace string
+	Type      string
+	// Certificates holds tls.crt, leaf first as kubectl expects.
+	Certificates []*x509.Certificate
+	// CA holds the optional ca.crt key.
+	CA     []*x509.Certificate
+	HasKey bool
+}
+
+// K8sSecretInfo describes one Secret in a manifest for display.
+type K8sSecretInfo struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace,omitempty"`
+	Type         string `json:"type"`
+	Subject      string `json:"subject,omitempty"`
+	NotAfter     string `json:"not_after,omitempty"`
+	Certificates int    `json:"certificates"`
+	HasKey       bool   `json:"has_key"`
+	CACerts      int    `json:"ca_certs,omitempty"`
+}
+
+// ID returns "namespace/name", or just the name when no namespace is set.
+func (s K8sSecret) ID() string {
+	if s.Namespace == "" {
+		return s.Name
+	}
+	return s.Namespace + "/" + s.Name
+}
+
+// ID is K8sSecret.ID for the described Secret.
+func (s K8sSecretInfo) ID() string {
+	return K8sSecret{Name: s.Name, Namespace: s.Namespace}.ID()
+}
+
+// k8sSecretManifest is the part of a Secret (or List) certconv reads.
+type k8sSecretManifest struct {
+	APIVersion string `yaml:"apiVersion" json:"apiVersion"`
+	Kind       string `yaml:"kind" json:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name" json:"name"`
+		Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	} `yaml:"metadata" json:"metadata"`
+	Type       string            `yaml:"type" json:"type"`
+	Data       map[string]string `yaml:"data,omitempty" json:"data,omitempty"`
+	StringData map[string]string `yaml:"stringData,omitempty" json:"stringData,omitempty"`
+}
+
+// value returns a Secret key, preferring the base64 data over stringData as
+// the API server does. ok is false when the key is absent.
+func (m *k8sSecretManifest) value(key string) (value []byte, ok bool, err error) {
+	if v, found := m.Data[key]; found {
+		out, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(v), ""))
+		if err != nil {
+			return nil, true, fmt.Errorf("secret %q: %s is not valid base64", m.Metadata.Name, key)
+		}
+		return out, true, nil
+	}
+	if v, found := m.StringData[key]; found {
+		return []byte(v), true, nil
+	}
+	return nil, false, nil
+}
+
+// decodeK8sTLSManifests returns every Secret holding a tls.crt in a YAML or
+// JSON manifest. Multi-document YAML and kind: List wrappers are walked;
+// other resources are skipped.
+func decodeK8sTLSManifests(data []byte) ([]k8sSecretManifest, error) {
+	var out []k8sSecretManifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse manifest: %w", err)
+		}
+		if out, err = appendK8sTLSManifests(out, &doc); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func appendK8sTLSManifests(out []k8sSecretManifest, n *yaml.Node) ([]k8sSecretManifest, error) {
+	var head struct {
+		Kind  string      `yaml:"kind"`
+		Items []yaml.Node `yaml:"items"`
+	}
+	if err := n.Decode(&head); err != nil {
+		return out, nil // not an object: some other document
+	}
+	if strings.HasSuffix(head.Kind, "List") {
+		for i := range head.Items {
+			var err error
+			if out, err = appendK8sTLSManifests(out, &head.Items[i]); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+	if head.Kind != "Secret" {
+		return out, nil
+	}
+	var m k8sSecretManifest
+	if err := n.Decode(&m); err != nil {
+		return nil, fmt.Errorf("parse secret: %w", err)
+	}
+	if _, ok := m.Data["tls.crt"]; ok {
+		return append(out, m), nil
+	}
+	if _, ok := m.StringData["tls.crt"]; ok {
+		return append(out, m), nil
+	}
+	return out, nil
+}
+
+// isK8sSecretBytes reports whether data is a manifest with at least one
+// Secret carrying tls.crt.
+func isK8sSecretBytes(data []byte) bool {
+	if !bytes.Contains(data, []byte("tls.crt")) || !bytes.Contains(data, []byte("Secret")) {
+		return false
+	}
+	manifests, err := decodeK8sTLSManifests(data)
+	return err == nil && len(manifests) > 0
+}
+
+func hasK8sSecret(path string) bool {
+	data, err := readHead(path, maxK8sManifestSize)
+	if err != nil {
+		return false
+	}
+	return isK8sSecretBytes(data)
+}
+
+// ParseK8sSecrets reads every TLS Secret in a YAML or JSON manifest,
+// including multi-document YAML and kind: List.
+func ParseK8sSecrets(data []byte) ([]K8sSecret, error) {
+	manifests, err := decodeK8sTLSManifests(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no Secret with tls.crt found")
+	}
+	var out []K8sSecret
+	for i := range manifests {
+		m := &manifests[i]
+		s := K8sSecret{Name: m.Metadata.Name, Namespace: m.Metadata.Namespace, Type: m.Type}
+		crt, _, err := m.value("tls.crt")
+		if err != nil {
+			return nil, err
+		}
+		if s.Certificates, _, err = parsePEMCerts(crt); err != nil || len(s.Certificates) == 0 {
+			return nil, fmt.Errorf("secret %q: tls.crt holds no PEM certificate", s.ID())
+		}
+		if ca, ok, err := m.value("ca.crt"); err != nil {
+			return nil, err
+		} else if ok {
+			if s.CA, _, err = parsePEMCerts(ca); err != nil {
+				return nil, fmt.Errorf("secret %q: ca.crt: %w", s.ID(), err)
+			}
+		}
+		key, _, err := m.value("tls.key")
+		if err != nil {
+			return nil, err
+		}
+		s.HasKey = len(bytes.TrimSpace(key)) > 0
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func readK8sSecrets(path string) ([]K8sSecret, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := ParseK8sSecrets(data)
+	if err != nil {
+		return nil, fmt.Errorf("read secret: %w", err)
+	}
+	return secrets, nil
+}
+
+// K8sSecretInfos summarises each Secret for display.
+func K8sSecretInfos(secrets []K8sSecret) []K8sSecretInfo {
+	out := make([]K8sSecretInfo, 0, len(secrets))
+	for _, s := range secrets {
+		leaf := s.Certificates[0]
+		out = append(out, K8sSecretInfo{
+			Name:         s.Name,
+			Namespace:    s.Namespace,
+			Type:         s.Type,
+			Subject:      opensslName(leaf.Subject),
+			NotAfter:     leaf.NotAfter.UTC().Format(time.RFC3339),
+			Certificates: len(s.Certificates),
+			HasKey:       s.HasKey,
+			CACerts:      len(s.CA),
+		})
+	}
+	return out
+}
+
+// k8sSecretCertificates returns the tls.crt certificates of every Secret,
+// in manifest order.
+func k8sSecretCertificates(secrets []K8sSecret) []*x509.Certificate {
+	var out []*x509.Certificate
+	for _, s := range secrets {
+		out = append(out, s.Certificates...)
+	}
+	return out
+}
+
+// k8sSecretSoonestExpiring returns the Secret leaf with the earliest
+// NotAfter, so expiry checks flag the first Secret to need renewal.
+func k8sSecretSoonestExpiring(secrets []K8sSecret) *x509.Certificate {
+	var out *x509.Certificate
+	for _, s := range secrets {
+		if out == nil || s.Certificates[0].NotAfter.Before(out.NotAfter) {
+			out = s.Certificates[0]
+		}
+	}
+	return out
+}
+
+func k8sSecretSummary(s *CertSummary, data []byte, fill func(*CertSummary, *x509.Certificate)) error {
+	secrets, err := ParseK8sSecrets(data)
+	if err != nil {
+		return fmt.Errorf("read secret: %w", err)
+	}
+	s.K8sSecrets = K8sSecretInfos(secrets)
+	fill(s, secrets[0].Certificates[0])
+	return nil
+}
+
+// K8sSecretDetailsText renders each Secret's name followed by the full text
+// of its tls.crt certificates.
+func K8sSecretDetailsText(secrets []K8sSecret) string {
+	var b strings.Builder
+	for i, s := range secrets {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Secret: %s\n", s.ID())
+		fmt.Fprintf(&b, "Type: %s\n", s.Type)
+		if len(s.CA) > 0 {
+			fmt.Fprintf(&b, "ca.crt: %d certificate(s)\n", len(s.CA))
+		}
+		for j, c := range s.Certificates {
+			fmt.Fprintf(&b, "tls.crt[%d]:\n", j)
+			b.WriteString(RenderCertificateText(c))
+		}
+		b.WriteString("\n*******************************************\n")
+	}
+	return b.String()
+}
+
+func lintK8sSecrets(name string, data []byte, cfg LintConfig) (*LintResult, error) {
+	secrets, err := ParseK8sSecrets(data)
+	if err != nil {
+		return nil, err
+	}
+	var issues []LintIssue
+	for _, s := range secrets {
+		for _, issue := range lintCertificates(s.Certificates, cfg) {
+			issue.Message = fmt.Sprintf("secret %q: %s", s.ID(), issue.Message)
+			issues = append(issues, issue)
+		}
+	}
+	return &LintResult{File: name, Issues: issues, Clean: len(issues) == 0}, nil
+}
+
+// K8sSecretOptions controls the manifest BuildK8sSecret writes.
+type K8sSecretOptions struct {
+	Name        string
+	Namespace   string
+	CAPath      string // optional; written as ca.crt
+	KeyPassword string
+	JSON        bool
+}
+
+// ToK8sSecretResult describes a Secret manifest written by ToK8sSecret.
+type ToK8sSecretResult struct {
+	Output    string `json:"output,omitempty"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Subject   string `json:"subject"`
+	Chain     int    `json:"chain"`
+	CACerts   int    `json:"ca_certs,omitempty"`
+	Format    string `json:"format"`
+}
+
+// k8sNameRE is an RFC 1123 subdomain, the rule Secret names must follow.
+var k8sNameRE = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// BuildK8sSecret renders a kubernetes.io/tls Secret manifest for the key and
+// the certificate it belongs to. Other certificates in certPath follow the
+// leaf in tls.crt; the key is written unencrypted as PKCS#8, since
+// Kubernetes cannot use an encrypted one.
+func BuildK8sSecret(certPath, keyPath string, opts K8sSecretOptions) ([]byte, *ToK8sSecretResult, error) {
+	if len(opts.Name) > 253 || !k8sNameRE.MatchString(opts.Name) {
+		return nil, nil, fmt.Errorf("invalid Secret name %q: use lowercase letters, digits, '-' and '.'", opts.Name)
+	}
+	if opts.Namespace != "" && (len(opts.Namespace) > 63 || strings.Contains(opts.Namespace, ".") || !k8sNameRE.MatchString(opts.Namespace)) {
+		return nil, nil, fmt.Errorf("invalid namespace %q: use lowercase letters, digits and '-'", opts.Namespace)
+	}
+	key, leaf, chain, err := loadKeyAndChain(certPath, keyPath, "", opts.KeyPassword)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode private key: %w", err)
+	}
+
+	var m k8sSecretManifest
+	m.APIVersion = "v1"
+	m.Kind = "Secret"
+	m.Metadata.Name = opts.Name
+	m.Metadata.Namespace = opts.Namespace
+	m.Type = K8sSecretTypeTLS
+	m.Data = map[string]string{
+		"tls.crt": base64.StdEncoding.EncodeToString(encodeCertsPEM(append([]*x509.Certificate{leaf}, chain...))),
+		"tls.key": base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+	}
+	result := &ToK8sSecretResult{
+		Name:      opts.Name,
+		Namespace: opts.Namespace,
+		Subject:   opensslName(leaf.Subject),
+		Chain:     len(chain),
+		Format:    "yaml",
+	}
+	if opts.CAPath != "" {
+		ft, err := DetectType(opts.CAPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read CA: %w", err)
+		}
+		if ft == FileTypePFX || ft == FileTypeJKS || !isCertFileType(ft) {
+			return nil, nil, fmt.Errorf("read CA: expected a PEM, DER or P7B certificate file, got %s", ft)
+		}
+		ca, err := loadCertificates(opts.CAPath, ft, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("read CA: %w", err)
+		}
+		m.Data["ca.crt"] = base64.StdEncoding.EncodeToString(encodeCertsPEM(ca))
+		result.CACerts = len(ca)
+	}
+
+	if opts.JSON {
+		result.Format = "json"
+		out, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return nil, nil, err
+		}
+		return append(out, '\n'), result, nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(m); err != nil {
+		return nil, nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), result, nil
+}
+
+// ToK8sSecret writes the manifest BuildK8sSecret renders to outputPath with
+// 0600 permissions, since it carries the private key.
+func (e *Engine) ToK8sSecret(_ context.Context, certPath, keyPath, outputPath string, opts K8sSecretOptions) (*ToK8sSecretResult, error) {
+	if err := ensureNotExists(outputPath); err != nil {
+		return nil, err
+	}
+	data, result, err := BuildK8sSecret(certPath, keyPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileExclusive(outputPath, data, 0o600); err != nil {
+		return nil, err
+	}
+	result.Output = outputPath
+	return result, nil
+}
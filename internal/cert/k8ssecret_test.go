@@ -0,0 +1,159 @@
+package cert
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+func TestBuildK8sSecret_RoundTrip(t *testing.T) {
+	chain := testutil.MakeChain(t)
+	leaf, _ := os.ReadFile(chain.LeafPath)
+	inter, _ := os.ReadFile(chain.IntermediatePath)
+	bundle := filepath.Join(chain.Dir, "bundle.pem")
+	if err := os.WriteFile(bundle, append(leaf, inter...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, res, err := BuildK8sSecret(bundle, chain.LeafKeyPath, K8sSecretOptions{Name: "web-tls", Namespace: "prod", CAPath: chain.RootPath})
+	if err != nil {
+		t.Fatalf("BuildK8sSecret: %v", err)
+	}
+	if res.Chain != 1 || res.CACerts != 1 || res.Format != "yaml" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	for _, want := range []string{"apiVersion: v1\n", "kind: Secret\n", "  name: web-tls\n", "type: kubernetes.io/tls\n", "  tls.key: "} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("expected %q in manifest:\n%s", want, data)
+		}
+	}
+	if got := DetectTypeFromNameAndBytes("web-tls.yaml", data); got != FileTypeK8sSecret {
+		t.Fatalf("DetectTypeFromNameAndBytes = %q", got)
+	}
+
+	secrets, err := ParseK8sSecrets(data)
+	if err != nil {
+		t.Fatalf("ParseK8sSecrets: %v", err)
+	}
+	if len(secrets) != 1 {
+		t.Fatalf("got %d secrets", len(secrets))
+	}
+	s := secrets[0]
+	if s.ID() != "prod/web-tls" || s.Type != K8sSecretTypeTLS || !s.HasKey || len(s.Certificates) != 2 || len(s.CA) != 1 {
+		t.Fatalf("unexpected secret: %+v", s)
+	}
+	if s.Certificates[0].Subject.CommonName != "app.test.local" {
+		t.Fatalf("tls.crt should start with the leaf, got %s", s.Certificates[0].Subject)
+	}
+
+	// --ca accepts any certificate file, not just PEM.
+	p7b, _, err := BuildP7B([]string{chain.RootPath}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caP7B := filepath.Join(chain.Dir, "ca.p7b")
+	if err := os.WriteFile(caP7B, p7b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, res, err := BuildK8sSecret(chain.LeafPath, chain.LeafKeyPath, K8sSecretOptions{Name: "web-tls", CAPath: caP7B}); err != nil || res.CACerts != 1 {
+		t.Fatalf("BuildK8sSecret with a P7B CA = %+v, %v", res, err)
+	}
+
+	jsonData, res, err := BuildK8sSecret(chain.LeafPath, chain.LeafKeyPath, K8sSecretOptions{Name: "web-tls", JSON: true})
+	if err != nil {
+		t.Fatalf("BuildK8sSecret JSON: %v", err)
+	}
+	if res.Format != "json" || !json.Valid(jsonData) {
+		t.Fatalf("expected a JSON manifest, got %s", jsonData)
+	}
+	if got := DetectTypeFromNameAndBytes("secret.json", jsonData); got != FileTypeK8sSecret {
+		t.Fatalf("JSON manifest detected as %q", got)
+	}
+
+	for _, opts := range []K8sSecretOptions{{Name: "Web_TLS"}, {Name: "web-tls", Namespace: "a.b"}} {
+		if _, _, err := BuildK8sSecret(chain.LeafPath, chain.LeafKeyPath, opts); err == nil {
+			t.Fatalf("expected an error for %+v", opts)
+		}
+	}
+}
+
+func TestK8sSecret_MultiDocumentAndList(t *testing.T) {
+	chain := testutil.MakeChain(t)
+	leafPEM, _ := os.ReadFile(chain.LeafPath)
+	interPEM, _ := os.ReadFile(chain.IntermediatePath)
+
+	indent := func(b []byte) string {
+		return "    " + strings.ReplaceAll(strings.TrimSpace(string(b)), "\n", "\n    ")
+	}
+	multi := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  tls.crt: not-a-secret\n" +
+		"---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: leaf-tls\ntype: kubernetes.io/tls\nstringData:\n  tls.crt: |\n" + indent(leafPEM) + "\n" +
+		"---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: inter-tls\n  namespace: infra\ntype: Opaque\nstringData:\n  tls.crt: |\n" + indent(interPEM) + "\n"
+	p := filepath.Join(t.TempDir(), "secrets.yaml")
+	if err := os.WriteFile(p, []byte(multi), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ft, err := DetectType(p)
+	if err != nil || ft != FileTypeK8sSecret {
+		t.Fatalf("DetectType = %q, %v", ft, err)
+	}
+
+	// Secrets never shell out, even on the openssl backend.
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+	s, err := eng.Summary(ctx, p, "")
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if len(s.K8sSecrets) != 2 || s.K8sSecrets[0].ID() != "leaf-tls" || s.K8sSecrets[1].ID() != "infra/inter-tls" || s.K8sSecrets[0].HasKey {
+		t.Fatalf("unexpected secrets: %+v", s.K8sSecrets)
+	}
+	d, err := eng.Details(ctx, p, "")
+	if err != nil {
+		t.Fatalf("Details: %v", err)
+	}
+	if !strings.Contains(d.RawText, "Secret: infra/inter-tls") {
+		t.Fatalf("Details missing second secret:\n%s", d.RawText)
+	}
+
+	// The intermediate expires first (60 days), so a 75-day window fails.
+	exp, err := eng.Expiry(ctx, p, 75)
+	if err != nil {
+		t.Fatalf("Expiry: %v", err)
+	}
+	if exp.Valid || !strings.Contains(exp.Subject, "Intermediate") {
+		t.Fatalf("Expiry should report the intermediate: %+v", exp)
+	}
+
+	lr, err := LintFile(p)
+	if err != nil {
+		t.Fatalf("LintFile: %v", err)
+	}
+	for _, issue := range lr.Issues {
+		if !strings.HasPrefix(issue.Message, `secret "`) {
+			t.Errorf("lint message not secret-prefixed: %q", issue.Message)
+		}
+	}
+
+	item, _, err := BuildK8sSecret(chain.LeafPath, chain.LeafKeyPath, K8sSecretOptions{Name: "web-tls", JSON: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := `{"apiVersion":"v1","kind":"List","items":[` + string(item) + `]}`
+	secrets, err := ParseK8sSecrets([]byte(list))
+	if err != nil || len(secrets) != 1 || !secrets[0].HasKey {
+		t.Fatalf("ParseK8sSecrets(List) = %+v, %v", secrets, err)
+	}
+
+	configMap := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  tls.crt: x\n"
+	if got := DetectTypeFromNameAndBytes("cm.yaml", []byte(configMap)); got == FileTypeK8sSecret {
+		t.Fatalf("ConfigMap detected as a Secret")
+	}
+	if got := DetectTypeFromNameAndBytes("leaf.pem", leafPEM); got != FileTypeCert {
+		t.Fatalf("PEM certificate detected as %q", got)
+	}
+}
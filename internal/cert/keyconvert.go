@@ -0,0 +1,241 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyFormat is a private key encoding that ConvertKey can write.
+type KeyFormat string
+
+const (
+	KeyFormatPKCS8   KeyFormat = "pkcs8"
+	KeyFormatPKCS1   KeyFormat = "pkcs1"
+	KeyFormatSEC1    KeyFormat = "sec1"
+	KeyFormatOpenSSH KeyFormat = "openssh"
+)
+
+// KeyKDF selects the key derivation function for encrypted PKCS#8 output.
+type KeyKDF string
+
+const (
+	KeyKDFPBKDF2 KeyKDF = "pbkdf2"
+	KeyKDFScrypt KeyKDF = "scrypt"
+)
+
+// ErrKeyEncryptedInput is returned when an encrypted key would be converted
+// without saying whether the output should stay encrypted.
+var ErrKeyEncryptedInput = errors.New("input key is encrypted; choose to re-encrypt or decrypt the output")
+
+// KeyConvertOptions controls ConvertKey. Password decrypts the input;
+// NewPassword encrypts the output when Encrypt is set. Decrypt must be set to
+// write an encrypted input in the clear.
+type KeyConvertOptions struct {
+	To          KeyFormat
+	Password    string
+	Encrypt     bool
+	Decrypt     bool
+	NewPassword string
+	KDF         KeyKDF
+}
+
+// KeyConvertResult reports what ConvertKey wrote.
+type KeyConvertResult struct {
+	Output    string    `json:"output"`
+	Format    KeyFormat `json:"format"`
+	Algorithm string    `json:"algorithm"`
+	Encrypted bool      `json:"encrypted"`
+	KDF       KeyKDF    `json:"kdf,omitempty"`
+}
+
+// ParseKeyFormat validates a --to value.
+func ParseKeyFormat(s string) (KeyFormat, error) {
+	switch f := KeyFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case KeyFormatPKCS8, KeyFormatPKCS1, KeyFormatSEC1, KeyFormatOpenSSH:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown key format %q (use pkcs8, pkcs1, sec1 or openssh)", s)
+}
+
+// ConvertKey re-encodes the private key at inputPath in the requested format
+// and writes it to outputPath with 0600 permissions. Only PKCS#8 output can
+// be encrypted (PBES2, AES-256-CBC with PBKDF2-HMAC-SHA256 or scrypt).
+func ConvertKey(inputPath, outputPath string, opts KeyConvertOptions) (*KeyConvertResult, error) {
+	if opts.Encrypt && opts.Decrypt {
+		return nil, errors.New("encrypt and decrypt are mutually exclusive")
+	}
+	if opts.Encrypt {
+		if opts.To != KeyFormatPKCS8 {
+			return nil, fmt.Errorf("encryption is only supported for pkcs8 output, not %s", opts.To)
+		}
+		if opts.NewPassword == "" {
+			return nil, errors.New("a password is required to encrypt the output key")
+		}
+		if opts.KDF == "" {
+			opts.KDF = KeyKDFPBKDF2
+		}
+	}
+
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading key: %w", err)
+	}
+	if privateKeyEncrypted(data) && !opts.Encrypt && !opts.Decrypt {
+		return nil, ErrKeyEncryptedInput
+	}
+	key, err := ParsePrivateKeyBytes(data, opts.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	var block *pem.Block
+	switch opts.To {
+	case KeyFormatPKCS8:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+		if opts.Encrypt {
+			enc, err := encryptPKCS8(der, []byte(opts.NewPassword), opts.KDF)
+			if err != nil {
+				return nil, err
+			}
+			block = &pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: enc}
+		}
+	case KeyFormatPKCS1:
+		k, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("pkcs1 output needs an RSA key, not %s", describePublicKeyValue(key.Public()))
+		}
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}
+	case KeyFormatSEC1:
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("sec1 output needs an EC key, not %s", describePublicKeyValue(key.Public()))
+		}
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		block = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	case KeyFormatOpenSSH:
+		// Keep the comment of an unencrypted OpenSSH input; an encrypted
+		// one's comment is inside the encrypted section.
+		comment := ""
+		if info := readOpenSSHKeyInfo(data); info != nil {
+			comment = info.Comment
+		}
+		block, err = marshalOpenSSHPrivateKey(key, comment)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown key format %q (use pkcs8, pkcs1, sec1 or openssh)", opts.To)
+	}
+
+	if err := writeFileExclusive(outputPath, pem.EncodeToMemory(block), 0o600); err != nil {
+		return nil, err
+	}
+	result := &KeyConvertResult{
+		Output:    outputPath,
+		Format:    opts.To,
+		Algorithm: describePublicKeyValue(key.Public()),
+		Encrypted: opts.Encrypt,
+	}
+	if opts.Encrypt {
+		result.KDF = opts.KDF
+	}
+	return result, nil
+}
+
+// KeyPasswdOptions controls ChangeKeyPassword. Password decrypts the input.
+// Exactly one of NewPassword and NoPassword must be set.
+type KeyPasswdOptions struct {
+	Password    string
+	NewPassword string
+	NoPassword  bool
+	KDF         KeyKDF
+}
+
+// ChangeKeyPassword writes the key at inputPath to outputPath protected by a
+// new password, or unencrypted with NoPassword. Encrypted output is always
+// PKCS#8 (legacy PEM encryption is upgraded); unencrypted output keeps the
+// input's encoding. outputPath must not exist, so the input is never
+// overwritten.
+func ChangeKeyPassword(inputPath, outputPath string, opts KeyPasswdOptions) (*KeyConvertResult, error) {
+	if opts.NoPassword == (opts.NewPassword != "") {
+		return nil, errors.New("set either a new password or no password")
+	}
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading key: %w", err)
+	}
+	if opts.NoPassword {
+		if !privateKeyEncrypted(data) {
+			return nil, errors.New("input key is not encrypted")
+		}
+		return ConvertKey(inputPath, outputPath, KeyConvertOptions{
+			To:       privateKeyFormat(data),
+			Password: opts.Password,
+			Decrypt:  true,
+		})
+	}
+	return ConvertKey(inputPath, outputPath, KeyConvertOptions{
+		To:          KeyFormatPKCS8,
+		Password:    opts.Password,
+		Encrypt:     true,
+		NewPassword: opts.NewPassword,
+		KDF:         opts.KDF,
+	})
+}
+
+// privateKeyFormat returns the encoding of the first private key in PEM
+// data. DER input is treated as PKCS#8.
+func privateKeyFormat(data []byte) KeyFormat {
+	rest := data
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			return KeyFormatPKCS8
+		}
+		rest = r
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			return KeyFormatPKCS1
+		case "EC PRIVATE KEY":
+			return KeyFormatSEC1
+		case "PRIVATE KEY", "ENCRYPTED PRIVATE KEY":
+			return KeyFormatPKCS8
+		case "OPENSSH PRIVATE KEY":
+			return KeyFormatOpenSSH
+		}
+	}
+}
+
+// privateKeyEncrypted reports whether the first private key block in PEM
+// data is password protected.
+func privateKeyEncrypted(data []byte) bool {
+	rest := data
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			return false
+		}
+		rest = r
+		if !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			continue
+		}
+		if block.Type == "OPENSSH PRIVATE KEY" {
+			info, err := ParseOpenSSHKeyInfo(block)
+			return err == nil && info.Encrypted
+		}
+		return block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block) //nolint:staticcheck // legacy PEM encryption is still common in the wild
+	}
+}
@@ -0,0 +1,237 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func writePKCS8Key(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestConvertKey_Formats(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		key       crypto.Signer
+		to        KeyFormat
+		blockType string
+		wantErr   string
+	}{
+		{"rsa pkcs1", rsaKey, KeyFormatPKCS1, "RSA PRIVATE KEY", ""},
+		{"ec sec1", ecKey, KeyFormatSEC1, "EC PRIVATE KEY", ""},
+		{"ed pkcs8", edKey, KeyFormatPKCS8, "PRIVATE KEY", ""},
+		{"rsa openssh", rsaKey, KeyFormatOpenSSH, "OPENSSH PRIVATE KEY", ""},
+		{"ec openssh", ecKey, KeyFormatOpenSSH, "OPENSSH PRIVATE KEY", ""},
+		{"ed openssh", edKey, KeyFormatOpenSSH, "OPENSSH PRIVATE KEY", ""},
+		{"ec pkcs1", ecKey, KeyFormatPKCS1, "", "needs an RSA key"},
+		{"rsa sec1", rsaKey, KeyFormatSEC1, "", "needs an EC key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := writePKCS8Key(t, tt.key)
+			out := filepath.Join(t.TempDir(), "out.pem")
+			r, err := ConvertKey(in, out, KeyConvertOptions{To: tt.to})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConvertKey: %v", err)
+			}
+			if r.Format != tt.to || r.Encrypted {
+				t.Fatalf("unexpected result: %+v", r)
+			}
+			info, err := os.Stat(out)
+			if err != nil || info.Mode().Perm() != 0o600 {
+				t.Fatalf("output mode: %v %v", info, err)
+			}
+			data, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if block, _ := pem.Decode(data); block == nil || block.Type != tt.blockType {
+				t.Fatalf("expected %s block, got:\n%s", tt.blockType, data)
+			}
+
+			var got any
+			if tt.to == KeyFormatOpenSSH {
+				got, err = ssh.ParseRawPrivateKey(data)
+				if k, ok := got.(*ed25519.PrivateKey); ok {
+					got = *k
+				}
+			} else {
+				got, err = ParsePrivateKeyBytes(data, "")
+			}
+			if err != nil {
+				t.Fatalf("parse output: %v", err)
+			}
+			if !publicKeysEqual(tt.key.Public(), got.(crypto.Signer).Public()) {
+				t.Fatal("converted key does not match the input")
+			}
+		})
+	}
+}
+
+func TestConvertKey_Encryption(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := writePKCS8Key(t, key)
+	dir := t.TempDir()
+
+	for _, kdf := range []KeyKDF{KeyKDFPBKDF2, KeyKDFScrypt} {
+		out := filepath.Join(dir, string(kdf)+".pem")
+		r, err := ConvertKey(in, out, KeyConvertOptions{To: KeyFormatPKCS8, Encrypt: true, NewPassword: "s3cret", KDF: kdf})
+		if err != nil {
+			t.Fatalf("%s: %v", kdf, err)
+		}
+		if !r.Encrypted || r.KDF != kdf {
+			t.Fatalf("%s: unexpected result %+v", kdf, r)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParsePrivateKeyBytes(data, ""); !errors.Is(err, ErrKeyPasswordRequired) {
+			t.Fatalf("%s: expected password required, got %v", kdf, err)
+		}
+		if _, err := ParsePrivateKeyBytes(data, "wrong"); !errors.Is(err, ErrKeyIncorrectPassword) {
+			t.Fatalf("%s: expected incorrect password, got %v", kdf, err)
+		}
+		got, err := ParsePrivateKeyBytes(data, "s3cret")
+		if err != nil || !publicKeysEqual(key.Public(), got.Public()) {
+			t.Fatalf("%s: decrypt round trip: %v", kdf, err)
+		}
+	}
+
+	enc := filepath.Join(dir, string(KeyKDFPBKDF2)+".pem")
+	if _, err := ConvertKey(enc, filepath.Join(dir, "plain.pem"), KeyConvertOptions{To: KeyFormatSEC1, Password: "s3cret"}); !errors.Is(err, ErrKeyEncryptedInput) {
+		t.Fatalf("expected ErrKeyEncryptedInput, got %v", err)
+	}
+	if _, err := ConvertKey(enc, filepath.Join(dir, "plain.pem"), KeyConvertOptions{To: KeyFormatSEC1, Password: "s3cret", Decrypt: true}); err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if _, err := ConvertKey(in, filepath.Join(dir, "x.pem"), KeyConvertOptions{To: KeyFormatSEC1, Encrypt: true, NewPassword: "x"}); err == nil {
+		t.Fatal("expected error encrypting non-PKCS#8 output")
+	}
+	if _, err := ConvertKey(in, enc, KeyConvertOptions{To: KeyFormatPKCS8}); !IsOutputExists(err) {
+		t.Fatalf("expected OutputExistsError, got %v", err)
+	}
+}
+
+func TestPBES2DeriveKey_RejectsExpensiveParameters(t *testing.T) {
+	kdf := func(oid asn1.ObjectIdentifier, params any) pkixAlgorithm {
+		t.Helper()
+		der, err := asn1.Marshal(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pkixAlgorithm{Algorithm: oid, Parameters: asn1.RawValue{FullBytes: der}}
+	}
+	salt := []byte("saltsalt")
+	for name, alg := range map[string]pkixAlgorithm{
+		"pbkdf2 iterations": kdf(oidPBKDF2, pbkdf2Params{Salt: salt, Iterations: 1 << 30}),
+		"scrypt N":          kdf(oidScrypt, scryptParams{Salt: salt, CostParameter: 1 << 20, BlockSize: 8, Parallelization: 1}),
+		"scrypt r":          kdf(oidScrypt, scryptParams{Salt: salt, CostParameter: 1 << 14, BlockSize: 1 << 20, Parallelization: 1}),
+		"scrypt p":          kdf(oidScrypt, scryptParams{Salt: salt, CostParameter: 1 << 14, BlockSize: 8, Parallelization: 1 << 20}),
+	} {
+		if _, err := pbes2DeriveKey(alg, []byte("pw"), 32); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	// The parameters we write ourselves stay within the limits.
+	if _, err := pbes2DeriveKey(kdf(oidScrypt, scryptParams{Salt: salt, CostParameter: pkcs8ScryptN, BlockSize: pkcs8ScryptR, Parallelization: pkcs8ScryptP}), []byte("pw"), 32); err != nil {
+		t.Errorf("default scrypt parameters: %v", err)
+	}
+}
+
+func TestChangeKeyPassword(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//nolint:staticcheck // legacy PEM encryption is what the upgrade path handles
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key), []byte("old"), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	in := filepath.Join(dir, "legacy.pem")
+	if err := os.WriteFile(in, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	original, _ := os.ReadFile(in)
+
+	rotated := filepath.Join(dir, "rotated.pem")
+	r, err := ChangeKeyPassword(in, rotated, KeyPasswdOptions{Password: "old", NewPassword: "new"})
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if r.Format != KeyFormatPKCS8 || !r.Encrypted {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+	data, _ := os.ReadFile(rotated)
+	if got, err := ParsePrivateKeyBytes(data, "new"); err != nil || !publicKeysEqual(key.Public(), got.Public()) {
+		t.Fatalf("rotated key: %v", err)
+	}
+
+	plain := filepath.Join(dir, "plain.pem")
+	r, err = ChangeKeyPassword(in, plain, KeyPasswdOptions{Password: "old", NoPassword: true})
+	if err != nil {
+		t.Fatalf("strip: %v", err)
+	}
+	if r.Format != KeyFormatPKCS1 || r.Encrypted {
+		t.Fatalf("stripped key should stay PKCS#1: %+v", r)
+	}
+	if _, err := ChangeKeyPassword(plain, filepath.Join(dir, "x.pem"), KeyPasswdOptions{NoPassword: true}); err == nil {
+		t.Fatal("expected error stripping an unencrypted key")
+	}
+	if _, err := ChangeKeyPassword(in, filepath.Join(dir, "y.pem"), KeyPasswdOptions{Password: "wrong", NewPassword: "new"}); err == nil {
+		t.Fatal("expected error with the wrong password")
+	}
+	if _, err := ChangeKeyPassword(in, in, KeyPasswdOptions{Password: "old", NewPassword: "new"}); !IsOutputExists(err) {
+		t.Fatalf("expected OutputExistsError, got %v", err)
+	}
+	if after, _ := os.ReadFile(in); string(after) != string(original) {
+		t.Fatal("input key was modified")
+	}
+}
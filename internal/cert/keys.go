@@ -0,0 +1,117 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrKeyPasswordRequired indicates an encrypted private key was read without a password.
+var ErrKeyPasswordRequired = errors.New("private key is encrypted; a password is required")
+
+// ErrKeyIncorrectPassword indicates the supplied key password could not decrypt the key.
+var ErrKeyIncorrectPassword = errors.New("incorrect key password")
+
+// ParsePrivateKeyFile reads a PEM or DER private key from disk.
+func ParsePrivateKeyFile(path, password string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading key: %w", err)
+	}
+	return ParsePrivateKeyBytes(data, password)
+}
+
+// ParsePrivateKeyBytes parses the first private key in PEM data (PKCS#1,
+// SEC1, PKCS#8, encrypted PKCS#8, legacy encrypted PEM, or OpenSSH). DER input is
+// accepted when it is not PEM-encoded.
+func ParsePrivateKeyBytes(data []byte, password string) (crypto.Signer, error) {
+	rest := data
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		rest = r
+		if !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			continue
+		}
+		return parsePrivateKeyBlock(block, password)
+	}
+
+	if looksLikePEM(data) {
+		return nil, errors.New("no private key found")
+	}
+	return parsePrivateKeyDER(data)
+}
+
+func looksLikePEM(data []byte) bool {
+	return strings.Contains(string(data), "-----BEGIN ")
+}
+
+func parsePrivateKeyBlock(block *pem.Block, password string) (crypto.Signer, error) {
+	if block.Type == "OPENSSH PRIVATE KEY" {
+		return parseOpenSSHPrivateKeyBlock(block, password)
+	}
+	der := block.Bytes
+
+	switch {
+	case block.Type == "ENCRYPTED PRIVATE KEY":
+		if password == "" {
+			return nil, ErrKeyPasswordRequired
+		}
+		plain, err := decryptPKCS8(der, []byte(password))
+		if err != nil {
+			return nil, err
+		}
+		der = plain
+
+	case x509.IsEncryptedPEMBlock(block): //nolint:staticcheck // legacy PEM encryption is still common in the wild
+		if password == "" {
+			return nil, ErrKeyPasswordRequired
+		}
+		plain, err := x509.DecryptPEMBlock(block, []byte(password)) //nolint:staticcheck // see above
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrKeyIncorrectPassword, err.Error())
+		}
+		der = plain
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(der)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(der)
+	}
+	return parsePrivateKeyDER(der)
+}
+
+func parsePrivateKeyDER(der []byte) (crypto.Signer, error) {
+	if k, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		if s, ok := k.(crypto.Signer); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("unsupported private key type %T", k)
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParseECPrivateKey(der); err == nil {
+		return k, nil
+	}
+	return nil, errors.New("unable to parse private key")
+}
+
+// publicKeysEqual compares two public keys of any supported algorithm.
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	type equaler interface {
+		Equal(crypto.PublicKey) bool
+	}
+	if ea, ok := a.(equaler); ok {
+		return ea.Equal(b)
+	}
+	return false
+}
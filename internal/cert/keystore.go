@@ -0,0 +1,411 @@
+package cert
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EntryInfos summarises each keystore alias for display.
+func (ks *Keystore) EntryInfos() []KeystoreEntryInfo {
+	out := make([]KeystoreEntryInfo, 0, len(ks.Entries))
+	for _, e := range ks.Entries {
+		info := KeystoreEntryInfo{
+			Alias:   e.Alias,
+			Type:    e.Type,
+			Created: e.Created.Format(time.RFC3339),
+		}
+		for _, c := range e.Chain {
+			info.Chain = append(info.Chain, c.Subject.String())
+		}
+		if len(e.Chain) > 0 {
+			info.NotAfter = e.Chain[0].NotAfter.UTC().Format(time.RFC3339)
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// firstCertificate returns the leaf of the first entry that has one.
+func (ks *Keystore) firstCertificate() *x509.Certificate {
+	for _, e := range ks.Entries {
+		if len(e.Chain) > 0 {
+			return e.Chain[0]
+		}
+	}
+	return nil
+}
+
+// soonestExpiring returns the entry certificate (leaf or trusted cert) with
+// the earliest NotAfter, so expiry checks flag the first thing to break.
+func (ks *Keystore) soonestExpiring() *x509.Certificate {
+	var out *x509.Certificate
+	for _, e := range ks.Entries {
+		if len(e.Chain) == 0 {
+			continue
+		}
+		if out == nil || e.Chain[0].NotAfter.Before(out.NotAfter) {
+			out = e.Chain[0]
+		}
+	}
+	return out
+}
+
+// keystoreSoonestExpiring reads path as a keystore and returns its
+// soonest-expiring entry certificate.
+func keystoreSoonestExpiring(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ks, err := ParseKeystore(data, "")
+	if err != nil {
+		return nil, err
+	}
+	c := ks.soonestExpiring()
+	if c == nil {
+		return nil, fmt.Errorf("keystore contains no certificates")
+	}
+	return c, nil
+}
+
+func keystoreSummary(s *CertSummary, data []byte, password string, fill func(*CertSummary, *x509.Certificate)) error {
+	ks, err := ParseKeystore(data, password)
+	if err != nil {
+		return fmt.Errorf("read keystore: %w", err)
+	}
+	s.KeystoreType = ks.Format
+	s.KeystoreEntries = ks.EntryInfos()
+	if c := ks.firstCertificate(); c != nil {
+		fill(s, c)
+	}
+	return nil
+}
+
+// KeystoreDetailsText renders a keystore listing in the spirit of
+// "keytool -list -v", followed by the full text of every certificate.
+func KeystoreDetailsText(ks *Keystore) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Keystore type: %s\n", ks.Format)
+	fmt.Fprintf(&b, "Your keystore contains %d entr%s\n", len(ks.Entries), pluralY(len(ks.Entries)))
+
+	for _, e := range ks.Entries {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "Alias name: %s\n", e.Alias)
+		fmt.Fprintf(&b, "Creation date: %s\n", e.Created.Format("Jan 2, 2006"))
+		switch e.Type {
+		case KeystoreEntryPrivateKey:
+			b.WriteString("Entry type: PrivateKeyEntry\n")
+			fmt.Fprintf(&b, "Certificate chain length: %d\n", len(e.Chain))
+		case KeystoreEntryTrustedCert:
+			b.WriteString("Entry type: trustedCertEntry\n")
+		case KeystoreEntrySecretKey:
+			b.WriteString("Entry type: SecretKeyEntry\n")
+		}
+		for i, c := range e.Chain {
+			if e.Type == KeystoreEntryPrivateKey {
+				fmt.Fprintf(&b, "Certificate[%d]:\n", i+1)
+			}
+			b.WriteString(RenderCertificateText(c))
+		}
+		b.WriteString("\n*******************************************\n")
+	}
+	return b.String()
+}
+
+func pluralY(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
+
+func lintKeystore(name string, data []byte, password string, cfg LintConfig) (*LintResult, error) {
+	ks, err := ParseKeystore(data, password)
+	if err != nil {
+		return nil, err
+	}
+	var issues []LintIssue
+	for _, e := range ks.Entries {
+		if len(e.Chain) == 0 {
+			continue
+		}
+		for _, issue := range lintCertificates(e.Chain, cfg) {
+			issue.Message = fmt.Sprintf("alias %q: %s", e.Alias, issue.Message)
+			issues = append(issues, issue)
+		}
+	}
+	return &LintResult{
+		File:   name,
+		Issues: issues,
+		Clean:  len(issues) == 0,
+	}, nil
+}
+
+// FromJKS extracts PEM files from a JKS/JCEKS keystore. Private-key entries
+// produce a cert, key and (when the chain is longer than one) CA file, named
+// exactly as FromPFX names them. When the keystore holds more than one entry
+// (and alias is empty) each file name is suffixed with the entry's alias.
+// Nothing is written unless every entry can be extracted.
+//
+// keyPassword defaults to password, matching keytool's default of using the
+// store password for keys.
+func (e *Engine) FromJKS(_ context.Context, inputPath, outputDir, password, keyPassword, alias string) (*FromJKSResult, error) {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore: %w", err)
+	}
+	ks, err := ParseKeystore(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore: %w", err)
+	}
+	if keyPassword == "" {
+		keyPassword = password
+	}
+
+	entries := ks.Entries
+	if alias != "" {
+		entry, ok := ks.Entry(alias)
+		if !ok {
+			return nil, fmt.Errorf("alias %q not found in keystore", alias)
+		}
+		entries = []KeystoreEntry{*entry}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("keystore contains no entries")
+	}
+
+	// Decrypt every entry and check every output path before writing
+	// anything, so a failure part-way leaves no files behind.
+	base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	result := &FromJKSResult{}
+	var files []keystoreFile
+	seen := map[string]string{}
+	for _, entry := range entries {
+		stem := base
+		if len(entries) > 1 {
+			stem = base + "-" + sanitizeAliasForFilename(entry.Alias)
+		}
+		out, entryFiles, err := planKeystoreEntry(entry, filepath.Join(outputDir, stem), keyPassword)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range entryFiles {
+			if other, ok := seen[f.path]; ok {
+				return nil, fmt.Errorf("aliases %q and %q both write %s; extract them one at a time with --alias", other, entry.Alias, f.path)
+			}
+			seen[f.path] = entry.Alias
+			if err := ensureNotExists(f.path); err != nil {
+				return nil, err
+			}
+		}
+		files = append(files, entryFiles...)
+		result.Entries = append(result.Entries, out)
+	}
+
+	// This directory may contain private keys. Prefer a restrictive default.
+	if err := os.MkdirAll(outputDir, 0o700); err != nil {
+		return nil, fmt.Errorf("create output directory: %w", err)
+	}
+	for _, f := range files {
+		if err := writeFileExclusive(f.path, f.data, f.perm); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// keystoreFile is one PEM file FromJKS will write.
+type keystoreFile struct {
+	path string
+	data []byte
+	perm os.FileMode
+}
+
+// planKeystoreEntry decrypts entry and returns the files it extracts to,
+// without writing them.
+func planKeystoreEntry(entry KeystoreEntry, stem, keyPassword string) (FromJKSEntry, []keystoreFile, error) {
+	out := FromJKSEntry{Alias: entry.Alias, Type: entry.Type}
+
+	switch entry.Type {
+	case KeystoreEntrySecretKey:
+		// Nothing to extract as PEM.
+		return out, nil, nil
+
+	case KeystoreEntryTrustedCert:
+		out.CertFile = stem + ".crt"
+		return out, []keystoreFile{{out.CertFile, encodeCertsPEM(entry.Chain), 0o644}}, nil
+	}
+
+	key, err := entry.PrivateKey(keyPassword)
+	if err != nil {
+		return out, nil, fmt.Errorf("alias %q: extract private key: %w", entry.Alias, err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return out, nil, fmt.Errorf("alias %q: extract private key: %w", entry.Alias, err)
+	}
+	if len(entry.Chain) == 0 {
+		return out, nil, fmt.Errorf("alias %q has no certificate chain", entry.Alias)
+	}
+
+	out.CertFile = stem + ".crt"
+	out.KeyFile = stem + ".key"
+	files := []keystoreFile{
+		{out.CertFile, encodeCertsPEM(entry.Chain[:1]), 0o644},
+		{out.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600},
+	}
+	if len(entry.Chain) > 1 {
+		out.CAFile = stem + "-ca.crt"
+		files = append(files, keystoreFile{out.CAFile, encodeCertsPEM(entry.Chain[1:]), 0o644})
+	}
+	return out, files, nil
+}
+
+// sanitizeAliasForFilename keeps aliases readable while avoiding path
+// separators and shell-hostile characters.
+func sanitizeAliasForFilename(alias string) string {
+	var b strings.Builder
+	for _, r := range alias {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	s := strings.Trim(b.String(), ".")
+	if s == "" {
+		return "entry"
+	}
+	return s
+}
+
+// ToJKS writes a JKS keystore holding a single private-key entry built from a
+// PEM cert + key (plus an optional CA bundle for the chain). The key is
+// protected with the store password, as keytool does by default. An empty
+// alias defaults to the certificate's subject CN.
+func (e *Engine) ToJKS(_ context.Context, certPath, keyPath, outputPath, password, caPath, keyPassword, alias string) (*ToKeystoreResult, error) {
+	if password == "" {
+		return nil, ErrKeystorePasswordRequired
+	}
+	if err := ValidatePEMCert(certPath); err != nil {
+		return nil, err
+	}
+	if err := ValidatePEMKey(keyPath); err != nil {
+		return nil, err
+	}
+	if err := ensureNotExists(outputPath); err != nil {
+		return nil, err
+	}
+
+	key, leaf, chain, err := loadKeyAndChain(certPath, keyPath, caPath, keyPassword)
+	if err != nil {
+		return nil, err
+	}
+	if alias == "" {
+		alias = aliasFromCertificate(leaf, "mykey")
+	}
+	alias = normaliseKeystoreAlias(alias)
+
+	data, err := encodeJKS([]keystoreWriteEntry{{
+		Alias: alias,
+		Key:   key,
+		Chain: append([]*x509.Certificate{leaf}, chain...),
+	}}, password, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("create keystore: %w", err)
+	}
+	if err := writeFileExclusive(outputPath, data, 0o600); err != nil {
+		return nil, err
+	}
+	return &ToKeystoreResult{Output: outputPath, Aliases: []string{alias}}, nil
+}
+
+// ToTruststore writes a JKS truststore with one trusted-cert entry per
+// certificate in bundlePath (PEM, DER or P7B). aliases, when given, must have
+// one entry per certificate; otherwise each alias defaults to the subject CN,
+// with a numeric suffix added to keep aliases unique.
+func (e *Engine) ToTruststore(_ context.Context, bundlePath, outputPath, password string, aliases []string) (*ToKeystoreResult, error) {
+	if password == "" {
+		return nil, ErrKeystorePasswordRequired
+	}
+	ft, err := DetectType(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("detect type: %w", err)
+	}
+	if ft == FileTypePFX || ft == FileTypeJKS || !isCertFileType(ft) {
+		return nil, fmt.Errorf("expected a PEM, DER or P7B certificate bundle, got %s", ft)
+	}
+	if err := ensureNotExists(outputPath); err != nil {
+		return nil, err
+	}
+
+	certs, err := loadCertificates(bundlePath, ft, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(aliases) > 0 && len(aliases) != len(certs) {
+		return nil, fmt.Errorf("got %d aliases for %d certificates", len(aliases), len(certs))
+	}
+
+	seen := map[string]bool{}
+	entries := make([]keystoreWriteEntry, 0, len(certs))
+	result := &ToKeystoreResult{Output: outputPath}
+	for i, c := range certs {
+		var alias string
+		if len(aliases) > 0 {
+			alias = normaliseKeystoreAlias(aliases[i])
+			if alias == "" {
+				return nil, fmt.Errorf("alias %d is empty", i+1)
+			}
+			if seen[alias] {
+				return nil, fmt.Errorf("duplicate alias %q", alias)
+			}
+		} else {
+			alias = uniqueAlias(normaliseKeystoreAlias(aliasFromCertificate(c, fmt.Sprintf("cert-%d", i+1))), seen)
+		}
+		seen[alias] = true
+		entries = append(entries, keystoreWriteEntry{Alias: alias, Chain: []*x509.Certificate{c}})
+		result.Aliases = append(result.Aliases, alias)
+	}
+
+	data, err := encodeJKS(entries, password, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("create truststore: %w", err)
+	}
+	if err := writeFileExclusive(outputPath, data, 0o644); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func aliasFromCertificate(c *x509.Certificate, fallback string) string {
+	if cn := strings.TrimSpace(c.Subject.CommonName); cn != "" {
+		return cn
+	}
+	return fallback
+}
+
+// normaliseKeystoreAlias lower-cases an alias. The JKS provider lower-cases
+// aliases on lookup, so a mixed-case alias would be unreachable from Java.
+func normaliseKeystoreAlias(alias string) string {
+	return strings.ToLower(strings.TrimSpace(alias))
+}
+
+func uniqueAlias(alias string, seen map[string]bool) string {
+	if !seen[alias] {
+		return alias
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", alias, i)
+		if !seen[candidate] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Kubeconfig entry kinds: a cluster's CA bundle or a user's client
+// certificate.
+const (
+	KubeconfigCluster = "cluster"
+	KubeconfigUser    = "user"
+)
+
+// KubeconfigEntry is one certificate-bearing cluster or user in a kubeconfig.
+type KubeconfigEntry struct {
+	Kind string // KubeconfigCluster or KubeconfigUser
+	Name string
+	// Source is "embedded" for *-data fields, else the referenced file.
+	Source       string
+	Certificates []*x509.Certificate
+	// HasKey reports a client key alongside a user's certificate.
+	HasKey bool
+}
+
+// KubeconfigEntryInfo describes one kubeconfig entry for display.
+type KubeconfigEntryInfo struct {
+	Kind         string `json:"kind"`
+	Name         string `json:"name"`
+	Source       string `json:"source"`
+	Subject      string `json:"subject"`
+	Issuer       string `json:"issuer"`
+	NotAfter     string `json:"not_after"`
+	Certificates int    `json:"certificates"`
+	HasKey       bool   `json:"has_key,omitempty"`
+}
+
+// ID returns "cluster/NAME" or "user/NAME".
+func (e KubeconfigEntry) ID() string {
+	return e.Kind + "/" + e.Name
+}
+
+// ID is KubeconfigEntry.ID for the described entry.
+func (e KubeconfigEntryInfo) ID() string {
+	return KubeconfigEntry{Kind: e.Kind, Name: e.Name}.ID()
+}
+
+// kubeconfigFile is the part of a kubeconfig certconv reads.
+type kubeconfigFile struct {
+	Kind     string `yaml:"kind"`
+	Clusters []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// decodeKubeconfig parses data as a kubeconfig holding at least one cluster
+// CA or client certificate. Token- or exec-only kubeconfigs are rejected,
+// since they carry nothing to inspect.
+func decodeKubeconfig(data []byte) (*kubeconfigFile, error) {
+	var kc kubeconfigFile
+	if err := yaml.Unmarshal(data, &kc); err != nil {
+		return nil, fmt.Errorf("parse kubeconfig: %w", err)
+	}
+	if kc.Kind != "" && kc.Kind != "Config" {
+		return nil, fmt.Errorf("not a kubeconfig (kind %q)", kc.Kind)
+	}
+	for _, c := range kc.Clusters {
+		if c.Cluster.CertificateAuthorityData != "" || c.Cluster.CertificateAuthority != "" {
+			return &kc, nil
+		}
+	}
+	for _, u := range kc.Users {
+		if u.User.ClientCertificateData != "" || u.User.ClientCertificate != "" {
+			return &kc, nil
+		}
+	}
+	return nil, fmt.Errorf("kubeconfig holds no certificates")
+}
+
+// isKubeconfigBytes reports whether data is a kubeconfig with at least one
+// certificate.
+func isKubeconfigBytes(data []byte) bool {
+	if !bytes.Contains(data, []byte("clusters")) ||
+		!(bytes.Contains(data, []byte("certificate-authority")) || bytes.Contains(data, []byte("client-certificate"))) {
+		return false
+	}
+	_, err := decodeKubeconfig(data)
+	return err == nil
+}
+
+func hasKubeconfig(path string) bool {
+	data, err := readHead(path, maxK8sManifestSize)
+	if err != nil {
+		return false
+	}
+	return isKubeconfigBytes(data)
+}
+
+// ParseKubeconfig reads every cluster CA and user client certificate in a
+// kubeconfig, clusters first. Certificates given as file paths are read
+// relative to baseDir, as kubectl resolves them relative to the kubeconfig.
+func ParseKubeconfig(data []byte, baseDir string) ([]KubeconfigEntry, error) {
+	kc, err := decodeKubeconfig(data)
+	if err != nil {
+		return nil, err
+	}
+	var out []KubeconfigEntry
+	add := func(kind, name, embedded, ref string) (*KubeconfigEntry, error) {
+		if embedded == "" && ref == "" {
+			return nil, nil
+		}
+		e := KubeconfigEntry{Kind: kind, Name: name, Source: "embedded"}
+		var pemData []byte
+		if embedded != "" {
+			if pemData, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(embedded), "")); err != nil {
+				return nil, fmt.Errorf("%s %q: invalid base64 certificate data", kind, name)
+			}
+		} else {
+			e.Source = ref
+			if !filepath.IsAbs(ref) {
+				e.Source = filepath.Join(baseDir, ref)
+			}
+			if pemData, err = os.ReadFile(e.Source); err != nil {
+				return nil, fmt.Errorf("%s %q: %w", kind, name, err)
+			}
+		}
+		if e.Certificates, _, err = parsePEMCerts(pemData); err != nil || len(e.Certificates) == 0 {
+			return nil, fmt.Errorf("%s %q: no PEM certificate found", kind, name)
+		}
+		out = append(out, e)
+		return &out[len(out)-1], nil
+	}
+	for _, c := range kc.Clusters {
+		if _, err := add(KubeconfigCluster, c.Name, c.Cluster.CertificateAuthorityData, c.Cluster.CertificateAuthority); err != nil {
+			return nil, err
+		}
+	}
+	for _, u := range kc.Users {
+		e, err := add(KubeconfigUser, u.Name, u.User.ClientCertificateData, u.User.ClientCertificate)
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			e.HasKey = u.User.ClientKeyData != "" || u.User.ClientKey != ""
+		}
+	}
+	return out, nil
+}
+
+func readKubeconfig(path string) ([]KubeconfigEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ParseKubeconfig(data, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("read kubeconfig: %w", err)
+	}
+	return entries, nil
+}
+
+// KubeconfigEntryInfos summarises each kubeconfig entry for display.
+func KubeconfigEntryInfos(entries []KubeconfigEntry) []KubeconfigEntryInfo {
+	out := make([]KubeconfigEntryInfo, 0, len(entries))
+	for _, e := range entries {
+		c := e.Certificates[0]
+		out = append(out, KubeconfigEntryInfo{
+			Kind:         e.Kind,
+			Name:         e.Name,
+			Source:       e.Source,
+			Subject:      opensslName(c.Subject),
+			Issuer:       opensslName(c.Issuer),
+			NotAfter:     c.NotAfter.UTC().Format(time.RFC3339),
+			Certificates: len(e.Certificates),
+			HasKey:       e.HasKey,
+		})
+	}
+	return out
+}
+
+// kubeconfigCertificates returns every certificate in the kubeconfig, in
+// file order.
+func kubeconfigCertificates(entries []KubeconfigEntry) []*x509.Certificate {
+	var out []*x509.Certificate
+	for _, e := range entries {
+		out = append(out, e.Certificates...)
+	}
+	return out
+}
+
+// kubeconfigSoonestExpiring returns the certificate with the earliest
+// NotAfter across every cluster CA and client certificate: the first one to
+// lapse breaks cluster access.
+func kubeconfigSoonestExpiring(entries []KubeconfigEntry) *x509.Certificate {
+	var out *x509.Certificate
+	for _, c := range kubeconfigCertificates(entries) {
+		if out == nil || c.NotAfter.Before(out.NotAfter) {
+			out = c
+		}
+	}
+	return out
+}
+
+// kubeconfigSummary fills s from the first client certificate, since that is
+// the one most likely to expire, or the first cluster CA when there is none.
+func kubeconfigSummary(s *CertSummary, entries []KubeconfigEntry, fill func(*CertSummary, *x509.Certificate)) {
+	s.KubeconfigEntries = KubeconfigEntryInfos(entries)
+	first := entries[0]
+	for _, e := range entries {
+		if e.Kind == KubeconfigUser {
+			first = e
+			break
+		}
+	}
+	fill(s, first.Certificates[0])
+}
+
+// KubeconfigDetailsText renders each entry followed by the full text of its
+// certificates.
+func KubeconfigDetailsText(entries []KubeconfigEntry) string {
+	var b strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Kubeconfig %s: %s\n", e.Kind, e.Name)
+		fmt.Fprintf(&b, "Source: %s\n", e.Source)
+		for j, c := range e.Certificates {
+			fmt.Fprintf(&b, "Certificate[%d]:\n", j)
+			b.WriteString(RenderCertificateText(c))
+		}
+		b.WriteString("\n*******************************************\n")
+	}
+	return b.String()
+}
+
+func lintKubeconfig(name string, data []byte, cfg LintConfig) (*LintResult, error) {
+	entries, err := ParseKubeconfig(data, filepath.Dir(name))
+	if err != nil {
+		return nil, err
+	}
+	var issues []LintIssue
+	for _, e := range entries {
+		lint := lintCertificates
+		if e.Kind == KubeconfigCluster {
+			lint = lintCACertificates
+		}
+		for _, issue := range lint(e.Certificates, cfg) {
+			issue.Message = fmt.Sprintf("%s %q: %s", e.Kind, e.Name, issue.Message)
+			issues = append(issues, issue)
+		}
+	}
+	return &LintResult{File: name, Issues: issues, Clean: len(issues) == 0}, nil
+}
@@ -0,0 +1,118 @@
+package cert
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+func TestKubeconfig(t *testing.T) {
+	chain := testutil.MakeChain(t)
+	b64 := func(path string) string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	kubeconfig := `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example:6443
+    certificate-authority-data: ` + b64(chain.RootPath) + `
+- name: token-only
+  cluster:
+    server: https://other.example:6443
+users:
+- name: alice
+  user:
+    client-certificate-data: ` + b64(chain.LeafPath) + `
+    client-key-data: ` + b64(chain.LeafKeyPath) + `
+- name: ops
+  user:
+    client-certificate: intermediate.pem
+- name: bot
+  user:
+    token: abc
+`
+	p := filepath.Join(chain.Dir, "config")
+	if err := os.WriteFile(p, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ft, err := DetectType(p)
+	if err != nil || ft != FileTypeKubeconfig {
+		t.Fatalf("DetectType = %q, %v", ft, err)
+	}
+
+	// Kubeconfigs never shell out, even on the openssl backend.
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+	s, err := eng.Summary(ctx, p, "")
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if len(s.KubeconfigEntries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", s.KubeconfigEntries)
+	}
+	var ids []string
+	for _, e := range s.KubeconfigEntries {
+		ids = append(ids, e.ID())
+	}
+	if got := strings.Join(ids, ","); got != "cluster/dev,user/alice,user/ops" {
+		t.Fatalf("entries = %s", got)
+	}
+	if !s.KubeconfigEntries[1].HasKey || s.KubeconfigEntries[2].Source != filepath.Join(chain.Dir, "intermediate.pem") {
+		t.Fatalf("unexpected user entries: %+v", s.KubeconfigEntries[1:])
+	}
+	if !strings.Contains(s.Subject, "app.test.local") {
+		t.Fatalf("summary should describe the first client certificate, got %q", s.Subject)
+	}
+
+	d, err := eng.Details(ctx, p, "")
+	if err != nil {
+		t.Fatalf("Details: %v", err)
+	}
+	if !strings.Contains(d.RawText, "Kubeconfig cluster: dev") || !strings.Contains(d.RawText, "Kubeconfig user: ops") {
+		t.Fatalf("Details missing entries:\n%s", d.RawText)
+	}
+
+	// The intermediate expires first (60 days), so a 75-day window fails.
+	exp, err := eng.Expiry(ctx, p, 75)
+	if err != nil {
+		t.Fatalf("Expiry: %v", err)
+	}
+	if exp.Valid || !strings.Contains(exp.Subject, "Intermediate") {
+		t.Fatalf("Expiry should report the intermediate: %+v", exp)
+	}
+
+	data, _ := os.ReadFile(p)
+	if msg, err := CheckExpiry(p, data, ""); err != nil || !strings.Contains(msg, "Intermediate") {
+		t.Fatalf("CheckExpiry = %q, %v", msg, err)
+	}
+	lr, err := LintFile(p)
+	if err != nil {
+		t.Fatalf("LintFile: %v", err)
+	}
+	for _, issue := range lr.Issues {
+		if !strings.HasPrefix(issue.Message, `cluster "`) && !strings.HasPrefix(issue.Message, `user "`) {
+			t.Errorf("lint message not entry-prefixed: %q", issue.Message)
+		}
+		// The cluster CA is not a leaf, so leaf-only rules skip it.
+		if strings.HasPrefix(issue.Message, `cluster "`) && (issue.Code == "missing-sans" || issue.Position != "ca") {
+			t.Errorf("cluster CA linted as a leaf: %+v", issue)
+		}
+	}
+
+	tokenOnly := "apiVersion: v1\nkind: Config\nclusters:\n- name: x\n  cluster:\n    server: https://x\nusers:\n- name: y\n  user:\n    token: abc\n"
+	if got := DetectTypeFromNameAndBytes("config", []byte(tokenOnly)); got == FileTypeKubeconfig {
+		t.Fatalf("token-only kubeconfig detected as %q", got)
+	}
+}
@@ -3,6 +3,11 @@ package cert
 import (
 	"crypto/rsa"
 	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -14,120 +19,331 @@ const (
 	LintWarning LintSeverity = "warning"
 )
 
-// LintIssue describes a single lint finding.
+// ParseLintSeverity accepts "error" or "warning" (case-insensitive).
+func ParseLintSeverity(s string) (LintSeverity, error) {
+	switch sev := LintSeverity(strings.ToLower(strings.TrimSpace(s))); sev {
+	case LintError, LintWarning:
+		return sev, nil
+	}
+	return "", fmt.Errorf("unknown severity %q (want error or warning)", s)
+}
+
+// LintIssue describes a single lint finding. Citation names the requirement
+// the rule enforces, when it comes from a published profile. Position
+// locates the finding in a file holding several certificates: "leaf",
+// "intermediate N", "root", "unlinked", "certificate N" (in a CA bundle) or
+// "bundle" for checks on the bundle as a whole.
 type LintIssue struct {
 	Severity LintSeverity `json:"severity"`
 	Code     string       `json:"code"`
 	Message  string       `json:"message"`
+	Citation string       `json:"citation,omitempty"`
+	Position string       `json:"position,omitempty"`
 }
 
-// LintResult holds all lint findings for a certificate file.
+// LintResult holds all lint findings for a certificate file. Error is set,
+// and Issues empty, when LintPaths could not lint the file.
 type LintResult struct {
 	File   string      `json:"file"`
 	Issues []LintIssue `json:"issues"`
 	Clean  bool        `json:"clean"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// lintCheck is a single lint rule applied to a parsed certificate. It returns
+// the finding's message, or "" when the certificate passes.
+type lintCheck func(*x509.Certificate) string
+
+// Lint profiles. The default profile holds the generic hygiene checks;
+// cabf-br checks a publicly trusted TLS server certificate against the
+// CA/Browser Forum Baseline Requirements.
+const (
+	LintProfileDefault = "default"
+	LintProfileCABFBR  = "cabf-br"
+)
+
+// LintProfiles lists the profiles accepted by LintConfig.Profile.
+var LintProfiles = []string{LintProfileDefault, LintProfileCABFBR}
+
+// LintRule is a lint check with a stable ID. Issues it raises carry the ID as
+// their Code and the rule's Citation.
+type LintRule struct {
+	ID              string       `json:"id"`
+	Description     string       `json:"description"`
+	DefaultSeverity LintSeverity `json:"default_severity"`
+	Citation        string       `json:"citation,omitempty"`
+	// Profiles are the profiles the rule runs in unless enabled or disabled
+	// explicitly.
+	Profiles []string `json:"profiles"`
+	// CSR is set for rules that also run against certificate requests.
+	CSR bool `json:"csr"`
+
+	check      lintCheck
+	csrCheck   func(*x509.CertificateRequest) string
+	chainCheck func(lintChain) string
+	// leafOnly rules are skipped for the other certificates in a bundle.
+	leafOnly bool
+}
+
+// lintProfilesBoth tags the generic checks that matter just as much under
+// the Baseline Requirements.
+var (
+	lintProfilesDefault = []string{LintProfileDefault}
+	lintProfilesBoth    = []string{LintProfileDefault, LintProfileCABFBR}
+)
+
+// lintRules is the ordered rule table. IDs are part of the CLI and config
+// surface; do not rename them.
+var lintRules = append([]LintRule{
+	{ID: "weak-key", Description: "RSA key shorter than 2048 bits", DefaultSeverity: LintError, Profiles: lintProfilesDefault, CSR: true, check: checkWeakKey},
+	{ID: "sha1-signature", Description: "SHA-1 signature algorithm", DefaultSeverity: LintWarning, Profiles: lintProfilesDefault, CSR: true, check: checkSHA1Signature},
+	{ID: "missing-sans", Description: "No Subject Alternative Names", DefaultSeverity: LintWarning, Profiles: lintProfilesDefault, CSR: true, check: checkMissingSANs, leafOnly: true},
+	{ID: "expired", Description: "Certificate has expired", DefaultSeverity: LintError, Profiles: lintProfilesBoth, check: checkExpired},
+	{ID: "not-yet-valid", Description: "Certificate is not yet valid", DefaultSeverity: LintError, Profiles: lintProfilesBoth, check: checkNotYetValid},
+	{ID: "ca-as-leaf", Description: "CA=true with ServerAuth but no CertSign", DefaultSeverity: LintWarning, Profiles: lintProfilesBoth, check: checkCAAsLeaf, leafOnly: true},
+	{ID: "long-validity", Description: "Leaf certificate validity > 398 days", DefaultSeverity: LintWarning, Profiles: lintProfilesDefault, check: checkLongValidity},
+	{ID: "bad-csr-signature", Description: "CSR self-signature does not verify", DefaultSeverity: LintError, Profiles: lintProfilesBoth, CSR: true, csrCheck: checkCSRSignature},
+}, slices.Concat(chainLintRules, cabfBRRules)...)
+
+// CSROnly reports whether r checks only certificate requests.
+func (r LintRule) CSROnly() bool { return r.CSR && r.check == nil }
+
+// BundleOnly reports whether r checks only files holding several
+// certificates.
+func (r LintRule) BundleOnly() bool { return r.chainCheck != nil }
+
+// LintRules returns the lint rules in the order they run.
+func LintRules() []LintRule {
+	return append([]LintRule(nil), lintRules...)
 }
 
-// lintCheck is a single lint rule applied to a parsed certificate.
-type lintCheck func(*x509.Certificate) []LintIssue
+func lintRuleByID(id string) (LintRule, bool) {
+	for _, r := range lintRules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return LintRule{}, false
+}
+
+// LintConfig selects and tunes lint rules. The zero value runs the default
+// profile's rules at their default severities.
+type LintConfig struct {
+	// Profile selects the rule set; "" means LintProfileDefault.
+	Profile string
+	// Enabled turns rules on (true) or off (false) by ID, whatever the
+	// profile; other rules run when they belong to the profile.
+	Enabled map[string]bool
+	// Severity overrides a rule's default severity by ID.
+	Severity map[string]LintSeverity
+}
+
+// Validate rejects unknown profiles, rule IDs and severities.
+func (c LintConfig) Validate() error {
+	if c.Profile != "" && !slices.Contains(LintProfiles, c.Profile) {
+		return fmt.Errorf("unknown lint profile %q (want %s)", c.Profile, strings.Join(LintProfiles, " or "))
+	}
+	var unknown []string
+	for id := range c.Enabled {
+		if _, ok := lintRuleByID(id); !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	for id, sev := range c.Severity {
+		if _, ok := lintRuleByID(id); !ok {
+			unknown = append(unknown, id)
+		}
+		if _, err := ParseLintSeverity(string(sev)); err != nil {
+			return fmt.Errorf("rule %s: %w", id, err)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown lint rule(s): %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
 
-// allLintChecks returns the ordered list of lint checks.
-func allLintChecks() []lintCheck {
-	return []lintCheck{
-		checkWeakKey,
-		checkSHA1Signature,
-		checkMissingSANs,
-		checkExpired,
-		checkNotYetValid,
-		checkCAAsLeaf,
-		checkLongValidity,
+// Merge returns c with over's settings layered on top, as CLI flags layer on
+// the config file.
+func (c LintConfig) Merge(over LintConfig) LintConfig {
+	out := LintConfig{Profile: c.Profile, Enabled: map[string]bool{}, Severity: map[string]LintSeverity{}}
+	if over.Profile != "" {
+		out.Profile = over.Profile
+	}
+	for _, layer := range []LintConfig{c, over} {
+		for id, on := range layer.Enabled {
+			out.Enabled[id] = on
+		}
+		for id, sev := range layer.Severity {
+			out.Severity[id] = sev
+		}
 	}
+	return out
+}
+
+// RuleEnabled reports whether rule r runs under c.
+func (c LintConfig) RuleEnabled(r LintRule) bool {
+	if on, ok := c.Enabled[r.ID]; ok {
+		return on
+	}
+	return slices.Contains(r.Profiles, c.profile())
+}
+
+func (c LintConfig) profile() string {
+	if c.Profile == "" {
+		return LintProfileDefault
+	}
+	return c.Profile
+}
+
+// RuleSeverity returns the severity issues from rule r are reported at.
+func (c LintConfig) RuleSeverity(r LintRule) LintSeverity {
+	if sev, ok := c.Severity[r.ID]; ok {
+		return sev
+	}
+	return r.DefaultSeverity
+}
+
+func (c LintConfig) issue(r LintRule, msg string) LintIssue {
+	return LintIssue{Severity: c.RuleSeverity(r), Code: r.ID, Message: msg, Citation: r.Citation}
 }
 
 // LintCertificate runs all lint checks against a parsed certificate.
 func LintCertificate(c *x509.Certificate) []LintIssue {
+	return LintCertificateWithConfig(c, LintConfig{})
+}
+
+// LintCertificateWithConfig runs the rules enabled in cfg against a parsed
+// certificate.
+func LintCertificateWithConfig(c *x509.Certificate, cfg LintConfig) []LintIssue {
 	var issues []LintIssue
-	for _, check := range allLintChecks() {
-		issues = append(issues, check(c)...)
+	for _, r := range lintRules {
+		if r.check == nil || !cfg.RuleEnabled(r) {
+			continue
+		}
+		if msg := r.check(c); msg != "" {
+			issues = append(issues, cfg.issue(r, msg))
+		}
 	}
 	return issues
 }
 
 // LintFile parses a certificate file and runs all lint checks.
 func LintFile(path string) (*LintResult, error) {
-	c, err := ParseCertFile(path)
+	return LintFileWithConfig(path, LintConfig{})
+}
+
+// LintFileWithConfig parses a certificate, bundle, CSR or keystore file and
+// runs the rules enabled in cfg. PFX files are read with an empty password.
+func LintFileWithConfig(path string, cfg LintConfig) (*LintResult, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	issues := LintCertificate(c)
-	return &LintResult{
-		File:   path,
-		Issues: issues,
-		Clean:  len(issues) == 0,
-	}, nil
+	return LintBytesWithConfig(path, data, "", cfg)
 }
 
-func checkWeakKey(c *x509.Certificate) []LintIssue {
+// lintableTypes are the file types LintPaths lints when it finds them in a
+// directory.
+var lintableTypes = map[FileType]bool{
+	FileTypeCert:       true,
+	FileTypeCombined:   true,
+	FileTypeDER:        true,
+	FileTypeCSR:        true,
+	FileTypeJKS:        true,
+	FileTypeP7B:        true,
+	FileTypeK8sSecret:  true,
+	FileTypeKubeconfig: true,
+}
+
+// LintPaths lints every file named in paths, and the lintable files found in
+// any directories (descending into subdirectories when recursive). Files
+// named explicitly are always linted; a file that cannot be linted yields a
+// result with Error set rather than stopping the run.
+func LintPaths(paths []string, recursive bool, cfg LintConfig) []LintResult {
+	var results []LintResult
+	failed := func(p string, err error) {
+		results = append(results, LintResult{File: p, Issues: []LintIssue{}, Error: err.Error()})
+	}
+	add := func(p string) {
+		r, err := LintFileWithConfig(p, cfg)
+		if err != nil {
+			failed(p, err)
+			return
+		}
+		if r.Issues == nil {
+			r.Issues = []LintIssue{}
+		}
+		results = append(results, *r)
+	}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			failed(p, err)
+			continue
+		}
+		if !info.IsDir() {
+			add(p)
+			continue
+		}
+		err = walkScanFiles(p, ScanOptions{Recursive: recursive}, func(fp, _ string, err error) {
+			if err != nil {
+				failed(fp, err)
+				return
+			}
+			if ft, err := DetectType(fp); err == nil && lintableTypes[ft] {
+				add(fp)
+			}
+		})
+		if err != nil {
+			failed(p, err)
+		}
+	}
+	return results
+}
+
+func checkWeakKey(c *x509.Certificate) string {
 	if pub, ok := c.PublicKey.(*rsa.PublicKey); ok {
 		if pub.N.BitLen() < 2048 {
-			return []LintIssue{{
-				Severity: LintError,
-				Code:     "weak-key",
-				Message:  "RSA key is less than 2048 bits",
-			}}
+			return "RSA key is less than 2048 bits"
 		}
 	}
-	return nil
+	return ""
 }
 
-func checkSHA1Signature(c *x509.Certificate) []LintIssue {
+func checkSHA1Signature(c *x509.Certificate) string {
 	switch c.SignatureAlgorithm {
 	case x509.SHA1WithRSA, x509.ECDSAWithSHA1:
-		return []LintIssue{{
-			Severity: LintWarning,
-			Code:     "sha1-signature",
-			Message:  "Certificate uses SHA-1 signature algorithm",
-		}}
+		return "Certificate uses SHA-1 signature algorithm"
 	}
-	return nil
+	return ""
 }
 
-func checkMissingSANs(c *x509.Certificate) []LintIssue {
+func checkMissingSANs(c *x509.Certificate) string {
 	if len(c.DNSNames) == 0 && len(c.IPAddresses) == 0 && len(c.EmailAddresses) == 0 && len(c.URIs) == 0 {
-		return []LintIssue{{
-			Severity: LintWarning,
-			Code:     "missing-sans",
-			Message:  "No Subject Alternative Names; relies on Common Name",
-		}}
+		return "No Subject Alternative Names; relies on Common Name"
 	}
-	return nil
+	return ""
 }
 
-func checkExpired(c *x509.Certificate) []LintIssue {
+func checkExpired(c *x509.Certificate) string {
 	if time.Now().After(c.NotAfter) {
-		return []LintIssue{{
-			Severity: LintError,
-			Code:     "expired",
-			Message:  "Certificate has expired (NotAfter: " + c.NotAfter.UTC().Format(time.RFC3339) + ")",
-		}}
+		return "Certificate has expired (NotAfter: " + c.NotAfter.UTC().Format(time.RFC3339) + ")"
 	}
-	return nil
+	return ""
 }
 
-func checkNotYetValid(c *x509.Certificate) []LintIssue {
+func checkNotYetValid(c *x509.Certificate) string {
 	if time.Now().Before(c.NotBefore) {
-		return []LintIssue{{
-			Severity: LintError,
-			Code:     "not-yet-valid",
-			Message:  "Certificate is not yet valid (NotBefore: " + c.NotBefore.UTC().Format(time.RFC3339) + ")",
-		}}
+		return "Certificate is not yet valid (NotBefore: " + c.NotBefore.UTC().Format(time.RFC3339) + ")"
 	}
-	return nil
+	return ""
 }
 
-func checkCAAsLeaf(c *x509.Certificate) []LintIssue {
+func checkCAAsLeaf(c *x509.Certificate) string {
 	if !c.IsCA {
-		return nil
+		return ""
 	}
 	hasServerAuth := false
 	for _, eku := range c.ExtKeyUsage {
@@ -137,29 +353,21 @@ func checkCAAsLeaf(c *x509.Certificate) []LintIssue {
 		}
 	}
 	if !hasServerAuth {
-		return nil
+		return ""
 	}
 	if c.KeyUsage&x509.KeyUsageCertSign != 0 {
-		return nil
+		return ""
 	}
-	return []LintIssue{{
-		Severity: LintWarning,
-		Code:     "ca-as-leaf",
-		Message:  "CA=true with ServerAuth EKU but missing CertSign key usage",
-	}}
+	return "CA=true with ServerAuth EKU but missing CertSign key usage"
 }
 
-func checkLongValidity(c *x509.Certificate) []LintIssue {
+func checkLongValidity(c *x509.Certificate) string {
 	if c.IsCA {
-		return nil
+		return ""
 	}
 	duration := c.NotAfter.Sub(c.NotBefore)
 	if duration > 398*24*time.Hour {
-		return []LintIssue{{
-			Severity: LintWarning,
-			Code:     "long-validity",
-			Message:  "Leaf certificate validity exceeds 398 days",
-		}}
+		return "Leaf certificate validity exceeds 398 days"
 	}
-	return nil
+	return ""
 }
@@ -0,0 +1,369 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+var lintProfilesCABFBR = []string{LintProfileCABFBR}
+
+// cabfBRRules check a TLS server (subscriber) certificate against the
+// CA/Browser Forum Baseline Requirements, version 2.1. Citations are BR
+// section numbers. Subscriber rules pass CA certificates, which the BRs
+// profile separately.
+var cabfBRRules = []LintRule{
+	{ID: "br-serial-length", Description: "Serial number must be positive and under 2^159", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 7.1", check: checkBRSerialLength},
+	{ID: "br-serial-entropy", Description: "Serial number shorter than 64 bits, too short to hold 64 bits of CSPRNG output", DefaultSeverity: LintWarning, Profiles: lintProfilesCABFBR, Citation: "CABF BR 7.1", check: checkBRSerialEntropy},
+	{ID: "br-key-size", Description: "Key must be RSA >= 2048 bits (multiple of 8, odd exponent) or ECDSA P-256/384/521", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 6.1.5, 6.1.6", CSR: true, check: checkBRKeySize},
+	{ID: "br-signature-algorithm", Description: "Signature algorithm must be RSA or ECDSA with SHA-256/384/512", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 7.1.3.2", check: checkBRSignatureAlgorithm},
+	{ID: "br-max-validity", Description: "Validity exceeds the BR maximum for the issuance date (398/200/100/47 days)", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 6.3.2", check: checkBRMaxValidity},
+	{ID: "br-eku-server-auth", Description: "EKU must include serverAuth and no other purpose but clientAuth", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 7.1.2.7.10", check: checkBREKU},
+	{ID: "br-ku-eku-consistency", Description: "Key usage must suit a TLS server key and carry no CA bits", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 7.1.2.7.11", check: checkBRKeyUsage},
+	{ID: "br-forbidden-subject-field", Description: "Subject has OU, metadata-only values, or address fields without O", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 7.1.2.7.2, 7.1.4.3", CSR: true, check: checkBRSubjectFields},
+	{ID: "br-san-missing", Description: "No DNS or IP Subject Alternative Names", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 7.1.2.7.12", CSR: true, check: checkBRSANPresent},
+	{ID: "br-san-cn-mismatch", Description: "Common Name is not one of the SAN entries", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 7.1.4.3", CSR: true, check: checkBRCNInSAN},
+	{ID: "br-wildcard-placement", Description: "Wildcard is not the whole leftmost label of a registrable name", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 1.6.1, 7.1.2.7.12", CSR: true, check: checkBRWildcards},
+	{ID: "br-internal-name", Description: "SAN holds an internal name or reserved IP address", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 4.2.2, 7.1.2.7.12", CSR: true, check: checkBRInternalNames},
+	{ID: "br-missing-aia", Description: "No Authority Information Access caIssuers URL", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 7.1.2.7.7", check: checkBRAIA},
+	{ID: "br-missing-crldp", Description: "Neither a CRL Distribution Point nor an OCSP URL", DefaultSeverity: LintError, Profiles: lintProfilesCABFBR, Citation: "CABF BR 7.1.2.11.2", check: checkBRCRLDP},
+}
+
+func checkBRSerialLength(c *x509.Certificate) string {
+	if c.SerialNumber == nil || c.SerialNumber.Sign() <= 0 {
+		return "Serial number must be greater than zero"
+	}
+	if c.SerialNumber.BitLen() > 159 {
+		return fmt.Sprintf("Serial number is %d bits; it must be less than 2^159 (20 octets)", c.SerialNumber.BitLen())
+	}
+	return ""
+}
+
+func checkBRSerialEntropy(c *x509.Certificate) string {
+	if c.SerialNumber == nil || c.SerialNumber.Sign() <= 0 {
+		return ""
+	}
+	if n := c.SerialNumber.BitLen(); n < 64 {
+		return fmt.Sprintf("Serial number is only %d bits; the BRs require at least 64 bits of CSPRNG output", n)
+	}
+	return ""
+}
+
+func checkBRKeySize(c *x509.Certificate) string {
+	switch pub := c.PublicKey.(type) {
+	case *rsa.PublicKey:
+		bits := pub.N.BitLen()
+		switch {
+		case bits < 2048:
+			return fmt.Sprintf("RSA modulus is %d bits; at least 2048 required", bits)
+		case bits%8 != 0:
+			return fmt.Sprintf("RSA modulus is %d bits; it must be a multiple of 8", bits)
+		case pub.E < 3 || pub.E%2 == 0:
+			return fmt.Sprintf("RSA public exponent %d must be an odd number of at least 3", pub.E)
+		}
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			return "ECDSA key is not on P-256, P-384 or P-521"
+		}
+	case ed25519.PublicKey:
+		return "Ed25519 keys are not permitted"
+	default:
+		return fmt.Sprintf("%s keys are not permitted", c.PublicKeyAlgorithm)
+	}
+	return ""
+}
+
+func checkBRSignatureAlgorithm(c *x509.Certificate) string {
+	switch c.SignatureAlgorithm {
+	case x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA,
+		x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS,
+		x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512:
+		return ""
+	}
+	return fmt.Sprintf("Signature algorithm %s is not permitted", c.SignatureAlgorithm)
+}
+
+// brMaxValidity is the subscriber certificate validity limit by issuance
+// date, newest first (ballot SC-081).
+var brMaxValidity = []struct {
+	from time.Time
+	days int
+}{
+	{time.Date(2029, 3, 15, 0, 0, 0, 0, time.UTC), 47},
+	{time.Date(2027, 3, 15, 0, 0, 0, 0, time.UTC), 100},
+	{time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), 200},
+	{time.Time{}, 398},
+}
+
+func checkBRMaxValidity(c *x509.Certificate) string {
+	if c.IsCA {
+		return ""
+	}
+	// The BRs count validity inclusively, and any part of a day as a day.
+	validity := c.NotAfter.Sub(c.NotBefore) + time.Second
+	for _, limit := range brMaxValidity {
+		if c.NotBefore.Before(limit.from) {
+			continue
+		}
+		if validity > time.Duration(limit.days)*24*time.Hour {
+			days := int((validity + 24*time.Hour - 1) / (24 * time.Hour))
+			return fmt.Sprintf("Validity is %d days; certificates issued on or after %s may be valid for at most %d days",
+				days, issuedFrom(limit.from), limit.days)
+		}
+		return ""
+	}
+	return ""
+}
+
+func issuedFrom(t time.Time) string {
+	if t.IsZero() {
+		return "2020-09-01"
+	}
+	return t.Format("2006-01-02")
+}
+
+func checkBREKU(c *x509.Certificate) string {
+	if c.IsCA {
+		return ""
+	}
+	if len(c.ExtKeyUsage) == 0 && len(c.UnknownExtKeyUsage) == 0 {
+		return "Extended Key Usage extension is missing; serverAuth is required"
+	}
+	serverAuth := false
+	var other []x509.ExtKeyUsage
+	for _, eku := range c.ExtKeyUsage {
+		switch eku {
+		case x509.ExtKeyUsageServerAuth:
+			serverAuth = true
+		case x509.ExtKeyUsageClientAuth:
+		default:
+			other = append(other, eku)
+		}
+	}
+	forbidden := describeExtKeyUsage(other)
+	for _, oid := range c.UnknownExtKeyUsage {
+		forbidden = append(forbidden, oid.String())
+	}
+	switch {
+	case !serverAuth:
+		return "Extended Key Usage does not include serverAuth"
+	case len(forbidden) > 0:
+		return "Extended Key Usage includes purposes other than serverAuth/clientAuth: " + strings.Join(forbidden, ", ")
+	}
+	return ""
+}
+
+func checkBRKeyUsage(c *x509.Certificate) string {
+	if c.IsCA || !hasExtension(c, oidExtKeyUsage) {
+		return ""
+	}
+	ku := c.KeyUsage
+	if ku&(x509.KeyUsageCertSign|x509.KeyUsageCRLSign) != 0 {
+		return "Key usage includes keyCertSign or cRLSign on a subscriber certificate"
+	}
+	var allowed x509.KeyUsage
+	switch c.PublicKey.(type) {
+	case *rsa.PublicKey:
+		allowed = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	case *ecdsa.PublicKey:
+		allowed = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyAgreement
+	default:
+		return ""
+	}
+	if ku&^allowed != 0 {
+		return fmt.Sprintf("Key usage %s is not permitted for a %s TLS server key",
+			strings.Join(describeKeyUsage(ku&^allowed), ", "), publicKeyAlgorithmName(c.PublicKey))
+	}
+	if ku&(x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment) == 0 {
+		return "Key usage allows neither digitalSignature nor keyEncipherment, so the key cannot be used for TLS"
+	}
+	return ""
+}
+
+func hasExtension(c *x509.Certificate, oid asn1.ObjectIdentifier) bool {
+	for _, ext := range c.Extensions {
+		if ext.Id.Equal(oid) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	oidAttrCommonName    = asn1.ObjectIdentifier{2, 5, 4, 3}
+	oidAttrOU            = asn1.ObjectIdentifier{2, 5, 4, 11}
+	oidAttrOrganization  = asn1.ObjectIdentifier{2, 5, 4, 10}
+	oidAttrLocality      = asn1.ObjectIdentifier{2, 5, 4, 7}
+	oidAttrProvince      = asn1.ObjectIdentifier{2, 5, 4, 8}
+	oidAttrStreetAddress = asn1.ObjectIdentifier{2, 5, 4, 9}
+	oidAttrPostalCode    = asn1.ObjectIdentifier{2, 5, 4, 17}
+)
+
+func checkBRSubjectFields(c *x509.Certificate) string {
+	if c.IsCA {
+		return ""
+	}
+	var problems []string
+	hasOrg, hasAddress := false, false
+	for _, atv := range c.Subject.Names {
+		value := fmt.Sprint(atv.Value)
+		switch {
+		case atv.Type.Equal(oidAttrOU):
+			problems = append(problems, "organizationalUnitName (OU) is not permitted")
+		case atv.Type.Equal(oidAttrOrganization):
+			hasOrg = true
+		case atv.Type.Equal(oidAttrLocality), atv.Type.Equal(oidAttrProvince),
+			atv.Type.Equal(oidAttrStreetAddress), atv.Type.Equal(oidAttrPostalCode):
+			hasAddress = true
+		}
+		if strings.Trim(value, ".- ") == "" {
+			problems = append(problems, fmt.Sprintf("%s holds only metadata (%q)", atv.Type, value))
+		}
+	}
+	if hasAddress && !hasOrg {
+		problems = append(problems, "address fields are present without organizationName")
+	}
+	return strings.Join(problems, "; ")
+}
+
+func checkBRSANPresent(c *x509.Certificate) string {
+	if c.IsCA {
+		return ""
+	}
+	if len(c.DNSNames) == 0 && len(c.IPAddresses) == 0 {
+		return "Subject Alternative Name must contain at least one dNSName or iPAddress"
+	}
+	return ""
+}
+
+func checkBRCNInSAN(c *x509.Certificate) string {
+	if c.IsCA {
+		return ""
+	}
+	var cns []string
+	for _, atv := range c.Subject.Names {
+		if atv.Type.Equal(oidAttrCommonName) {
+			cns = append(cns, fmt.Sprint(atv.Value))
+		}
+	}
+	switch len(cns) {
+	case 0:
+		return ""
+	case 1:
+	default:
+		return fmt.Sprintf("Subject has %d commonName attributes; at most one is permitted", len(cns))
+	}
+	for _, name := range c.DNSNames {
+		if name == cns[0] {
+			return ""
+		}
+	}
+	for _, ip := range c.IPAddresses {
+		if ip.String() == cns[0] {
+			return ""
+		}
+	}
+	return fmt.Sprintf("Common Name %q does not match any SAN entry", cns[0])
+}
+
+func checkBRWildcards(c *x509.Certificate) string {
+	if c.IsCA {
+		return ""
+	}
+	var bad []string
+	for _, name := range c.DNSNames {
+		if !strings.Contains(name, "*") {
+			continue
+		}
+		rest, ok := strings.CutPrefix(name, "*.")
+		if !ok || strings.Contains(rest, "*") || !strings.Contains(strings.Trim(rest, "."), ".") {
+			bad = append(bad, name)
+		}
+	}
+	if len(bad) > 0 {
+		return "Wildcard must be the entire leftmost label above a registrable domain: " + strings.Join(bad, ", ")
+	}
+	return ""
+}
+
+// brInternalSuffixes are names that can never be validated as publicly
+// registered domains.
+var brInternalSuffixes = []string{
+	"local", "localhost", "localdomain", "internal", "intranet", "private",
+	"lan", "corp", "home", "home.arpa", "test", "example", "invalid",
+}
+
+// brReservedNets are IANA special-purpose ranges that net.IP's own
+// predicates do not cover.
+var brReservedNets = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{"0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "192.0.2.0/24", "198.18.0.0/15", "198.51.100.0/24", "203.0.113.0/24", "240.0.0.0/4", "2001:db8::/32"} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+func checkBRInternalNames(c *x509.Certificate) string {
+	if c.IsCA {
+		return ""
+	}
+	var bad []string
+	for _, name := range c.DNSNames {
+		n := strings.ToLower(strings.TrimSuffix(name, "."))
+		if !strings.Contains(strings.TrimPrefix(n, "*."), ".") {
+			bad = append(bad, name)
+			continue
+		}
+		for _, suffix := range brInternalSuffixes {
+			if n == suffix || strings.HasSuffix(n, "."+suffix) {
+				bad = append(bad, name)
+				break
+			}
+		}
+	}
+	for _, ip := range c.IPAddresses {
+		if isReservedIP(ip) {
+			bad = append(bad, ip.String())
+		}
+	}
+	if len(bad) > 0 {
+		return "Internal names and reserved IP addresses are not permitted: " + strings.Join(bad, ", ")
+	}
+	return ""
+}
+
+func isReservedIP(ip net.IP) bool {
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return true
+	}
+	for _, n := range brReservedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkBRAIA(c *x509.Certificate) string {
+	if c.IsCA || len(c.IssuingCertificateURL) > 0 {
+		return ""
+	}
+	return "Authority Information Access must include a caIssuers URL"
+}
+
+func checkBRCRLDP(c *x509.Certificate) string {
+	if c.IsCA || len(c.CRLDistributionPoints) > 0 || len(c.OCSPServer) > 0 {
+		return ""
+	}
+	return "Certificate must carry a CRL Distribution Point (or an OCSP responder URL)"
+}
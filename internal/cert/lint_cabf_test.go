@@ -0,0 +1,172 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var cabfBR = LintConfig{Profile: LintProfileCABFBR}
+
+// brCert returns a subscriber certificate that passes the cabf-br profile,
+// after mutate has had its say.
+func brCert(t *testing.T, mutate func(*x509.Certificate)) *x509.Certificate {
+	t.Helper()
+	return makeCert(t, func(tmpl *x509.Certificate, key any) any {
+		tmpl.SerialNumber = new(big.Int).SetBytes([]byte{0x51, 0x2e, 0x9a, 0x07, 0xcc, 0x10, 0x3f, 0x8b, 0x44, 0x6d, 0xe1, 0x02, 0x97, 0x5a, 0x3c, 0x11})
+		tmpl.Subject = pkix.Name{CommonName: "www.example.com"}
+		tmpl.DNSNames = []string{"www.example.com", "example.com", "*.api.example.com"}
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+		tmpl.IssuingCertificateURL = []string{"http://ca.example.com/issuer.crt"}
+		tmpl.CRLDistributionPoints = []string{"http://ca.example.com/issuer.crl"}
+		if mutate != nil {
+			mutate(tmpl)
+		}
+		return key
+	})
+}
+
+func issueCodes(issues []LintIssue) []string {
+	var codes []string
+	for _, i := range issues {
+		codes = append(codes, i.Code)
+	}
+	return codes
+}
+
+func TestLintCABF_CompliantCert(t *testing.T) {
+	c := brCert(t, nil)
+	if issues := LintCertificateWithConfig(c, cabfBR); len(issues) != 0 {
+		t.Errorf("expected clean under cabf-br, got %+v", issues)
+	}
+	// The default profile does not run the BR rules.
+	for _, code := range issueCodes(LintCertificate(brCert(t, func(c *x509.Certificate) { c.SerialNumber = big.NewInt(1) }))) {
+		if strings.HasPrefix(code, "br-") {
+			t.Errorf("default profile raised %s", code)
+		}
+	}
+}
+
+func TestLintCABF_Findings(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*x509.Certificate)
+		want   string
+	}{
+		{"short serial", func(c *x509.Certificate) { c.SerialNumber = big.NewInt(1) }, "br-serial-entropy"},
+		{"eight-byte serial under 64 bits", func(c *x509.Certificate) { c.SerialNumber = new(big.Int).Lsh(big.NewInt(1), 62) }, "br-serial-entropy"},
+		{"oversized serial", func(c *x509.Certificate) { c.SerialNumber = new(big.Int).Lsh(big.NewInt(1), 159) }, "br-serial-length"},
+		{"398 days under the 200-day limit", func(c *x509.Certificate) {
+			c.NotBefore = time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+			c.NotAfter = c.NotBefore.Add(398 * 24 * time.Hour)
+		}, "br-max-validity"},
+		{"no EKU", func(c *x509.Certificate) { c.ExtKeyUsage = nil }, "br-eku-server-auth"},
+		{"code signing EKU", func(c *x509.Certificate) {
+			c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageCodeSigning}
+		}, "br-eku-server-auth"},
+		{"certSign on leaf", func(c *x509.Certificate) { c.KeyUsage |= x509.KeyUsageCertSign }, "br-ku-eku-consistency"},
+		{"OU", func(c *x509.Certificate) { c.Subject.OrganizationalUnit = []string{"IT"} }, "br-forbidden-subject-field"},
+		{"locality without O", func(c *x509.Certificate) { c.Subject.Locality = []string{"London"} }, "br-forbidden-subject-field"},
+		{"metadata-only value", func(c *x509.Certificate) { c.Subject.Organization = []string{"-"} }, "br-forbidden-subject-field"},
+		{"no SANs", func(c *x509.Certificate) { c.DNSNames = nil; c.Subject.CommonName = "" }, "br-san-missing"},
+		{"CN not in SAN", func(c *x509.Certificate) { c.Subject.CommonName = "mail.example.com" }, "br-san-cn-mismatch"},
+		{"partial wildcard", func(c *x509.Certificate) { c.DNSNames = append(c.DNSNames, "w*.example.com") }, "br-wildcard-placement"},
+		{"wildcard on TLD", func(c *x509.Certificate) { c.DNSNames = append(c.DNSNames, "*.com") }, "br-wildcard-placement"},
+		{"internal name", func(c *x509.Certificate) { c.DNSNames = append(c.DNSNames, "db.corp") }, "br-internal-name"},
+		{"private IP", func(c *x509.Certificate) { c.IPAddresses = []net.IP{net.ParseIP("10.1.2.3")} }, "br-internal-name"},
+		{"no AIA", func(c *x509.Certificate) { c.IssuingCertificateURL = nil }, "br-missing-aia"},
+		{"no CRLDP or OCSP", func(c *x509.Certificate) { c.CRLDistributionPoints = nil }, "br-missing-crldp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := LintCertificateWithConfig(brCert(t, tt.mutate), cabfBR)
+			i := slices.IndexFunc(issues, func(i LintIssue) bool { return i.Code == tt.want })
+			if i < 0 {
+				t.Fatalf("want %s, got %v", tt.want, issueCodes(issues))
+			}
+			if !strings.HasPrefix(issues[i].Citation, "CABF BR ") {
+				t.Errorf("%s citation = %q", tt.want, issues[i].Citation)
+			}
+		})
+	}
+}
+
+func TestLintCABF_SubscriberRulesPassCAs(t *testing.T) {
+	ca := brCert(t, func(c *x509.Certificate) {
+		c.IsCA = true
+		c.BasicConstraintsValid = true
+		c.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		c.DNSNames = []string{"ca.corp", "*.com"}
+		c.IPAddresses = []net.IP{net.ParseIP("10.1.2.3")}
+	})
+	for _, code := range issueCodes(LintCertificateWithConfig(ca, cabfBR)) {
+		if code == "br-wildcard-placement" || code == "br-internal-name" {
+			t.Errorf("CA certificate raised %s", code)
+		}
+	}
+}
+
+func TestLintCABF_MaxValidityByIssuanceDate(t *testing.T) {
+	tests := []struct {
+		notBefore time.Time
+		days      int
+		ok        bool
+	}{
+		{time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), 398, true},
+		{time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), 200, true},
+		{time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), 201, false},
+		{time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC), 100, true},
+		{time.Date(2029, 3, 15, 0, 0, 0, 0, time.UTC), 48, false},
+	}
+	for _, tt := range tests {
+		c := &x509.Certificate{NotBefore: tt.notBefore, NotAfter: tt.notBefore.Add(time.Duration(tt.days)*24*time.Hour - time.Second)}
+		if got := checkBRMaxValidity(c) == ""; got != tt.ok {
+			t.Errorf("issued %s for %d days: ok = %v, want %v", tt.notBefore.Format(time.DateOnly), tt.days, got, tt.ok)
+		}
+	}
+}
+
+func TestLintCABF_ECDSAKeyUsage(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := makeCert(t, func(tmpl *x509.Certificate, _ any) any {
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+		return key
+	})
+	if msg := checkBRKeyUsage(c); !strings.Contains(msg, "Key Encipherment") {
+		t.Errorf("checkBRKeyUsage = %q, want keyEncipherment rejected for ECDSA", msg)
+	}
+}
+
+func TestLintCABF_CSR(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "app.internal", OrganizationalUnit: []string{"Ops"}},
+		DNSNames: []string{"app.internal"},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := x509.ParseCertificateRequest(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := issueCodes(LintCSRWithConfig(csr, cabfBR))
+	want := []string{"br-forbidden-subject-field", "br-internal-name"}
+	if !slices.Equal(got, want) {
+		t.Errorf("CSR issues = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,212 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// lintChain is a bundle's certificates with duplicates removed, in file
+// order, alongside the leaf→root order orderCerts found for them.
+type lintChain struct {
+	certs      []*x509.Certificate
+	duplicates int
+	ordered    []int
+	// linked is how many entries of ordered form an unbroken issuer chain
+	// from the leaf; the rest are certificates orderCerts could not attach.
+	linked int
+	// caBundle is set when every certificate is a CA, as in a trust bundle.
+	// Such files have no leaf, so the chain checks do not apply.
+	caBundle bool
+}
+
+// chainLintRules check a bundle (PEM, PFX, P7B or keystore entry) as a whole.
+// They run only when the file holds more than one certificate.
+var chainLintRules = []LintRule{
+	{ID: "chain-order", Description: "Bundle is not in leaf→intermediate→root order", DefaultSeverity: LintWarning, Profiles: lintProfilesBoth, chainCheck: checkChainOrder},
+	{ID: "chain-duplicate", Description: "Bundle holds the same certificate more than once", DefaultSeverity: LintWarning, Profiles: lintProfilesBoth, chainCheck: checkChainDuplicate},
+	{ID: "chain-includes-root", Description: "Bundle includes the self-signed root", DefaultSeverity: LintWarning, Profiles: lintProfilesBoth, chainCheck: checkChainIncludesRoot},
+	{ID: "chain-missing-intermediate", Description: "Bundle certificates do not link up to the leaf", DefaultSeverity: LintError, Profiles: lintProfilesBoth, chainCheck: checkChainMissingIntermediate},
+	{ID: "chain-issuer-mismatch", Description: "A certificate's issuer name or signature does not match the next certificate", DefaultSeverity: LintError, Profiles: lintProfilesBoth, chainCheck: checkChainIssuerMismatch},
+}
+
+func newLintChain(certs []*x509.Certificate) lintChain {
+	ch := lintChain{caBundle: true}
+	for _, c := range certs {
+		if slices.ContainsFunc(ch.certs, func(o *x509.Certificate) bool { return o.Equal(c) }) {
+			ch.duplicates++
+			continue
+		}
+		ch.certs = append(ch.certs, c)
+		if !c.IsCA {
+			ch.caBundle = false
+		}
+	}
+	ch.ordered, _ = orderCerts(ch.certs, nil)
+	ch.linked = 1
+	for ch.linked < len(ch.ordered) && issuedBy(ch.certs[ch.ordered[ch.linked-1]], ch.certs[ch.ordered[ch.linked]]) {
+		ch.linked++
+	}
+	return ch
+}
+
+// issuedBy reports whether orderCerts would link child to parent: by key
+// identifier, or failing that by name.
+func issuedBy(child, parent *x509.Certificate) bool {
+	if len(child.AuthorityKeyId) > 0 && bytes.Equal(child.AuthorityKeyId, parent.SubjectKeyId) {
+		return true
+	}
+	return child.Issuer.String() == parent.Subject.String()
+}
+
+func isSelfSigned(c *x509.Certificate) bool {
+	return bytes.Equal(c.RawIssuer, c.RawSubject) && c.CheckSignatureFrom(c) == nil
+}
+
+// positions labels each certificate, by index into ch.certs, with its place
+// in the chain.
+func (ch lintChain) positions() []string {
+	labels := make([]string, len(ch.certs))
+	if ch.caBundle {
+		for i := range labels {
+			labels[i] = fmt.Sprintf("certificate %d", i+1)
+		}
+		return labels
+	}
+	for k, idx := range ch.ordered {
+		c := ch.certs[idx]
+		switch {
+		case k == 0:
+			labels[idx] = "leaf"
+		case isSelfSigned(c):
+			labels[idx] = "root"
+		case k < ch.linked:
+			labels[idx] = fmt.Sprintf("intermediate %d", k)
+		default:
+			labels[idx] = "unlinked"
+		}
+	}
+	return labels
+}
+
+// lintCertificates lints every certificate in a file. A lone certificate is
+// linted exactly as LintCertificateWithConfig does; in a bundle each finding
+// is labelled with the certificate's chain position, leaf-only rules run on
+// the leaf alone, and the chain rules check the bundle as a whole.
+func lintCertificates(certs []*x509.Certificate, cfg LintConfig) []LintIssue {
+	if len(certs) == 1 {
+		return LintCertificateWithConfig(certs[0], cfg)
+	}
+
+	ch := newLintChain(certs)
+	labels := ch.positions()
+	var issues []LintIssue
+	for _, idx := range ch.ordered {
+		for _, r := range lintRules {
+			if r.check == nil || !cfg.RuleEnabled(r) || (r.leafOnly && labels[idx] != "leaf") {
+				continue
+			}
+			if msg := r.check(ch.certs[idx]); msg != "" {
+				issue := cfg.issue(r, msg)
+				issue.Position = labels[idx]
+				issues = append(issues, issue)
+			}
+		}
+	}
+	for _, r := range lintRules {
+		if r.chainCheck == nil || !cfg.RuleEnabled(r) {
+			continue
+		}
+		if msg := r.chainCheck(ch); msg != "" {
+			issue := cfg.issue(r, msg)
+			issue.Position = "bundle"
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// lintCACertificates lints certificates a file names as trust anchors, such
+// as a kubeconfig's certificate-authority-data. There is no leaf, so the
+// leaf-only rules are skipped and each finding is labelled "ca".
+func lintCACertificates(certs []*x509.Certificate, cfg LintConfig) []LintIssue {
+	var issues []LintIssue
+	for i, c := range certs {
+		position := "ca"
+		if len(certs) > 1 {
+			position = fmt.Sprintf("ca %d", i+1)
+		}
+		for _, r := range lintRules {
+			if r.check == nil || !cfg.RuleEnabled(r) || r.leafOnly {
+				continue
+			}
+			if msg := r.check(c); msg != "" {
+				issue := cfg.issue(r, msg)
+				issue.Position = position
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues
+}
+
+func checkChainOrder(ch lintChain) string {
+	if ch.caBundle {
+		return ""
+	}
+	for i, idx := range ch.ordered {
+		if i != idx {
+			return "Certificates are not in leaf→intermediate→root order (certconv chain reorders them)"
+		}
+	}
+	return ""
+}
+
+func checkChainDuplicate(ch lintChain) string {
+	if ch.duplicates > 0 {
+		return fmt.Sprintf("%d duplicate certificate(s) in bundle", ch.duplicates)
+	}
+	return ""
+}
+
+func checkChainIncludesRoot(ch lintChain) string {
+	if ch.caBundle || ch.linked < 2 {
+		return ""
+	}
+	if root := ch.certs[ch.ordered[ch.linked-1]]; isSelfSigned(root) {
+		return "Bundle includes the root " + opensslName(root.Subject) + "; clients already hold it, so servers need not send it"
+	}
+	return ""
+}
+
+func checkChainMissingIntermediate(ch lintChain) string {
+	if ch.caBundle || ch.linked == len(ch.ordered) {
+		return ""
+	}
+	top := ch.certs[ch.ordered[ch.linked-1]]
+	return fmt.Sprintf("%d certificate(s) do not link to the leaf; no certificate in the bundle issued %s (issuer %s)",
+		len(ch.ordered)-ch.linked, opensslName(top.Subject), opensslName(top.Issuer))
+}
+
+func checkChainIssuerMismatch(ch lintChain) string {
+	if ch.caBundle {
+		return ""
+	}
+	var problems []string
+	for k := 1; k < ch.linked; k++ {
+		child, parent := ch.certs[ch.ordered[k-1]], ch.certs[ch.ordered[k]]
+		if !bytes.Equal(child.RawIssuer, parent.RawSubject) {
+			problems = append(problems, fmt.Sprintf("issuer of %s does not match subject of %s", opensslName(child.Subject), opensslName(parent.Subject)))
+			continue
+		}
+		// SHA-1 chains are reported by sha1-signature, not as mismatches.
+		var insecure x509.InsecureAlgorithmError
+		if err := child.CheckSignatureFrom(parent); err != nil && !errors.As(err, &insecure) {
+			problems = append(problems, fmt.Sprintf("%s is not signed by %s: %v", opensslName(child.Subject), opensslName(parent.Subject), err))
+		}
+	}
+	return strings.Join(problems, "; ")
+}
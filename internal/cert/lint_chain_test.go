@@ -0,0 +1,129 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+// chainPEM returns the chain's leaf, intermediate and root PEM, in that
+// order.
+func chainPEM(t *testing.T) (leaf, intermediate, root []byte) {
+	t.Helper()
+	cs := testutil.MakeChain(t)
+	read := func(p string) []byte {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	return read(cs.LeafPath), read(cs.IntermediatePath), read(cs.RootPath)
+}
+
+func lintBundle(t *testing.T, parts ...[]byte) []LintIssue {
+	t.Helper()
+	r, err := LintBytesWithConfig("bundle.pem", bytes.Join(parts, nil), "", LintConfig{})
+	if err != nil {
+		t.Fatalf("LintBytesWithConfig: %v", err)
+	}
+	return r.Issues
+}
+
+func TestLintBundle_ChainChecks(t *testing.T) {
+	leaf, intermediate, root := chainPEM(t)
+
+	if issues := lintBundle(t, leaf, intermediate); len(issues) != 0 {
+		t.Errorf("leaf+intermediate: want clean, got %+v", issues)
+	}
+
+	tests := []struct {
+		name  string
+		parts [][]byte
+		want  []string
+	}{
+		{"reversed", [][]byte{intermediate, leaf}, []string{"chain-order"}},
+		{"duplicate", [][]byte{leaf, intermediate, intermediate}, []string{"chain-duplicate"}},
+		{"root included", [][]byte{leaf, intermediate, root}, []string{"chain-includes-root"}},
+		{"gap", [][]byte{leaf, root}, []string{"chain-missing-intermediate"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := lintBundle(t, tt.parts...)
+			if got := issueCodes(issues); !slices.Equal(got, tt.want) {
+				t.Fatalf("codes = %v, want %v (%+v)", got, tt.want, issues)
+			}
+			if issues[0].Position != "bundle" {
+				t.Errorf("position = %q, want bundle", issues[0].Position)
+			}
+		})
+	}
+
+	// A trust bundle of CA certificates has no leaf to chain from.
+	if issues := lintBundle(t, root, intermediate); len(issues) != 0 {
+		t.Errorf("CA bundle: want clean, got %+v", issues)
+	}
+}
+
+func TestLintBundle_LabelsIntermediateFindings(t *testing.T) {
+	leaf, _, root := chainPEM(t)
+	rootCert, err := ParseCertBytes(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafCert, err := ParseCertBytes(leaf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An impostor with the intermediate's name but a different key, and
+	// expired: the leaf links to it by name but its signature fails.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(99),
+		Subject:               leafCert.Issuer,
+		NotBefore:             time.Now().Add(-48 * time.Hour),
+		NotAfter:              time.Now().Add(-24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	// Issued under the root's name, so it is not self-signed.
+	parent := *rootCert
+	parent.PublicKey = &key.PublicKey
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, &parent, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	impostor := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	issues := lintBundle(t, leaf, impostor)
+	byCode := map[string]LintIssue{}
+	for _, i := range issues {
+		byCode[i.Code] = i
+	}
+	if i, ok := byCode["expired"]; !ok || i.Position != "intermediate 1" {
+		t.Errorf("want expired on intermediate 1, got %+v", issues)
+	}
+	if _, ok := byCode["chain-issuer-mismatch"]; !ok {
+		t.Errorf("want chain-issuer-mismatch, got %+v", issues)
+	}
+	for _, i := range issues {
+		if i.Code == "missing-sans" {
+			t.Errorf("leaf-only rule ran on %s", i.Position)
+		}
+	}
+}
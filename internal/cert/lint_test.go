@@ -306,3 +306,87 @@ func TestLintFile(t *testing.T) {
 		t.Errorf("File = %q, want %q", result.File, path)
 	}
 }
+
+func TestLint_WithConfig_DisableAndSeverity(t *testing.T) {
+	c := makeCert(t, func(tmpl *x509.Certificate, key any) any {
+		tmpl.NotAfter = time.Now().Add(5 * 365 * 24 * time.Hour)
+		tmpl.DNSNames = nil
+		return key
+	})
+
+	cfg := LintConfig{
+		Enabled:  map[string]bool{"missing-sans": false},
+		Severity: map[string]LintSeverity{"long-validity": LintError},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	issues := LintCertificateWithConfig(c, cfg)
+	if len(issues) != 1 || issues[0].Code != "long-validity" || issues[0].Severity != LintError {
+		t.Errorf("issues = %+v, want long-validity as error only", issues)
+	}
+
+	// Flags layer over the config file: re-enable missing-sans, drop long-validity.
+	merged := cfg.Merge(LintConfig{Enabled: map[string]bool{"missing-sans": true, "long-validity": false}})
+	issues = LintCertificateWithConfig(c, merged)
+	if len(issues) != 1 || issues[0].Code != "missing-sans" || issues[0].Severity != LintWarning {
+		t.Errorf("merged issues = %+v, want missing-sans warning only", issues)
+	}
+}
+
+func TestLintConfig_ValidateRejectsUnknown(t *testing.T) {
+	if err := (LintConfig{Enabled: map[string]bool{"no-such-rule": false}}).Validate(); err == nil {
+		t.Error("Validate() accepted an unknown rule")
+	}
+	if err := (LintConfig{Severity: map[string]LintSeverity{"expired": "fatal"}}).Validate(); err == nil {
+		t.Error("Validate() accepted an unknown severity")
+	}
+	seen := map[string]bool{}
+	for _, r := range LintRules() {
+		if r.ID == "" || r.Description == "" || seen[r.ID] {
+			t.Errorf("bad rule %+v", r)
+		}
+		seen[r.ID] = true
+	}
+}
+
+func TestLintPaths_DirectoryAndErrors(t *testing.T) {
+	dir := t.TempDir()
+	clean := writeCertFile(t, makeCert(t, nil))
+	noSANs := writeCertFile(t, makeCert(t, func(tmpl *x509.Certificate, key any) any {
+		tmpl.DNSNames = nil
+		return key
+	}))
+	for name, src := range map[string]string{"a.pem": clean, "sub/b.pem": noSANs} {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := LintPaths([]string{dir}, false, LintConfig{})
+	if len(results) != 1 || !results[0].Clean {
+		t.Fatalf("non-recursive results = %+v, want a.pem only", results)
+	}
+
+	missing := filepath.Join(dir, "missing.pem")
+	results = LintPaths([]string{dir, missing}, true, LintConfig{})
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3: %+v", len(results), results)
+	}
+	if r := results[1]; r.File != filepath.Join(dir, "sub", "b.pem") || len(r.Issues) != 1 || r.Issues[0].Code != "missing-sans" {
+		t.Errorf("sub/b.pem result = %+v", r)
+	}
+	if r := results[2]; r.File != missing || r.Error == "" || r.Issues == nil {
+		t.Errorf("missing file result = %+v, want Error set and empty Issues", r)
+	}
+}
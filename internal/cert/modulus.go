@@ -23,6 +23,9 @@ func (e *Engine) RSAModulus(ctx context.Context, path string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("detect type: %w", err)
 	}
+	if e.native() {
+		return nativeRSAModulus(path, ft)
+	}
 
 	var stdout, stderr []byte
 	switch ft {
@@ -0,0 +1,659 @@
+package cert
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+// This file holds the pure-Go implementations used when the Engine runs with
+// BackendGo. Each function mirrors the behaviour (and, where practical, the
+// output wording) of its openssl-backed counterpart.
+
+// isCertFileType reports whether ft carries one or more X.509 certificates.
+func isCertFileType(ft FileType) bool {
+	switch ft {
+	case FileTypeCert, FileTypeCombined, FileTypeDER, FileTypePFX, FileTypeP7B, FileTypeJKS, FileTypeK8sSecret, FileTypeKubeconfig:
+		return true
+	}
+	return false
+}
+
+// loadCertificates reads every certificate in path without calling openssl.
+func loadCertificates(path string, ft FileType, password string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ft {
+	case FileTypePFX:
+		_, certs, err := ParsePFXCertificates(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("read pfx: %w", err)
+		}
+		return certs, nil
+	case FileTypeP7B:
+		certs, err := ParsePKCS7Certificates(data)
+		if err != nil {
+			return nil, fmt.Errorf("read p7b: %w", err)
+		}
+		return certs, nil
+	case FileTypeJKS:
+		ks, err := ParseKeystore(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("read keystore: %w", err)
+		}
+		certs := ks.Certificates()
+		if len(certs) == 0 {
+			return nil, fmt.Errorf("read keystore: no certificates found")
+		}
+		return certs, nil
+	case FileTypeK8sSecret:
+		secrets, err := ParseK8sSecrets(data)
+		if err != nil {
+			return nil, fmt.Errorf("read secret: %w", err)
+		}
+		return k8sSecretCertificates(secrets), nil
+	case FileTypeKubeconfig:
+		entries, err := ParseKubeconfig(data, filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("read kubeconfig: %w", err)
+		}
+		return kubeconfigCertificates(entries), nil
+	case FileTypeDER:
+		c, err := x509.ParseCertificate(data)
+		if err != nil {
+			return nil, fmt.Errorf("read der cert: %w", err)
+		}
+		return []*x509.Certificate{c}, nil
+	default:
+		certs, _, err := parsePEMCerts(data)
+		if err != nil {
+			return nil, fmt.Errorf("read pem cert: %w", err)
+		}
+		if len(certs) == 0 {
+			// Some tools write DER with a .pem/.crt extension.
+			if c, derr := x509.ParseCertificate(data); derr == nil {
+				return []*x509.Certificate{c}, nil
+			}
+			return nil, fmt.Errorf("read pem cert: no certificate found")
+		}
+		return certs, nil
+	}
+}
+
+func loadFirstCertificate(path string, ft FileType, password string) (*x509.Certificate, error) {
+	certs, err := loadCertificates(path, ft, password)
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// fillNativeSummary populates summary fields in the same shape openssl's
+// "x509 -noout -subject -issuer -dates -serial" output produces.
+func fillNativeSummary(s *CertSummary, c *x509.Certificate) {
+	s.Subject = opensslName(c.Subject)
+	s.Issuer = opensslName(c.Issuer)
+	s.NotBefore = formatOpenSSLTime(c.NotBefore)
+	s.NotAfter = formatOpenSSLTime(c.NotAfter)
+	s.Serial = opensslSerial(c.SerialNumber)
+	EnrichSummary(s, c)
+}
+
+func nativeSummary(path string, ft FileType, password string) (*CertSummary, error) {
+	s := &CertSummary{File: path, FileType: ft}
+	if ft == FileTypeJKS {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return s, err
+		}
+		return s, keystoreSummary(s, data, password, fillNativeSummary)
+	}
+	if ft == FileTypeK8sSecret {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return s, err
+		}
+		return s, k8sSecretSummary(s, data, fillNativeSummary)
+	}
+	if ft == FileTypeKubeconfig {
+		entries, err := readKubeconfig(path)
+		if err != nil {
+			return s, err
+		}
+		kubeconfigSummary(s, entries, fillNativeSummary)
+		return s, nil
+	}
+	c, err := loadFirstCertificate(path, ft, password)
+	if err != nil {
+		return s, err
+	}
+	fillNativeSummary(s, c)
+	return s, nil
+}
+
+func nativeDetails(path string, ft FileType, password string) (*CertDetails, error) {
+	d := &CertDetails{File: path, FileType: ft}
+	if ft == FileTypeJKS {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return d, err
+		}
+		ks, err := ParseKeystore(data, password)
+		if err != nil {
+			return d, fmt.Errorf("read keystore: %w", err)
+		}
+		d.RawText = KeystoreDetailsText(ks)
+		return d, nil
+	}
+	if ft == FileTypeK8sSecret {
+		secrets, err := readK8sSecrets(path)
+		if err != nil {
+			return d, err
+		}
+		d.RawText = K8sSecretDetailsText(secrets)
+		return d, nil
+	}
+	if ft == FileTypeKubeconfig {
+		entries, err := readKubeconfig(path)
+		if err != nil {
+			return d, err
+		}
+		d.RawText = KubeconfigDetailsText(entries)
+		return d, nil
+	}
+	c, err := loadFirstCertificate(path, ft, password)
+	if err != nil {
+		return d, err
+	}
+	d.RawText = RenderCertificateText(c)
+	return d, nil
+}
+
+// nativePublicKeyText renders a PEM public key like "openssl pkey -pubin -text".
+func nativePublicKeyText(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	pub, err := parsePEMPublicKey(data)
+	if err != nil {
+		return "", err
+	}
+	return renderPublicKeyText(pub, ""), nil
+}
+
+func parsePEMPublicKey(data []byte) (any, error) {
+	rest := data
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			return nil, errors.New("no PEM public key found")
+		}
+		rest = r
+		switch block.Type {
+		case "PUBLIC KEY":
+			return x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			return x509.ParsePKCS1PublicKey(block.Bytes)
+		}
+	}
+}
+
+func nativeExpiry(path string, ft FileType, days int) (*ExpiryResult, error) {
+	var c *x509.Certificate
+	var err error
+	switch ft {
+	case FileTypeJKS:
+		c, err = keystoreSoonestExpiring(path)
+	case FileTypeK8sSecret:
+		var secrets []K8sSecret
+		if secrets, err = readK8sSecrets(path); err == nil {
+			c = k8sSecretSoonestExpiring(secrets)
+		}
+	case FileTypeKubeconfig:
+		var entries []KubeconfigEntry
+		if entries, err = readKubeconfig(path); err == nil {
+			c = kubeconfigSoonestExpiring(entries)
+		}
+	case FileTypeJWK:
+		var keys []JWK
+		if keys, err = readJWKs(path); err == nil {
+			c, err = jwkSoonestExpiring(keys)
+		}
+	case FileTypeSSHCert:
+		sc, _, err := readSSHCertificate(path)
+		if err != nil {
+			return nil, fmt.Errorf("read certificate expiry: %w", err)
+		}
+		return sshCertExpiryResult(sc, days), nil
+	default:
+		c, err = loadFirstCertificate(path, ft, "")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read certificate expiry: %w", err)
+	}
+	return &ExpiryResult{
+		ExpiryDate: formatOpenSSLTime(c.NotAfter),
+		ExpiresAt:  c.NotAfter.UTC(),
+		DaysLeft:   int(time.Until(c.NotAfter).Hours() / 24),
+		Valid:      time.Now().Add(time.Duration(days) * 24 * time.Hour).Before(c.NotAfter),
+		Subject:    opensslName(c.Subject),
+	}, nil
+}
+
+func nativeVerifyChain(certPath, caPath string, opts VerifyOptions) (*VerifyResult, error) {
+	ft, err := DetectType(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("detect type: %w", err)
+	}
+	certs, err := loadCertificates(certPath, ft, "")
+	if err != nil {
+		return nil, err
+	}
+	roots, err := loadCertBundle(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("read CA bundle: %w", err)
+	}
+	var intermediates []*x509.Certificate
+	if opts.IntermediatesFile != "" {
+		intermediates, err = loadCertBundle(opts.IntermediatesFile)
+		if err != nil {
+			return nil, fmt.Errorf("read intermediates: %w", err)
+		}
+	}
+	usage := x509.ExtKeyUsageAny
+	if opts.Purpose != "" {
+		if usage, err = ParseVerifyPurpose(opts.Purpose); err != nil {
+			return nil, err
+		}
+	}
+	at := opts.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	rootPool := x509.NewCertPool()
+	for _, r := range roots {
+		rootPool.AddCert(r)
+	}
+	interPool := x509.NewCertPool()
+	for _, c := range intermediates {
+		interPool.AddCert(c)
+	}
+
+	leaf := certs[0]
+	chains, verr := leaf.Verify(x509.VerifyOptions{
+		Roots:         rootPool,
+		Intermediates: interPool,
+		DNSName:       opts.Hostname,
+		CurrentTime:   at,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	if verr == nil && len(opts.CRLFiles) > 0 {
+		crls, err := loadCRLFiles(opts.CRLFiles)
+		if err != nil {
+			return nil, err
+		}
+		issuer := leaf
+		if len(chains[0]) > 1 {
+			issuer = chains[0][1]
+		}
+		if reason, detail := checkCRLRevocation(leaf, issuer, crls, at); reason != "" {
+			return failedVerifyResult(certPath, reason, 0, chains[0], detail), nil
+		}
+	}
+	if verr == nil {
+		result := &VerifyResult{Valid: true, Output: certPath + ": OK"}
+		for _, chain := range chains {
+			result.Chains = append(result.Chains, describeVerifyChain(chain))
+		}
+		return result, nil
+	}
+
+	path := bestEffortChain(leaf, append(intermediates, roots...))
+	reason, depth := classifyVerifyError(verr, path, at)
+	result := failedVerifyResult(certPath, reason, depth, path, "")
+	result.Output = fmt.Sprintf("%s: verification failed: %s", certPath, verr.Error())
+
+	var details []string
+	switch reason {
+	case VerifyReasonExpired, VerifyReasonNotYetValid:
+		c := path[depth]
+		when := "now"
+		if !opts.At.IsZero() {
+			when = "at " + formatOpenSSLTime(at)
+		}
+		details = append(details, fmt.Sprintf("Certificate at depth %d (%s) is not valid %s: valid %s to %s",
+			depth, opensslName(c.Subject), when, formatOpenSSLTime(c.NotBefore), formatOpenSSLTime(c.NotAfter)))
+	case VerifyReasonUnknownAuthority:
+		details = append(details, "Certificate issuer not found in CA bundle")
+	case VerifyReasonHostnameMismatch:
+		details = append(details, fmt.Sprintf("Certificate does not cover hostname %q (SANs: %s)", opts.Hostname, strings.Join(collectSANs(leaf), ", ")))
+	case VerifyReasonIncompatibleUsage:
+		details = append(details, fmt.Sprintf("Certificate is not valid for purpose %s", opts.Purpose))
+	}
+	if leaf.Subject.String() == leaf.Issuer.String() && leaf.CheckSignatureFrom(leaf) == nil {
+		details = append(details, "Certificate is self-signed")
+	}
+	result.Details = strings.Join(details, "; ")
+	return result, nil
+}
+
+// loadCertBundle reads every certificate from a PEM bundle, or a single DER
+// certificate.
+func loadCertBundle(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	certs, _, err := parsePEMCerts(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		if c, derr := x509.ParseCertificate(data); derr == nil {
+			certs = append(certs, c)
+		}
+	}
+	return certs, nil
+}
+
+func nativeMatchKeyToCert(certPath, keyPath, keyPassword string) (*MatchResult, error) {
+	ft, _ := DetectType(certPath)
+	var certPub crypto.PublicKey
+	if ft == FileTypePublicKey {
+		pub, err := readPublicKeyFile(certPath)
+		if err != nil {
+			return nil, fmt.Errorf("read public key: %w", err)
+		}
+		certPub = pub
+	} else {
+		if !isCertFileType(ft) {
+			ft = FileTypeCert
+		}
+		c, err := loadFirstCertificate(certPath, ft, "")
+		if err != nil {
+			return nil, fmt.Errorf("read certificate public key: %w", err)
+		}
+		certPub = c.PublicKey
+	}
+	keyPub, err := privateKeyFilePublic(keyPath, keyPassword)
+	if err != nil {
+		return nil, fmt.Errorf("read key public key: %w", err)
+	}
+	return &MatchResult{Match: publicKeysEqual(certPub, keyPub)}, nil
+}
+
+// readPublicKeyFile parses a PEM public key or an OpenSSH public key line.
+func readPublicKeyFile(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if hasPublicKeyMarkerBytes(data) {
+		return parsePEMPublicKey(data)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse OpenSSH public key: %w", err)
+	}
+	cpk, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %s", pub.Type())
+	}
+	return cpk.CryptoPublicKey(), nil
+}
+
+// privateKeyFilePublic returns the public half of the private key at path.
+// An encrypted OpenSSH key read without a password falls back to the public
+// key stored in its cleartext header.
+func privateKeyFilePublic(path, password string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading key: %w", err)
+	}
+	key, err := ParsePrivateKeyBytes(data, password)
+	if errors.Is(err, ErrKeyPasswordRequired) {
+		if info := readOpenSSHKeyInfo(data); info != nil {
+			if cpk, ok := info.publicKey.(ssh.CryptoPublicKey); ok {
+				return cpk.CryptoPublicKey(), nil
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key.Public(), nil
+}
+
+func nativeRSAModulus(path string, ft FileType) (string, error) {
+	var pub any
+	switch ft {
+	case FileTypeCert, FileTypeCombined, FileTypeDER:
+		c, err := loadFirstCertificate(path, ft, "")
+		if err != nil {
+			return "", err
+		}
+		pub = c.PublicKey
+	case FileTypeKey:
+		key, err := ParsePrivateKeyFile(path, "")
+		if err != nil {
+			return "", err
+		}
+		pub = key.Public()
+	case FileTypePublicKey:
+		if !hasPublicKeyMarker(path) {
+			return "", ErrNotRSA
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		pub, err = parsePEMPublicKey(data)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported file type: %s", ft)
+	}
+
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return "", ErrNotRSA
+	}
+	return strings.ToUpper(rsaPub.N.Text(16)), nil
+}
+
+// nativePFXBytes builds a PKCS#12 container from a PEM cert (plus any chain
+// certs in the same file or caPath) and its private key.
+func nativePFXBytes(certPath, keyPath, password, caPath, keyPassword string) ([]byte, error) {
+	key, leaf, chain, err := loadKeyAndChain(certPath, keyPath, caPath, keyPassword)
+	if err != nil {
+		return nil, err
+	}
+	out, err := pkcs12.Modern.Encode(key, leaf, chain, password)
+	if err != nil {
+		return nil, fmt.Errorf("create PFX: %w", err)
+	}
+	return out, nil
+}
+
+// loadKeyAndChain reads a private key and the certificate it belongs to. Any
+// other certificates in certPath, followed by those in caPath, form the chain.
+func loadKeyAndChain(certPath, keyPath, caPath, keyPassword string) (crypto.Signer, *x509.Certificate, []*x509.Certificate, error) {
+	certs, err := loadCertificates(certPath, FileTypeCert, "")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	key, err := ParsePrivateKeyFile(keyPath, keyPassword)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("read private key: %w", err)
+	}
+
+	leafIdx := -1
+	for i, c := range certs {
+		if publicKeysEqual(c.PublicKey, key.Public()) {
+			leafIdx = i
+			break
+		}
+	}
+	if leafIdx < 0 {
+		return nil, nil, nil, fmt.Errorf("private key does NOT match certificate")
+	}
+	leaf := certs[leafIdx]
+
+	var chain []*x509.Certificate
+	for i, c := range certs {
+		if i != leafIdx {
+			chain = append(chain, c)
+		}
+	}
+	if caPath != "" {
+		caCerts, err := loadCertificates(caPath, FileTypeCert, "")
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("read CA: %w", err)
+		}
+		chain = append(chain, caCerts...)
+	}
+	return key, leaf, chain, nil
+}
+
+func nativeFromPFX(inputPath, outputDir, password string) (*FromPFXResult, error) {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("read PFX: %w", err)
+	}
+	key, leaf, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		perr := classifyPFXBytesError(err, nil)
+		switch {
+		case IsPFXIncorrectPassword(perr):
+			return nil, fmt.Errorf("invalid PFX or wrong password: incorrect password")
+		case errors.Is(perr, ErrPFXNotPKCS12):
+			return nil, fmt.Errorf("file is not a valid PKCS#12/PFX file")
+		default:
+			return nil, fmt.Errorf("invalid PFX: %s", strings.TrimSpace(perr.Error()))
+		}
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("extract private key: %w", err)
+	}
+
+	if err := os.MkdirAll(outputDir, 0o700); err != nil {
+		return nil, fmt.Errorf("create output directory: %w", err)
+	}
+
+	base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	result := &FromPFXResult{
+		CertFile: filepath.Join(outputDir, base+".crt"),
+		KeyFile:  filepath.Join(outputDir, base+".key"),
+	}
+	// Check every output before writing any, so a clash leaves nothing behind.
+	caFile := filepath.Join(outputDir, base+"-ca.crt")
+	outputs := []string{result.CertFile, result.KeyFile}
+	if len(caCerts) > 0 {
+		outputs = append(outputs, caFile)
+	}
+	for _, p := range outputs {
+		if err := ensureNotExists(p); err != nil {
+			return nil, err
+		}
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})
+	if err := writeFileExclusive(result.CertFile, certPEM, 0o644); err != nil {
+		return nil, fmt.Errorf("extract certificate: %w", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	if err := writeFileExclusive(result.KeyFile, keyPEM, 0o600); err != nil {
+		return nil, fmt.Errorf("extract private key: %w", err)
+	}
+
+	if len(caCerts) > 0 {
+		if err := writeFileExclusive(caFile, encodeCertsPEM(caCerts), 0o644); err != nil {
+			return nil, err
+		}
+		result.CAFile = caFile
+	}
+	return result, nil
+}
+
+func nativeToDER(inputPath, outputPath string, isKey bool, keyPassword string) error {
+	if isKey {
+		if err := ValidatePEMKey(inputPath); err != nil {
+			return err
+		}
+		key, err := ParsePrivateKeyFile(inputPath, keyPassword)
+		if err != nil {
+			return fmt.Errorf("convert key to DER: %w", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return fmt.Errorf("convert key to DER: %w", err)
+		}
+		return writeFileExclusive(outputPath, der, 0o600)
+	}
+
+	if err := ValidatePEMCert(inputPath); err != nil {
+		return err
+	}
+	c, err := ParseCertFile(inputPath)
+	if err != nil {
+		return fmt.Errorf("convert cert to DER: %w", err)
+	}
+	return writeFileExclusive(outputPath, c.Raw, 0o644)
+}
+
+func nativeFromDER(inputPath, outputPath string, isKey bool, keyPassword string) error {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return err
+	}
+
+	if isKey {
+		key, err := parsePrivateKeyDER(data)
+		if err != nil && keyPassword != "" {
+			if plain, derr := decryptPKCS8(data, []byte(keyPassword)); derr == nil {
+				key, err = parsePrivateKeyDER(plain)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("convert DER to key PEM: %w (try without --key if this is a certificate)", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return fmt.Errorf("convert DER to key PEM: %w", err)
+		}
+		return writeFileExclusive(outputPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+	}
+
+	c, err := x509.ParseCertificate(data)
+	if err != nil {
+		return fmt.Errorf("convert DER to cert PEM: %w (try with --key if this is a private key)", err)
+	}
+	return writeFileExclusive(outputPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw}), 0o644)
+}
+
+// encodeCertsPEM concatenates certificates as PEM CERTIFICATE blocks.
+func encodeCertsPEM(certs []*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, c := range certs {
+		_ = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})
+	}
+	return buf.Bytes()
+}
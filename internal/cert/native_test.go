@@ -0,0 +1,322 @@
+package cert
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+// refusingExec fails the test if the Go backend ever shells out.
+type refusingExec struct{ t *testing.T }
+
+func (r refusingExec) Run(ctx context.Context, args ...string) ([]byte, []byte, error) {
+	return r.RunWithExtraFiles(ctx, nil, args...)
+}
+
+func (r refusingExec) RunWithExtraFiles(_ context.Context, _ []ExtraFile, args ...string) ([]byte, []byte, error) {
+	r.t.Helper()
+	r.t.Fatalf("go backend called openssl: %v", args)
+	return nil, nil, nil
+}
+
+func newNativeTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	e := NewEngine(refusingExec{t: t})
+	e.SetBackend(BackendGo)
+	return e
+}
+
+func TestNative_SummaryAndDetails(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	eng := newNativeTestEngine(t)
+	ctx := context.Background()
+
+	s, err := eng.Summary(ctx, pair.CertPath, "")
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if !strings.Contains(s.Subject, "CN = test.local") {
+		t.Errorf("Subject = %q, want openssl-style CN", s.Subject)
+	}
+	if s.Serial != "01" {
+		t.Errorf("Serial = %q, want 01", s.Serial)
+	}
+	if !strings.HasSuffix(s.NotAfter, "GMT") {
+		t.Errorf("NotAfter = %q, want openssl date format", s.NotAfter)
+	}
+	if s.PublicKeyInfo != "RSA 2048" {
+		t.Errorf("PublicKeyInfo = %q", s.PublicKeyInfo)
+	}
+
+	d, err := eng.Details(ctx, pair.CertPath, "")
+	if err != nil {
+		t.Fatalf("Details() error = %v", err)
+	}
+	for _, want := range []string{"Certificate:", "Public-Key: (2048 bit)", "X509v3 Basic Constraints: critical", "CA:TRUE"} {
+		if !strings.Contains(d.RawText, want) {
+			t.Errorf("Details missing %q:\n%s", want, d.RawText)
+		}
+	}
+}
+
+func TestNative_Expiry(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	eng := newNativeTestEngine(t)
+
+	r, err := eng.Expiry(context.Background(), pair.CertPath, 30)
+	if err != nil {
+		t.Fatalf("Expiry() error = %v", err)
+	}
+	if !r.Valid || r.DaysLeft < 360 {
+		t.Errorf("Expiry() = %+v, want valid with ~365 days", r)
+	}
+	r, err = eng.Expiry(context.Background(), pair.CertPath, 400)
+	if err != nil {
+		t.Fatalf("Expiry() error = %v", err)
+	}
+	if r.Valid {
+		t.Error("Expiry(400 days) should not be valid for a 365-day cert")
+	}
+}
+
+func TestNative_PFXRoundTrip(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	eng := newNativeTestEngine(t)
+	ctx := context.Background()
+
+	pfxPath := filepath.Join(pair.Dir, "native.pfx")
+	if err := eng.ToPFX(ctx, pair.CertPath, pair.KeyPath, pfxPath, "s3cret", "", ""); err != nil {
+		t.Fatalf("ToPFX() error = %v", err)
+	}
+	if info, err := os.Stat(pfxPath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("PFX stat = %v, %v; want mode 0600", info, err)
+	}
+
+	if _, err := eng.FromPFX(ctx, pfxPath, filepath.Join(pair.Dir, "bad"), "wrong"); err == nil ||
+		!strings.Contains(err.Error(), "incorrect password") {
+		t.Fatalf("FromPFX(wrong password) error = %v", err)
+	}
+
+	res, err := eng.FromPFX(ctx, pfxPath, filepath.Join(pair.Dir, "out"), "s3cret")
+	if err != nil {
+		t.Fatalf("FromPFX() error = %v", err)
+	}
+	m, err := eng.MatchKeyToCert(ctx, res.CertFile, res.KeyFile, "")
+	if err != nil || !m.Match {
+		t.Fatalf("extracted pair should match: %v, %v", m, err)
+	}
+	if info, _ := os.Stat(res.KeyFile); info.Mode().Perm() != 0o600 {
+		t.Errorf("key mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	s, err := eng.Summary(ctx, pfxPath, "s3cret")
+	if err != nil || !strings.Contains(s.Subject, "test.local") {
+		t.Fatalf("Summary(pfx) = %+v, %v", s, err)
+	}
+
+	// An existing CA file fails the extraction before anything is written.
+	chain := testutil.MakeChain(t)
+	chainPFX := filepath.Join(chain.Dir, "chain.pfx")
+	if err := eng.ToPFX(ctx, chain.LeafPath, chain.LeafKeyPath, chainPFX, "s3cret", chain.IntermediatePath, ""); err != nil {
+		t.Fatalf("ToPFX(chain) error = %v", err)
+	}
+	outDir := filepath.Join(chain.Dir, "clash")
+	if err := os.MkdirAll(outDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "chain-ca.crt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eng.FromPFX(ctx, chainPFX, outDir, "s3cret"); !IsOutputExists(err) {
+		t.Fatalf("FromPFX(existing CA file) error = %v", err)
+	}
+	if names, _ := os.ReadDir(outDir); len(names) != 1 {
+		t.Errorf("failed extraction wrote files: %v", names)
+	}
+}
+
+func TestNative_DERRoundTrip(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	eng := newNativeTestEngine(t)
+	ctx := context.Background()
+
+	derPath := filepath.Join(pair.Dir, "cert.der")
+	if err := eng.ToDER(ctx, pair.CertPath, derPath, false, ""); err != nil {
+		t.Fatalf("ToDER() error = %v", err)
+	}
+	pemPath := filepath.Join(pair.Dir, "back.pem")
+	if err := eng.FromDER(ctx, derPath, pemPath, false, ""); err != nil {
+		t.Fatalf("FromDER() error = %v", err)
+	}
+	orig, _ := ParseCertFile(pair.CertPath)
+	back, err := ParseCertFile(pemPath)
+	if err != nil || !orig.Equal(back) {
+		t.Fatalf("round-tripped cert differs: %v", err)
+	}
+
+	keyDER := filepath.Join(pair.Dir, "key.der")
+	if err := eng.ToDER(ctx, pair.KeyPath, keyDER, true, ""); err != nil {
+		t.Fatalf("ToDER(key) error = %v", err)
+	}
+	keyPEM := filepath.Join(pair.Dir, "key.pem")
+	if err := eng.FromDER(ctx, keyDER, keyPEM, true, ""); err != nil {
+		t.Fatalf("FromDER(key) error = %v", err)
+	}
+	m, err := eng.MatchKeyToCert(ctx, pair.CertPath, keyPEM, "")
+	if err != nil || !m.Match {
+		t.Fatalf("round-tripped key should match: %v, %v", m, err)
+	}
+
+	if err := eng.ToDER(ctx, pair.CertPath, derPath, false, ""); !IsOutputExists(err) {
+		t.Fatalf("ToDER() over existing output error = %v, want OutputExistsError", err)
+	}
+}
+
+func TestNative_VerifyAndMatch(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	other := testutil.MakeCertPair(t)
+	eng := newNativeTestEngine(t)
+	ctx := context.Background()
+
+	v, err := eng.VerifyChain(ctx, pair.CertPath, pair.CertPath)
+	if err != nil || !v.Valid || !strings.HasSuffix(v.Output, ": OK") {
+		t.Fatalf("VerifyChain(self) = %+v, %v", v, err)
+	}
+
+	v, err = eng.VerifyChain(ctx, pair.CertPath, other.CertPath)
+	if err != nil {
+		t.Fatalf("VerifyChain() error = %v", err)
+	}
+	if v.Valid || !strings.Contains(v.Details, "issuer not found") {
+		t.Fatalf("VerifyChain(wrong CA) = %+v", v)
+	}
+
+	m, err := eng.MatchKeyToCert(ctx, pair.CertPath, other.KeyPath, "")
+	if err != nil || m.Match {
+		t.Fatalf("MatchKeyToCert(mismatch) = %v, %v", m, err)
+	}
+}
+
+func TestNative_FromP7B(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	eng := newNativeTestEngine(t)
+
+	c, err := ParseCertFile(pair.CertPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p7bPath := filepath.Join(pair.Dir, "bundle.p7b")
+	if err := os.WriteFile(p7bPath, makeCertsOnlyP7B(t, c, c), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := eng.FromP7B(context.Background(), p7bPath, filepath.Join(pair.Dir, "p7b"))
+	if err != nil {
+		t.Fatalf("FromP7B() error = %v", err)
+	}
+	if len(res.CertFiles) != 2 {
+		t.Fatalf("CertFiles = %v, want 2", res.CertFiles)
+	}
+
+	s, err := eng.Summary(context.Background(), p7bPath, "")
+	if err != nil || s.FileType != FileTypeP7B || !strings.Contains(s.Subject, "test.local") {
+		t.Fatalf("Summary(p7b) = %+v, %v", s, err)
+	}
+}
+
+func TestNative_EncryptedPKCS8Key(t *testing.T) {
+	if _, err := exec.LookPath("openssl"); err != nil {
+		t.Skip("openssl not available to create an encrypted key")
+	}
+	pair := testutil.MakeCertPair(t)
+	encPath := filepath.Join(pair.Dir, "enc.key")
+	out, err := exec.Command("openssl", "pkcs8", "-topk8", "-v2", "aes-256-cbc",
+		"-in", pair.KeyPath, "-out", encPath, "-passout", "pass:hunter2").CombinedOutput()
+	if err != nil {
+		t.Fatalf("openssl pkcs8: %v: %s", err, out)
+	}
+
+	eng := newNativeTestEngine(t)
+	ctx := context.Background()
+	m, err := eng.MatchKeyToCert(ctx, pair.CertPath, encPath, "hunter2")
+	if err != nil || !m.Match {
+		t.Fatalf("MatchKeyToCert(encrypted) = %v, %v", m, err)
+	}
+	if _, err := eng.MatchKeyToCert(ctx, pair.CertPath, encPath, "wrong"); err == nil {
+		t.Fatal("expected error for wrong key password")
+	}
+}
+
+func TestNative_RSAModulusMatchesOpenSSL(t *testing.T) {
+	if _, err := exec.LookPath("openssl"); err != nil {
+		t.Skip("openssl not available")
+	}
+	pair := testutil.MakeCertPair(t)
+	ctx := context.Background()
+
+	want, err := NewEngine(&OSExecutor{}).RSAModulus(ctx, pair.CertPath)
+	if err != nil {
+		t.Fatalf("openssl RSAModulus() error = %v", err)
+	}
+	eng := newNativeTestEngine(t)
+	for _, p := range []string{pair.CertPath, pair.KeyPath} {
+		got, err := eng.RSAModulus(ctx, p)
+		if err != nil {
+			t.Fatalf("RSAModulus(%s) error = %v", p, err)
+		}
+		if got != want {
+			t.Errorf("RSAModulus(%s) = %s, want %s", p, got, want)
+		}
+	}
+
+	ec := testutil.MakeECCertPair(t)
+	if _, err := eng.RSAModulus(ctx, ec.CertPath); err != ErrNotRSA {
+		t.Errorf("RSAModulus(EC) error = %v, want ErrNotRSA", err)
+	}
+}
+
+// makeCertsOnlyP7B builds a minimal PEM-armoured PKCS#7 SignedData bundle.
+func makeCertsOnlyP7B(t *testing.T, certs ...*x509.Certificate) []byte {
+	t.Helper()
+	var raw []byte
+	for _, c := range certs {
+		raw = append(raw, c.Raw...)
+	}
+	sd := struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      struct{ ContentType asn1.ObjectIdentifier }
+		Certificates     asn1.RawValue
+		SignerInfos      asn1.RawValue
+	}{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: raw},
+		SignerInfos:      asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+	}
+	sd.ContentInfo.ContentType = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	sdDER, err := asn1.Marshal(sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ci := struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{
+		ContentType: oidPKCS7SignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sdDER},
+	}
+	der, err := asn1.Marshal(ci)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PKCS7", Bytes: der})
+}
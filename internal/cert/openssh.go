@@ -0,0 +1,336 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const opensshKeyMagic = "openssh-key-v1\x00"
+
+// marshalOpenSSHPrivateKey encodes key in the unencrypted openssh-key-v1
+// format written by ssh-keygen (PROTOCOL.key in the OpenSSH sources).
+func marshalOpenSSHPrivateKey(key crypto.Signer, comment string) (*pem.Block, error) {
+	pub, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return nil, fmt.Errorf("unsupported key for OpenSSH: %w", err)
+	}
+
+	var fields []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if len(k.Primes) != 2 {
+			return nil, fmt.Errorf("multi-prime RSA keys cannot be written in OpenSSH format")
+		}
+		k.Precompute()
+		fields = ssh.Marshal(struct {
+			N, E, D, Iqmp, P, Q *big.Int
+		}{k.N, big.NewInt(int64(k.E)), k.D, k.Precomputed.Qinv, k.Primes[0], k.Primes[1]})
+	case *ecdsa.PrivateKey:
+		curve, err := opensshCurveName(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		ecdh, err := k.PublicKey.ECDH()
+		if err != nil {
+			return nil, err
+		}
+		fields = ssh.Marshal(struct {
+			Curve string
+			Pub   []byte
+			D     *big.Int
+		}{curve, ecdh.Bytes(), k.D})
+	case ed25519.PrivateKey:
+		fields = ssh.Marshal(struct {
+			Pub  []byte
+			Priv []byte
+		}{k.Public().(ed25519.PublicKey), k})
+	default:
+		return nil, fmt.Errorf("unsupported key type %T for OpenSSH", key)
+	}
+
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return nil, err
+	}
+	checkInt := binary.BigEndian.Uint32(check[:])
+	priv := ssh.Marshal(struct {
+		Check1, Check2 uint32
+		KeyType        string
+		Rest           []byte `ssh:"rest"`
+	}{checkInt, checkInt, pub.Type(), fields})
+	priv = append(priv, ssh.Marshal(struct{ Comment string }{comment})...)
+	for i := byte(1); len(priv)%8 != 0; i++ {
+		priv = append(priv, i)
+	}
+
+	body := ssh.Marshal(struct {
+		CipherName, KDFName, KDFOptions string
+		NumKeys                         uint32
+		PubKey, PrivKeys                []byte
+	}{"none", "none", "", 1, pub.Marshal(), priv})
+	return &pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: append([]byte(opensshKeyMagic), body...)}, nil
+}
+
+func opensshCurveName(c elliptic.Curve) (string, error) {
+	switch c {
+	case elliptic.P256():
+		return "nistp256", nil
+	case elliptic.P384():
+		return "nistp384", nil
+	case elliptic.P521():
+		return "nistp521", nil
+	}
+	return "", fmt.Errorf("unsupported curve %s for OpenSSH", c.Params().Name)
+}
+
+// OpenSSHKeyInfo describes an openssh-key-v1 private key. The public half is
+// stored in the clear, so everything but the comment of an encrypted key can
+// be read without its passphrase.
+type OpenSSHKeyInfo struct {
+	Algorithm   string `json:"algorithm"` // e.g. "ssh-ed25519"
+	Bits        int    `json:"bits"`
+	Fingerprint string `json:"fingerprint"`
+	Comment     string `json:"comment,omitempty"`
+	Encrypted   bool   `json:"encrypted"`
+	Cipher      string `json:"cipher,omitempty"`
+	KDF         string `json:"kdf,omitempty"`
+	KDFRounds   uint32 `json:"kdf_rounds,omitempty"`
+
+	publicKey ssh.PublicKey
+}
+
+// opensshKeyEnvelope is the unencrypted header of an openssh-key-v1 blob.
+type opensshKeyEnvelope struct {
+	CipherName, KDFName, KDFOptions string
+	NumKeys                         uint32
+	PubKey, PrivKeys                []byte
+}
+
+// ParseOpenSSHKeyInfo reads the header of an "OPENSSH PRIVATE KEY" block:
+// algorithm, size, fingerprint and encryption, plus the comment when the
+// key is not encrypted.
+func ParseOpenSSHKeyInfo(block *pem.Block) (*OpenSSHKeyInfo, error) {
+	if block.Type != "OPENSSH PRIVATE KEY" || len(block.Bytes) < len(opensshKeyMagic) || string(block.Bytes[:len(opensshKeyMagic)]) != opensshKeyMagic {
+		return nil, fmt.Errorf("not an OpenSSH private key")
+	}
+	var env opensshKeyEnvelope
+	if err := ssh.Unmarshal(block.Bytes[len(opensshKeyMagic):], &env); err != nil {
+		return nil, fmt.Errorf("parse OpenSSH private key: %w", err)
+	}
+	if env.NumKeys != 1 {
+		return nil, fmt.Errorf("OpenSSH key files with %d keys are not supported", env.NumKeys)
+	}
+	pub, err := ssh.ParsePublicKey(env.PubKey)
+	if err != nil {
+		return nil, fmt.Errorf("parse OpenSSH public key: %w", err)
+	}
+	info := &OpenSSHKeyInfo{
+		Algorithm:   pub.Type(),
+		Fingerprint: ssh.FingerprintSHA256(pub),
+		Encrypted:   env.CipherName != "none",
+		publicKey:   pub,
+	}
+	if cpk, ok := pub.(ssh.CryptoPublicKey); ok {
+		info.Bits = publicKeyBits(cpk.CryptoPublicKey())
+	}
+	if info.Encrypted {
+		info.Cipher = env.CipherName
+		info.KDF = env.KDFName
+		var opts struct {
+			Salt   string
+			Rounds uint32
+		}
+		if env.KDFName == "bcrypt" && ssh.Unmarshal([]byte(env.KDFOptions), &opts) == nil {
+			info.KDFRounds = opts.Rounds
+		}
+		return info, nil
+	}
+	info.Comment = opensshKeyComment(env.PrivKeys)
+	return info, nil
+}
+
+// opensshKeyComment returns the comment that follows the key fields in an
+// unencrypted private section, or "" for key types it does not know.
+func opensshKeyComment(priv []byte) string {
+	var head struct {
+		Check1, Check2 uint32
+		KeyType        string
+		Rest           []byte `ssh:"rest"`
+	}
+	if ssh.Unmarshal(priv, &head) != nil {
+		return ""
+	}
+	var comment struct {
+		Comment string
+		Pad     []byte `ssh:"rest"`
+	}
+	var rest []byte
+	switch head.KeyType {
+	case ssh.KeyAlgoRSA:
+		var k struct {
+			N, E, D, Iqmp, P, Q *big.Int
+			Rest                []byte `ssh:"rest"`
+		}
+		if ssh.Unmarshal(head.Rest, &k) != nil {
+			return ""
+		}
+		rest = k.Rest
+	case ssh.KeyAlgoED25519:
+		var k struct {
+			Pub, Priv []byte
+			Rest      []byte `ssh:"rest"`
+		}
+		if ssh.Unmarshal(head.Rest, &k) != nil {
+			return ""
+		}
+		rest = k.Rest
+	case ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521:
+		var k struct {
+			Curve string
+			Pub   []byte
+			D     *big.Int
+			Rest  []byte `ssh:"rest"`
+		}
+		if ssh.Unmarshal(head.Rest, &k) != nil {
+			return ""
+		}
+		rest = k.Rest
+	default:
+		return ""
+	}
+	if ssh.Unmarshal(rest, &comment) != nil {
+		return ""
+	}
+	return comment.Comment
+}
+
+// publicKeyBits returns the key size as ssh-keygen reports it.
+func publicKeyBits(pub crypto.PublicKey) int {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return k.N.BitLen()
+	case *ecdsa.PublicKey:
+		return k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return 256
+	}
+	return 0
+}
+
+// readOpenSSHKeyInfo returns the OpenSSH key in data, or nil when the first
+// private key is in another format.
+func readOpenSSHKeyInfo(data []byte) *OpenSSHKeyInfo {
+	rest := data
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			return nil
+		}
+		rest = r
+		if !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			continue
+		}
+		info, err := ParseOpenSSHKeyInfo(block)
+		if err != nil {
+			return nil
+		}
+		return info
+	}
+}
+
+// openSSHKeySummary fills s from the OpenSSH private key in data.
+func openSSHKeySummary(s *CertSummary, data []byte) {
+	info := readOpenSSHKeyInfo(data)
+	if info == nil {
+		return
+	}
+	s.OpenSSHKey = info
+	s.PublicKeyAlgorithm = info.Algorithm
+	s.PublicKeyComment = info.Comment
+	if cpk, ok := info.publicKey.(ssh.CryptoPublicKey); ok {
+		s.PublicKeyInfo = describePublicKeyValue(cpk.CryptoPublicKey())
+	}
+}
+
+// maxBcryptKDFRounds caps the bcrypt_pbkdf rounds read from a key file, as
+// maxKDFIterations does for PBKDF2: ssh-keygen defaults to 16, and a crafted
+// key must not make decryption hash for hours.
+const maxBcryptKDFRounds = 1000
+
+// parseOpenSSHPrivateKeyBlock decrypts and parses an OpenSSH private key.
+func parseOpenSSHPrivateKeyBlock(block *pem.Block, password string) (crypto.Signer, error) {
+	info, err := ParseOpenSSHKeyInfo(block)
+	if err != nil {
+		return nil, err
+	}
+	var raw any
+	if info.Encrypted {
+		if password == "" {
+			return nil, ErrKeyPasswordRequired
+		}
+		if info.KDFRounds > maxBcryptKDFRounds {
+			return nil, fmt.Errorf("OpenSSH key bcrypt rounds %d exceed the limit of %d", info.KDFRounds, maxBcryptKDFRounds)
+		}
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase(pem.EncodeToMemory(block), []byte(password))
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, ErrKeyIncorrectPassword
+		}
+	} else {
+		raw, err = ssh.ParseRawPrivateKey(pem.EncodeToMemory(block))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse OpenSSH private key: %w", err)
+	}
+	switch k := raw.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case *ed25519.PrivateKey:
+		return *k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	}
+	return nil, fmt.Errorf("unsupported OpenSSH key type %s", info.Algorithm)
+}
+
+// RenderOpenSSHKeyText describes an OpenSSH private key without revealing
+// any private material.
+func RenderOpenSSHKeyText(info *OpenSSHKeyInfo) string {
+	var b strings.Builder
+	b.WriteString("Private key (OpenSSH)\n\n")
+	fmt.Fprintf(&b, "Algorithm: %s\n", info.Algorithm)
+	fmt.Fprintf(&b, "Bits: %d\n", info.Bits)
+	fmt.Fprintf(&b, "Fingerprint: %s\n", info.Fingerprint)
+	if info.Comment != "" {
+		fmt.Fprintf(&b, "Comment: %s\n", info.Comment)
+	}
+	if info.Encrypted {
+		fmt.Fprintf(&b, "Encrypted: yes (%s", info.Cipher)
+		if info.KDF != "" {
+			fmt.Fprintf(&b, ", %s", info.KDF)
+			if info.KDFRounds > 0 {
+				fmt.Fprintf(&b, " %d rounds", info.KDFRounds)
+			}
+		}
+		b.WriteString(")\n")
+	} else {
+		b.WriteString("Encrypted: no\n")
+	}
+	b.WriteString("\nPublic key:\n")
+	b.Write(ssh.MarshalAuthorizedKey(info.publicKey))
+	return b.String()
+}
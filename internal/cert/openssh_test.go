@@ -0,0 +1,171 @@
+package cert
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nickromney/certconv/test/testutil"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestOpenSSHPrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := marshalOpenSSHPrivateKey(key, "bob@host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	p := filepath.Join(dir, "id_rsa")
+	if err := os.WriteFile(p, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ft, err := DetectType(p)
+	if err != nil || ft != FileTypeKey {
+		t.Fatalf("DetectType = %q, %v", ft, err)
+	}
+	data, _ := os.ReadFile(p)
+	if got := DetectTypeFromNameAndBytes("id_rsa", data); got != FileTypeKey {
+		t.Fatalf("DetectTypeFromNameAndBytes = %q", got)
+	}
+
+	// OpenSSH keys never shell out, even on the openssl backend.
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+	s, err := eng.Summary(ctx, p, "")
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	k := s.OpenSSHKey
+	if s.KeyType != KeyTypeOpenSSH || k == nil || k.Algorithm != "ssh-rsa" || k.Bits != 2048 || k.Comment != "bob@host" || k.Encrypted {
+		t.Fatalf("unexpected summary: %+v %+v", s, k)
+	}
+	d, err := eng.Details(ctx, p, "")
+	if err != nil || !strings.Contains(d.RawText, "Encrypted: no") || !strings.Contains(d.RawText, "ssh-rsa AAAA") {
+		t.Fatalf("Details = %v:\n%s", err, d.RawText)
+	}
+
+	// OpenSSH -> PKCS#8 -> OpenSSH keeps the key; only OpenSSH input keeps
+	// its comment.
+	pkcs8 := filepath.Join(dir, "key.pem")
+	if _, err := ConvertKey(p, pkcs8, KeyConvertOptions{To: KeyFormatPKCS8}); err != nil {
+		t.Fatalf("ConvertKey to pkcs8: %v", err)
+	}
+	got, err := ParsePrivateKeyFile(pkcs8, "")
+	if err != nil || !publicKeysEqual(key.Public(), got.Public()) {
+		t.Fatalf("pkcs8 output does not match the input: %v", err)
+	}
+	again := filepath.Join(dir, "id_rsa_copy")
+	if _, err := ConvertKey(p, again, KeyConvertOptions{To: KeyFormatOpenSSH}); err != nil {
+		t.Fatalf("ConvertKey to openssh: %v", err)
+	}
+	data, _ = os.ReadFile(again)
+	if info := readOpenSSHKeyInfo(data); info == nil || info.Comment != "bob@host" {
+		t.Fatalf("comment not kept: %+v", info)
+	}
+
+	sshPub, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := filepath.Join(dir, "id_rsa.pub")
+	if err := os.WriteFile(pub, ssh.MarshalAuthorizedKey(sshPub), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := eng.MatchKeyToCert(ctx, pub, p, "")
+	if err != nil || !m.Match {
+		t.Fatalf("MatchKeyToCert(pub) = %+v, %v", m, err)
+	}
+}
+
+func TestOpenSSHPrivateKey_MatchCertificate(t *testing.T) {
+	chain := testutil.MakeChain(t)
+	p := filepath.Join(chain.Dir, "leaf_openssh")
+	if _, err := ConvertKey(chain.LeafKeyPath, p, KeyConvertOptions{To: KeyFormatOpenSSH}); err != nil {
+		t.Fatalf("ConvertKey: %v", err)
+	}
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+	m, err := eng.MatchKeyToCert(ctx, chain.LeafPath, p, "")
+	if err != nil || !m.Match {
+		t.Fatalf("MatchKeyToCert(leaf) = %+v, %v", m, err)
+	}
+	m, err = eng.MatchKeyToCert(ctx, chain.RootPath, p, "")
+	if err != nil || m.Match {
+		t.Fatalf("MatchKeyToCert(root) = %+v, %v", m, err)
+	}
+}
+
+// The fixture was written by "ssh-keygen -t ed25519 -a 16 -N secret".
+func TestOpenSSHPrivateKey_Encrypted(t *testing.T) {
+	p := testutil.FixturePath(t, "openssh-ed25519-encrypted")
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+	s, err := eng.Summary(ctx, p, "")
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	k := s.OpenSSHKey
+	if k == nil || k.Algorithm != "ssh-ed25519" || k.Bits != 256 || !k.Encrypted || k.Cipher != "aes256-ctr" || k.KDF != "bcrypt" || k.KDFRounds != 16 {
+		t.Fatalf("unexpected summary: %+v", k)
+	}
+
+	if _, err := ParsePrivateKeyFile(p, ""); !errors.Is(err, ErrKeyPasswordRequired) {
+		t.Fatalf("expected ErrKeyPasswordRequired, got %v", err)
+	}
+	if _, err := ParsePrivateKeyFile(p, "wrong"); !errors.Is(err, ErrKeyIncorrectPassword) {
+		t.Fatalf("expected ErrKeyIncorrectPassword, got %v", err)
+	}
+	if _, err := ParsePrivateKeyFile(p, "secret"); err != nil {
+		t.Fatalf("ParsePrivateKeyFile: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "key.pem")
+	if _, err := ConvertKey(p, out, KeyConvertOptions{To: KeyFormatPKCS8}); !errors.Is(err, ErrKeyEncryptedInput) {
+		t.Fatalf("expected ErrKeyEncryptedInput, got %v", err)
+	}
+	if _, err := ConvertKey(p, out, KeyConvertOptions{To: KeyFormatPKCS8, Password: "secret", Decrypt: true}); err != nil {
+		t.Fatalf("ConvertKey: %v", err)
+	}
+
+	// The public key is in the cleartext header, so no password is needed.
+	m, err := eng.MatchKeyToCert(ctx, p+".pub", p, "")
+	if err != nil || !m.Match {
+		t.Fatalf("MatchKeyToCert = %+v, %v", m, err)
+	}
+	data, _ := os.ReadFile(p)
+	records := ScanBytes(p, data, nil, time.Now())
+	if len(records) != 1 || !records[0].Encrypted || records[0].KeyAlgorithm != "Ed25519" || records[0].Error != "" {
+		t.Fatalf("unexpected scan records: %+v", records)
+	}
+
+	// A crafted round count is rejected before any hashing.
+	block, _ := pem.Decode(data)
+	var env opensshKeyEnvelope
+	if err := ssh.Unmarshal(block.Bytes[len(opensshKeyMagic):], &env); err != nil {
+		t.Fatal(err)
+	}
+	var kdf struct {
+		Salt   string
+		Rounds uint32
+	}
+	if err := ssh.Unmarshal([]byte(env.KDFOptions), &kdf); err != nil {
+		t.Fatal(err)
+	}
+	kdf.Rounds = 1 << 30
+	env.KDFOptions = string(ssh.Marshal(&kdf))
+	block.Bytes = append([]byte(opensshKeyMagic), ssh.Marshal(&env)...)
+	if _, err := parseOpenSSHPrivateKeyBlock(block, "secret"); err == nil || !strings.Contains(err.Error(), "rounds") {
+		t.Fatalf("expected a bcrypt rounds error, got %v", err)
+	}
+}
@@ -89,17 +89,24 @@ func (o *OSExecutor) RunWithExtraFiles(ctx context.Context, files []ExtraFile, a
 
 // Engine wraps an Executor and provides all certificate operations.
 type Engine struct {
-	exec Executor
+	exec    Executor
+	backend Backend
 }
 
 // NewEngine creates an Engine with the given Executor.
 func NewEngine(exec Executor) *Engine {
-	return &Engine{exec: exec}
+	return &Engine{exec: exec, backend: BackendOpenSSL}
 }
 
-// NewDefaultEngine creates an Engine with the real OS executor.
+// NewDefaultEngine creates an Engine with the real OS executor. When openssl
+// is not installed, the Engine falls back to the pure-Go backend.
 func NewDefaultEngine() *Engine {
-	return &Engine{exec: &OSExecutor{}}
+	return &Engine{exec: &OSExecutor{}, backend: DefaultBackend()}
+}
+
+// NewGoEngine creates an Engine that never shells out to openssl.
+func NewGoEngine() *Engine {
+	return &Engine{exec: &OSExecutor{}, backend: BackendGo}
 }
 
 func fdArg(extraIndex int) string {
@@ -0,0 +1,492 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// PKCS#12 (RFC 7292) object identifiers.
+var (
+	oidPKCS7EncryptedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+
+	oidPKCS12KeyBag          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidPKCS12ShroudedKeyBag  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidPKCS12CertBag         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidPKCS12CRLBag          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 4}
+	oidPKCS12SecretBag       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 5}
+	oidPKCS12SafeContentsBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 6}
+
+	oidPKCS9X509Certificate = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+
+	oidPKCS9FriendlyName = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidPKCS9LocalKeyID   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+
+	oidPBEWithSHAAnd3KeyTripleDESCBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidPBEWithSHAAnd2KeyTripleDESCBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 4}
+	oidPBEWithSHAAnd128BitRC2CBC     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 5}
+	oidPBEWithSHAAnd40BitRC2CBC      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 6}
+
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+)
+
+type pfxPDU struct {
+	Version  int
+	AuthSafe pkcs7ContentInfo
+	MacData  pfxMacData `asn1:"optional"`
+}
+
+type pfxMacData struct {
+	Mac        pfxDigestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type pfxDigestInfo struct {
+	Algorithm pkixAlgorithm
+	Digest    []byte
+}
+
+type pfxEncryptedData struct {
+	Version              int
+	EncryptedContentInfo pfxEncryptedContentInfo
+}
+
+type pfxEncryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkixAlgorithm
+	EncryptedContent           []byte `asn1:"tag:0,optional"`
+}
+
+type pfxSafeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue  `asn1:"tag:0,explicit"`
+	Attributes []pfxAttribute `asn1:"set,optional"`
+}
+
+type pfxCertBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type pfxAttribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type pfxPBEParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+// pfxFile is a decrypted PKCS#12 file, kept at the level of safes and bags
+// so it can be re-encrypted without losing bag attributes.
+type pfxFile struct {
+	MacAlgorithm  asn1.ObjectIdentifier
+	MacIterations int
+	Safes         []pfxSafe
+}
+
+// pfxSafe is one SafeContents of the authenticated safe. Algorithm is the
+// encryption of an encrypted safe, and is empty for a plain one.
+type pfxSafe struct {
+	Encrypted bool
+	Algorithm pkixAlgorithm
+	Bags      []pfxBag
+}
+
+// pfxBag is one SafeBag. Value is the bag content; for a shrouded key bag
+// it is the decrypted PrivateKeyInfo and Algorithm is how it was encrypted.
+// Attributes are kept exactly as read.
+type pfxBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      []byte
+	Algorithm  pkixAlgorithm
+	Attributes []pfxAttribute
+}
+
+// decodePFX verifies the MAC of a DER PKCS#12 file and decrypts every safe
+// and shrouded key bag. A wrong password is reported as
+// ErrPFXIncorrectPassword.
+func decodePFX(der []byte, password string) (*pfxFile, error) {
+	var pdu pfxPDU
+	if rest, err := asn1.Unmarshal(der, &pdu); err != nil || len(rest) != 0 {
+		return nil, ErrPFXNotPKCS12
+	}
+	if pdu.Version != 3 {
+		return nil, fmt.Errorf("%w: version %d", ErrPFXUnsupportedStructure, pdu.Version)
+	}
+	if !pdu.AuthSafe.ContentType.Equal(oidPKCS7Data) {
+		return nil, fmt.Errorf("%w: only password-integrity PFX files are supported", ErrPFXUnsupportedStructure)
+	}
+	var authSafe []byte
+	if _, err := asn1.Unmarshal(pdu.AuthSafe.Content.Bytes, &authSafe); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPFXNotPKCS12, err.Error())
+	}
+
+	bmpPassword, err := pfxBMPPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	f := &pfxFile{}
+	if len(pdu.MacData.Mac.Algorithm.Algorithm) > 0 {
+		f.MacAlgorithm = pdu.MacData.Mac.Algorithm.Algorithm
+		f.MacIterations = pdu.MacData.Iterations
+		ok, err := pfxVerifyMAC(pdu.MacData, authSafe, bmpPassword)
+		if err != nil {
+			return nil, err
+		}
+		// Some writers derive the MAC for an empty password from no bytes at
+		// all rather than a lone BMP terminator.
+		if !ok && password == "" {
+			if ok, _ = pfxVerifyMAC(pdu.MacData, authSafe, nil); ok {
+				bmpPassword = nil
+			}
+		}
+		if !ok {
+			return nil, ErrPFXIncorrectPassword
+		}
+	}
+
+	var contents []pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(authSafe, &contents); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPFXNotPKCS12, err.Error())
+	}
+	for _, ci := range contents {
+		var safe pfxSafe
+		var data []byte
+		switch {
+		case ci.ContentType.Equal(oidPKCS7Data):
+			if _, err := asn1.Unmarshal(ci.Content.Bytes, &data); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrPFXNotPKCS12, err.Error())
+			}
+		case ci.ContentType.Equal(oidPKCS7EncryptedData):
+			var ed pfxEncryptedData
+			if _, err := asn1.Unmarshal(ci.Content.Bytes, &ed); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrPFXNotPKCS12, err.Error())
+			}
+			safe.Encrypted = true
+			safe.Algorithm = ed.EncryptedContentInfo.ContentEncryptionAlgorithm
+			data, err = pfxDecrypt(safe.Algorithm, ed.EncryptedContentInfo.EncryptedContent, password, bmpPassword)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: content type %s", ErrPFXUnsupportedStructure, ci.ContentType)
+		}
+
+		var bags []pfxSafeBag
+		if _, err := asn1.Unmarshal(data, &bags); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrPFXNotPKCS12, err.Error())
+		}
+		for _, b := range bags {
+			bag := pfxBag{ID: b.ID, Value: b.Value.Bytes, Attributes: b.Attributes}
+			if b.ID.Equal(oidPKCS12ShroudedKeyBag) {
+				var info encryptedPrivateKeyInfo
+				if _, err := asn1.Unmarshal(b.Value.Bytes, &info); err != nil {
+					return nil, fmt.Errorf("%w: %s", ErrPFXNotPKCS12, err.Error())
+				}
+				bag.Algorithm = info.Algorithm
+				bag.Value, err = pfxDecrypt(info.Algorithm, info.EncryptedData, password, bmpPassword)
+				if err != nil {
+					return nil, err
+				}
+			}
+			safe.Bags = append(safe.Bags, bag)
+		}
+		f.Safes = append(f.Safes, safe)
+	}
+	return f, nil
+}
+
+// pfxProfile holds the algorithms a PKCS#12 file is written with.
+type pfxProfile struct {
+	legacy     bool
+	macHash    func() hash.Hash
+	macOID     asn1.ObjectIdentifier
+	iterations int
+}
+
+func pfxProfileFor(p PFXProfile) (pfxProfile, error) {
+	switch p {
+	case PFXProfileModern, "":
+		return pfxProfile{macHash: sha256.New, macOID: oidSHA256, iterations: 2048}, nil
+	case PFXProfileLegacy:
+		return pfxProfile{legacy: true, macHash: sha1.New, macOID: oidSHA1, iterations: 2048}, nil
+	}
+	return pfxProfile{}, fmt.Errorf("unknown PFX profile %q (use modern or legacy)", p)
+}
+
+// encodePFX writes f as DER PKCS#12 under password. The layout of safes and
+// the attributes of every bag are kept; encrypted safes and shrouded keys are
+// re-encrypted with the profile's cipher and the MAC is recomputed.
+func encodePFX(f *pfxFile, password string, profile PFXProfile) ([]byte, error) {
+	prof, err := pfxProfileFor(profile)
+	if err != nil {
+		return nil, err
+	}
+	bmpPassword, err := pfxBMPPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	var contents []pkcs7ContentInfo
+	for _, safe := range f.Safes {
+		var bags []pfxSafeBag
+		for _, b := range safe.Bags {
+			value := b.Value
+			if b.ID.Equal(oidPKCS12ShroudedKeyBag) {
+				alg, encrypted, err := pfxEncrypt(prof, b.Value, password, bmpPassword)
+				if err != nil {
+					return nil, err
+				}
+				if value, err = asn1.Marshal(encryptedPrivateKeyInfo{Algorithm: alg, EncryptedData: encrypted}); err != nil {
+					return nil, err
+				}
+			}
+			bags = append(bags, pfxSafeBag{
+				ID:         b.ID,
+				Value:      asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: value},
+				Attributes: b.Attributes,
+			})
+		}
+		data, err := asn1.Marshal(bags)
+		if err != nil {
+			return nil, err
+		}
+
+		ci := pkcs7ContentInfo{ContentType: oidPKCS7Data}
+		content, err := asn1.Marshal(data)
+		if safe.Encrypted {
+			alg, encrypted, encErr := pfxEncrypt(prof, data, password, bmpPassword)
+			if encErr != nil {
+				return nil, encErr
+			}
+			ci.ContentType = oidPKCS7EncryptedData
+			content, err = asn1.Marshal(pfxEncryptedData{
+				EncryptedContentInfo: pfxEncryptedContentInfo{
+					ContentType:                oidPKCS7Data,
+					ContentEncryptionAlgorithm: alg,
+					EncryptedContent:           encrypted,
+				},
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		ci.Content = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content}
+		contents = append(contents, ci)
+	}
+
+	authSafe, err := asn1.Marshal(contents)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	macKey := pkcs12KDF(prof.macHash, 3, bmpPassword, salt, prof.iterations, prof.macHash().Size())
+	mac := hmac.New(prof.macHash, macKey)
+	mac.Write(authSafe)
+
+	authSafeOctets, err := asn1.Marshal(authSafe)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pfxPDU{
+		Version: 3,
+		AuthSafe: pkcs7ContentInfo{
+			ContentType: oidPKCS7Data,
+			Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: authSafeOctets},
+		},
+		MacData: pfxMacData{
+			Mac:        pfxDigestInfo{Algorithm: pkixAlgorithm{Algorithm: prof.macOID, Parameters: asn1.NullRawValue}, Digest: mac.Sum(nil)},
+			MacSalt:    salt,
+			Iterations: prof.iterations,
+		},
+	})
+}
+
+func pfxVerifyMAC(md pfxMacData, authSafe, bmpPassword []byte) (bool, error) {
+	h, err := pfxMACHash(md.Mac.Algorithm.Algorithm)
+	if err != nil {
+		return false, err
+	}
+	if err := checkKDFIterations(md.Iterations); err != nil {
+		return false, err
+	}
+	key := pkcs12KDF(h, 3, bmpPassword, md.MacSalt, md.Iterations, h().Size())
+	mac := hmac.New(h, key)
+	mac.Write(authSafe)
+	return hmac.Equal(mac.Sum(nil), md.Mac.Digest), nil
+}
+
+func pfxMACHash(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case oid.Equal(oidSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidSHA512):
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("%w: MAC algorithm %s", ErrPFXUnsupportedStructure, oid)
+}
+
+// pfxDecrypt decrypts a safe or shrouded key. PBES2 takes the password as
+// UTF-8; the PKCS#12 PBE schemes take it as a terminated BMPString.
+func pfxDecrypt(alg pkixAlgorithm, data []byte, password string, bmpPassword []byte) ([]byte, error) {
+	if alg.Algorithm.Equal(oidPBES2) {
+		plain, err := pbes2Decrypt(alg.Parameters.FullBytes, data, []byte(password))
+		if errors.Is(err, ErrKeyIncorrectPassword) {
+			return nil, ErrPFXIncorrectPassword
+		}
+		return plain, err
+	}
+
+	var keyLen int
+	var newBlock func([]byte) (cipher.Block, error)
+	switch {
+	case alg.Algorithm.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC):
+		keyLen, newBlock = 24, des.NewTripleDESCipher
+	case alg.Algorithm.Equal(oidPBEWithSHAAnd2KeyTripleDESCBC):
+		keyLen = 16
+		newBlock = func(k []byte) (cipher.Block, error) {
+			return des.NewTripleDESCipher(append(append([]byte{}, k...), k[:8]...))
+		}
+	case alg.Algorithm.Equal(oidPBEWithSHAAnd128BitRC2CBC):
+		keyLen = 16
+		newBlock = func(k []byte) (cipher.Block, error) { return newRC2Cipher(k, 128) }
+	case alg.Algorithm.Equal(oidPBEWithSHAAnd40BitRC2CBC):
+		keyLen = 5
+		newBlock = func(k []byte) (cipher.Block, error) { return newRC2Cipher(k, 40) }
+	default:
+		return nil, fmt.Errorf("%w: encryption algorithm %s", ErrPFXUnsupportedStructure, alg.Algorithm)
+	}
+
+	var params pfxPBEParams
+	if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("parse PBE parameters: %w", err)
+	}
+	if err := checkKDFIterations(params.Iterations); err != nil {
+		return nil, err
+	}
+	key := pkcs12KDF(sha1.New, 1, bmpPassword, params.Salt, params.Iterations, keyLen)
+	iv := pkcs12KDF(sha1.New, 2, bmpPassword, params.Salt, params.Iterations, 8)
+	block, err := newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, errors.New("malformed encrypted data")
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+	if plain, err = pkcs7Unpad(plain, block.BlockSize()); err != nil {
+		return nil, ErrPFXIncorrectPassword
+	}
+	return plain, nil
+}
+
+// pfxEncrypt encrypts a safe or shrouded key with the profile's cipher:
+// PBES2 (PBKDF2-HMAC-SHA256, AES-256-CBC) or pbeWithSHAAnd3-KeyTripleDES-CBC.
+func pfxEncrypt(prof pfxProfile, data []byte, password string, bmpPassword []byte) (pkixAlgorithm, []byte, error) {
+	if !prof.legacy {
+		return pbes2Encrypt(data, []byte(password), KeyKDFPBKDF2, prof.iterations)
+	}
+
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return pkixAlgorithm{}, nil, err
+	}
+	params, err := asn1.Marshal(pfxPBEParams{Salt: salt, Iterations: prof.iterations})
+	if err != nil {
+		return pkixAlgorithm{}, nil, err
+	}
+	key := pkcs12KDF(sha1.New, 1, bmpPassword, salt, prof.iterations, 24)
+	iv := pkcs12KDF(sha1.New, 2, bmpPassword, salt, prof.iterations, 8)
+	block, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		return pkixAlgorithm{}, nil, err
+	}
+	padded := pkcs7Pad(data, block.BlockSize())
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+	return pkixAlgorithm{Algorithm: oidPBEWithSHAAnd3KeyTripleDESCBC, Parameters: asn1.RawValue{FullBytes: params}}, padded, nil
+}
+
+// pfxBMPPassword encodes password as a zero-terminated big-endian BMPString,
+// the form the PKCS#12 key derivation takes.
+func pfxBMPPassword(password string) ([]byte, error) {
+	out := make([]byte, 0, 2*len(password)+2)
+	for _, r := range password {
+		if r > 0xFFFF {
+			return nil, errors.New("PFX passwords must use characters from the Basic Multilingual Plane")
+		}
+		out = append(out, byte(r>>8), byte(r))
+	}
+	return append(out, 0, 0), nil
+}
+
+// pkcs12KDF is the PKCS#12 key derivation of RFC 7292 appendix B.2. id
+// selects the purpose: 1 for keys, 2 for IVs, 3 for MAC keys.
+func pkcs12KDF(h func() hash.Hash, id byte, password, salt []byte, iterations, size int) []byte {
+	v := h().BlockSize()
+	d := bytes.Repeat([]byte{id}, v)
+	i := append(pkcs12Fill(salt, v), pkcs12Fill(password, v)...)
+
+	var out []byte
+	for len(out) < size {
+		a := h()
+		a.Write(d)
+		a.Write(i)
+		ai := a.Sum(nil)
+		for n := 1; n < iterations; n++ {
+			a = h()
+			a.Write(ai)
+			ai = a.Sum(nil)
+		}
+		out = append(out, ai...)
+		if len(out) >= size {
+			break
+		}
+
+		// I_j = (I_j + B + 1) mod 2^(8v) for every v-byte block of I.
+		b := pkcs12Fill(ai, v)
+		for j := 0; j < len(i); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(i[j+k]) + int(b[k]) + carry
+				i[j+k] = byte(sum)
+				carry = sum >> 8
+			}
+		}
+	}
+	return out[:size]
+}
+
+// pkcs12Fill repeats b to the smallest multiple of v bytes that holds it.
+func pkcs12Fill(b []byte, v int) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	out := make([]byte, v*((len(b)+v-1)/v))
+	for i := range out {
+		out[i] = b[i%len(b)]
+	}
+	return out
+}
@@ -0,0 +1,427 @@
+package cert
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PFXInspection describes the structure of a PKCS#12 file: how it is
+// protected, what each SafeBag holds and which keys belong to which
+// certificates.
+type PFXInspection struct {
+	MAC           *PFXMACInfo      `json:"mac,omitempty"`
+	Safes         []PFXSafeInfo    `json:"safes"`
+	Bags          []PFXBagInfo     `json:"bags"`
+	Pairs         []PFXKeyPair     `json:"pairs,omitempty"`
+	Compatibility PFXCompatibility `json:"compatibility"`
+}
+
+// PFXMACInfo is the integrity MAC of a PKCS#12 file.
+type PFXMACInfo struct {
+	Algorithm  string `json:"algorithm"`
+	Iterations int    `json:"iterations"`
+}
+
+// PFXSafeInfo is one SafeContents of the authenticated safe.
+type PFXSafeInfo struct {
+	Encrypted  bool           `json:"encrypted"`
+	Encryption *PFXEncryption `json:"encryption,omitempty"`
+	Bags       int            `json:"bags"`
+}
+
+// PFXEncryption describes a password-based encryption scheme and its
+// parameters. Iterations is 0 for scrypt, whose cost is part of KDF.
+type PFXEncryption struct {
+	Scheme     string `json:"scheme"`
+	Cipher     string `json:"cipher"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations,omitempty"`
+	SaltBytes  int    `json:"salt_bytes"`
+}
+
+// PFXBagInfo is one SafeBag. Index is 1-based across the whole file and
+// Safe is the 1-based safe it was read from.
+type PFXBagInfo struct {
+	Index        int            `json:"index"`
+	Safe         int            `json:"safe"`
+	Type         string         `json:"type"`
+	Encryption   *PFXEncryption `json:"encryption,omitempty"`
+	FriendlyName string         `json:"friendly_name,omitempty"`
+	LocalKeyID   string         `json:"local_key_id,omitempty"`
+	Subject      string         `json:"subject,omitempty"`
+	Issuer       string         `json:"issuer,omitempty"`
+	NotAfter     string         `json:"not_after,omitempty"`
+	KeyType      string         `json:"key_type,omitempty"`
+}
+
+// PFXKeyPair links a key bag to a certificate bag. A pair is reported when
+// either the public keys or the local key IDs agree; a local key ID match
+// without a public key match means the file is inconsistent.
+type PFXKeyPair struct {
+	Key             int  `json:"key"`
+	Certificate     int  `json:"certificate"`
+	PublicKeyMatch  bool `json:"public_key_match"`
+	LocalKeyIDMatch bool `json:"local_key_id_match"`
+}
+
+// PFXCompatibility reports whether the file's algorithms suit importers
+// that only understand the older PKCS#12 ciphers.
+type PFXCompatibility struct {
+	// LegacyWindows is true when every cipher is 3DES or RC2 and the MAC is
+	// SHA-1, as Windows before 10 1709 / Server 2019 requires.
+	LegacyWindows bool `json:"legacy_windows"`
+	// AzureAppService is true when every cipher is 3DES and the MAC is
+	// SHA-1, as Azure App Service certificate uploads require.
+	AzureAppService bool     `json:"azure_app_service"`
+	Reasons         []string `json:"reasons,omitempty"`
+}
+
+// InspectPFX decrypts the PKCS#12 file at path and describes its MAC, safes,
+// bags, bag attributes and key/certificate pairing.
+func InspectPFX(path, password string) (*PFXInspection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := decodePFX(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &PFXInspection{}
+	if len(f.MacAlgorithm) > 0 {
+		out.MAC = &PFXMACInfo{
+			Algorithm:  "HMAC-" + pfxHashName(f.MacAlgorithm),
+			Iterations: f.MacIterations,
+		}
+	}
+
+	type keyEntry struct {
+		index int
+		pub   crypto.PublicKey
+		id    []byte
+	}
+	type certEntry struct {
+		index int
+		cert  *x509.Certificate
+		id    []byte
+	}
+	var keys []keyEntry
+	var certs []certEntry
+	for i, safe := range f.Safes {
+		si := PFXSafeInfo{Encrypted: safe.Encrypted, Bags: len(safe.Bags)}
+		if safe.Encrypted {
+			si.Encryption = describePFXEncryption(safe.Algorithm)
+		}
+		out.Safes = append(out.Safes, si)
+
+		for _, bag := range safe.Bags {
+			bi := PFXBagInfo{
+				Index:        len(out.Bags) + 1,
+				Safe:         i + 1,
+				Type:         pfxBagTypeName(bag.ID),
+				FriendlyName: bag.friendlyName(),
+			}
+			id := bag.localKeyID()
+			if len(id) > 0 {
+				bi.LocalKeyID = formatFingerprint(hex.EncodeToString(id))
+			}
+			switch {
+			case bag.ID.Equal(oidPKCS12KeyBag), bag.ID.Equal(oidPKCS12ShroudedKeyBag):
+				if bag.ID.Equal(oidPKCS12ShroudedKeyBag) {
+					bi.Encryption = describePFXEncryption(bag.Algorithm)
+				}
+				key, err := x509.ParsePKCS8PrivateKey(bag.Value)
+				if signer, ok := key.(crypto.Signer); err == nil && ok {
+					bi.KeyType = describePublicKeyValue(signer.Public())
+					keys = append(keys, keyEntry{index: bi.Index, pub: signer.Public(), id: id})
+				} else {
+					bi.KeyType = "unreadable"
+				}
+			case bag.ID.Equal(oidPKCS12CertBag):
+				var cb pfxCertBag
+				if _, err := asn1.Unmarshal(bag.Value, &cb); err != nil || !cb.ID.Equal(oidPKCS9X509Certificate) {
+					break
+				}
+				c, err := x509.ParseCertificate(cb.Data)
+				if err != nil {
+					break
+				}
+				bi.Subject = opensslName(c.Subject)
+				bi.Issuer = opensslName(c.Issuer)
+				bi.NotAfter = c.NotAfter.UTC().Format("2006-01-02T15:04:05Z")
+				certs = append(certs, certEntry{index: bi.Index, cert: c, id: id})
+			}
+			out.Bags = append(out.Bags, bi)
+		}
+	}
+
+	for _, k := range keys {
+		for _, c := range certs {
+			pair := PFXKeyPair{
+				Key:             k.index,
+				Certificate:     c.index,
+				PublicKeyMatch:  publicKeysEqual(k.pub, c.cert.PublicKey),
+				LocalKeyIDMatch: len(k.id) > 0 && bytes.Equal(k.id, c.id),
+			}
+			if pair.PublicKeyMatch || pair.LocalKeyIDMatch {
+				out.Pairs = append(out.Pairs, pair)
+			}
+		}
+	}
+
+	out.Compatibility = pfxCompatibility(out)
+	return out, nil
+}
+
+func pfxCompatibility(in *PFXInspection) PFXCompatibility {
+	c := PFXCompatibility{LegacyWindows: true, AzureAppService: true}
+	if in.MAC != nil && in.MAC.Algorithm != "HMAC-SHA-1" {
+		c.LegacyWindows, c.AzureAppService = false, false
+		c.Reasons = append(c.Reasons, in.MAC.Algorithm+" MAC")
+	}
+	seen := map[string]bool{}
+	check := func(e *PFXEncryption) {
+		if e == nil || seen[e.Cipher] {
+			return
+		}
+		seen[e.Cipher] = true
+		if e.Scheme != "PKCS#12 PBE" {
+			c.LegacyWindows, c.AzureAppService = false, false
+			c.Reasons = append(c.Reasons, e.Cipher+" via PBES2")
+		} else if e.Cipher != "3DES-CBC" {
+			c.AzureAppService = false
+			c.Reasons = append(c.Reasons, e.Cipher)
+		}
+	}
+	for _, s := range in.Safes {
+		check(s.Encryption)
+	}
+	for _, b := range in.Bags {
+		check(b.Encryption)
+	}
+	return c
+}
+
+func describePFXEncryption(alg pkixAlgorithm) *PFXEncryption {
+	if alg.Algorithm.Equal(oidPBES2) {
+		e := &PFXEncryption{Scheme: "PBES2", Cipher: alg.Algorithm.String()}
+		var params pbes2Params
+		if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &params); err != nil {
+			return e
+		}
+		e.Cipher = pbes2CipherName(params.EncryptionScheme.Algorithm)
+		switch kdf := params.KeyDerivationFunc; {
+		case kdf.Algorithm.Equal(oidPBKDF2):
+			var p pbkdf2Params
+			if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &p); err == nil {
+				prf := p.PRF.Algorithm
+				if len(prf) == 0 {
+					prf = oidHMACWithSHA1
+				}
+				e.KDF = "PBKDF2-" + pbkdf2PRFName(prf)
+				e.Iterations = p.Iterations
+				e.SaltBytes = len(p.Salt)
+			}
+		case kdf.Algorithm.Equal(oidScrypt):
+			var p scryptParams
+			if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &p); err == nil {
+				e.KDF = fmt.Sprintf("scrypt (N=%d, r=%d, p=%d)", p.CostParameter, p.BlockSize, p.Parallelization)
+				e.SaltBytes = len(p.Salt)
+			}
+		default:
+			e.KDF = kdf.Algorithm.String()
+		}
+		return e
+	}
+
+	e := &PFXEncryption{Scheme: "PKCS#12 PBE", KDF: "PKCS#12 SHA-1"}
+	switch {
+	case alg.Algorithm.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC):
+		e.Cipher = "3DES-CBC"
+	case alg.Algorithm.Equal(oidPBEWithSHAAnd2KeyTripleDESCBC):
+		e.Cipher = "2-key 3DES-CBC"
+	case alg.Algorithm.Equal(oidPBEWithSHAAnd128BitRC2CBC):
+		e.Cipher = "RC2-128-CBC"
+	case alg.Algorithm.Equal(oidPBEWithSHAAnd40BitRC2CBC):
+		e.Cipher = "RC2-40-CBC"
+	default:
+		return &PFXEncryption{Scheme: alg.Algorithm.String(), Cipher: alg.Algorithm.String()}
+	}
+	var params pfxPBEParams
+	if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &params); err == nil {
+		e.Iterations = params.Iterations
+		e.SaltBytes = len(params.Salt)
+	}
+	return e
+}
+
+func pfxBagTypeName(oid asn1.ObjectIdentifier) string {
+	switch {
+	case oid.Equal(oidPKCS12KeyBag):
+		return "keyBag"
+	case oid.Equal(oidPKCS12ShroudedKeyBag):
+		return "pkcs8ShroudedKeyBag"
+	case oid.Equal(oidPKCS12CertBag):
+		return "certBag"
+	case oid.Equal(oidPKCS12CRLBag):
+		return "crlBag"
+	case oid.Equal(oidPKCS12SecretBag):
+		return "secretBag"
+	case oid.Equal(oidPKCS12SafeContentsBag):
+		return "safeContentsBag"
+	}
+	return oid.String()
+}
+
+func pfxHashName(oid asn1.ObjectIdentifier) string {
+	switch {
+	case oid.Equal(oidSHA1):
+		return "SHA-1"
+	case oid.Equal(oidSHA256):
+		return "SHA-256"
+	case oid.Equal(oidSHA384):
+		return "SHA-384"
+	case oid.Equal(oidSHA512):
+		return "SHA-512"
+	}
+	return oid.String()
+}
+
+func pbkdf2PRFName(oid asn1.ObjectIdentifier) string {
+	switch {
+	case oid.Equal(oidHMACWithSHA1):
+		return "HMAC-SHA-1"
+	case oid.Equal(oidHMACWithSHA256):
+		return "HMAC-SHA-256"
+	case oid.Equal(oidHMACWithSHA384):
+		return "HMAC-SHA-384"
+	case oid.Equal(oidHMACWithSHA512):
+		return "HMAC-SHA-512"
+	}
+	return oid.String()
+}
+
+func pbes2CipherName(oid asn1.ObjectIdentifier) string {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return "AES-128-CBC"
+	case oid.Equal(oidAES192CBC):
+		return "AES-192-CBC"
+	case oid.Equal(oidAES256CBC):
+		return "AES-256-CBC"
+	case oid.Equal(oidDESEDE3CBC):
+		return "3DES-CBC"
+	}
+	return oid.String()
+}
+
+// RenderPFXInspectionText renders a PFXInspection for terminals and the TUI.
+func RenderPFXInspectionText(in *PFXInspection) string {
+	var b strings.Builder
+	if in.MAC != nil {
+		fmt.Fprintf(&b, "MAC: %s, %d iteration(s)\n", in.MAC.Algorithm, in.MAC.Iterations)
+	} else {
+		b.WriteString("MAC: none\n")
+	}
+
+	for i, safe := range in.Safes {
+		b.WriteString("\n")
+		if safe.Encrypted {
+			fmt.Fprintf(&b, "Safe %d: encrypted, %s\n", i+1, formatPFXEncryption(safe.Encryption))
+		} else {
+			fmt.Fprintf(&b, "Safe %d: not encrypted\n", i+1)
+		}
+		for _, bag := range in.Bags {
+			if bag.Safe != i+1 {
+				continue
+			}
+			fmt.Fprintf(&b, "  Bag %d: %s\n", bag.Index, bag.Type)
+			kv := func(k, v string) {
+				if v != "" {
+					fmt.Fprintf(&b, "    %-15s %s\n", k+":", v)
+				}
+			}
+			if bag.Encryption != nil {
+				kv("Encryption", formatPFXEncryption(bag.Encryption))
+			}
+			kv("Key", bag.KeyType)
+			kv("Subject", bag.Subject)
+			kv("Issuer", bag.Issuer)
+			kv("Not after", bag.NotAfter)
+			kv("Friendly name", bag.FriendlyName)
+			kv("Local key ID", bag.LocalKeyID)
+		}
+	}
+
+	b.WriteString("\nPairing:\n")
+	paired := map[int]bool{}
+	for _, p := range in.Pairs {
+		paired[p.Key] = true
+		switch {
+		case p.PublicKeyMatch && p.LocalKeyIDMatch:
+			fmt.Fprintf(&b, "  Bag %d (key) -> Bag %d (certificate): public key and local key ID match\n", p.Key, p.Certificate)
+		case p.PublicKeyMatch:
+			fmt.Fprintf(&b, "  Bag %d (key) -> Bag %d (certificate): public key matches, local key ID does not\n", p.Key, p.Certificate)
+		default:
+			fmt.Fprintf(&b, "  Bag %d (key) -> Bag %d (certificate): local key ID matches but the public key does NOT\n", p.Key, p.Certificate)
+		}
+	}
+	for _, bag := range in.Bags {
+		if bag.KeyType != "" && !paired[bag.Index] {
+			fmt.Fprintf(&b, "  Bag %d (key): no matching certificate\n", bag.Index)
+		}
+	}
+	if len(paired) == 0 && !hasPFXKeyBag(in) {
+		b.WriteString("  no private keys\n")
+	}
+
+	yesNo := func(ok bool) string {
+		if ok {
+			return "yes"
+		}
+		return "no"
+	}
+	b.WriteString("\nCompatibility:\n")
+	fmt.Fprintf(&b, "  Older Windows (before 10 1709 / Server 2019): %s\n", yesNo(in.Compatibility.LegacyWindows))
+	fmt.Fprintf(&b, "  Azure App Service upload:                     %s\n", yesNo(in.Compatibility.AzureAppService))
+	if len(in.Compatibility.Reasons) > 0 {
+		fmt.Fprintf(&b, "  Uses: %s\n", strings.Join(in.Compatibility.Reasons, ", "))
+		if !in.Compatibility.AzureAppService {
+			b.WriteString("  Re-encrypt with: certconv pfx rewrap IN OUT --profile legacy\n")
+		}
+	}
+	return b.String()
+}
+
+func hasPFXKeyBag(in *PFXInspection) bool {
+	for _, bag := range in.Bags {
+		if bag.KeyType != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func formatPFXEncryption(e *PFXEncryption) string {
+	if e == nil {
+		return "unknown"
+	}
+	parts := []string{e.Scheme, e.Cipher}
+	if e.KDF != "" {
+		parts = append(parts, e.KDF)
+	}
+	if e.Iterations > 0 {
+		parts = append(parts, fmt.Sprintf("%d iteration(s)", e.Iterations))
+	}
+	if e.SaltBytes > 0 {
+		parts = append(parts, fmt.Sprintf("%d-byte salt", e.SaltBytes))
+	}
+	return strings.Join(parts, ", ")
+}
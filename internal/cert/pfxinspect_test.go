@@ -0,0 +1,83 @@
+package cert
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickromney/certconv/test/testutil"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+func TestInspectPFX(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	certPEM, _ := os.ReadFile(pair.CertPath)
+	block, _ := pem.Decode(certPEM)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePrivateKeyFile(pair.KeyPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	modern := testutil.MakePFX(t, pair, "pw")
+	in, err := InspectPFX(modern, "pw")
+	if err != nil {
+		t.Fatalf("InspectPFX: %v", err)
+	}
+	if in.MAC == nil || in.MAC.Algorithm != "HMAC-SHA-256" || in.MAC.Iterations == 0 {
+		t.Fatalf("unexpected MAC: %+v", in.MAC)
+	}
+	if len(in.Bags) != 2 || len(in.Pairs) != 1 {
+		t.Fatalf("unexpected bags/pairs: %+v %+v", in.Bags, in.Pairs)
+	}
+	var keyBag PFXBagInfo
+	for _, b := range in.Bags {
+		if b.Type == "pkcs8ShroudedKeyBag" {
+			keyBag = b
+		}
+	}
+	if keyBag.Encryption == nil || keyBag.Encryption.Cipher != "AES-256-CBC" || !strings.HasPrefix(keyBag.Encryption.KDF, "PBKDF2-") {
+		t.Fatalf("unexpected key encryption: %+v", keyBag.Encryption)
+	}
+	p := in.Pairs[0]
+	if p.Key != keyBag.Index || !p.PublicKeyMatch || !p.LocalKeyIDMatch {
+		t.Fatalf("unexpected pair: %+v", p)
+	}
+	if in.Compatibility.LegacyWindows || in.Compatibility.AzureAppService {
+		t.Fatalf("modern PFX reported as legacy-compatible: %+v", in.Compatibility)
+	}
+
+	legacyData, err := pkcs12.LegacyDES.Encode(key, leaf, nil, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := filepath.Join(dir, "legacy.pfx")
+	if err := os.WriteFile(legacy, legacyData, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	in, err = InspectPFX(legacy, "pw")
+	if err != nil {
+		t.Fatalf("InspectPFX legacy: %v", err)
+	}
+	if in.MAC.Algorithm != "HMAC-SHA-1" || !in.Compatibility.LegacyWindows || !in.Compatibility.AzureAppService {
+		t.Fatalf("3DES PFX should be legacy-compatible: %+v %+v", in.MAC, in.Compatibility)
+	}
+	text := RenderPFXInspectionText(in)
+	for _, want := range []string{"MAC: HMAC-SHA-1", "Safe 1: encrypted, PKCS#12 PBE, 3DES-CBC", "public key and local key ID match"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	if _, err := InspectPFX(legacy, "wrong"); !errors.Is(err, ErrPFXIncorrectPassword) {
+		t.Fatalf("expected ErrPFXIncorrectPassword, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package cert
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"os"
+	"unicode/utf16"
+)
+
+// PFXProfile names the cipher suite a PKCS#12 file is written with.
+type PFXProfile string
+
+const (
+	// PFXProfileModern is AES-256-CBC with PBKDF2-HMAC-SHA256 and a SHA-256
+	// MAC, OpenSSL 3's default.
+	PFXProfileModern PFXProfile = "modern"
+	// PFXProfileLegacy is pbeWithSHAAnd3-KeyTripleDES-CBC with a SHA-1 MAC,
+	// for older Windows and Java releases.
+	PFXProfileLegacy PFXProfile = "legacy"
+)
+
+// PFXRewrapResult reports what RewrapPFX wrote.
+type PFXRewrapResult struct {
+	Input         string     `json:"input"`
+	Output        string     `json:"output"`
+	Profile       PFXProfile `json:"profile"`
+	Certificates  int        `json:"certificates"`
+	Keys          int        `json:"keys"`
+	FriendlyNames []string   `json:"friendly_names,omitempty"` // distinct, in bag order
+}
+
+// RewrapPFX re-encrypts the PKCS#12 file at inputPath under newPassword with
+// the given cipher profile and writes it to outputPath (0600, never
+// overwriting). Bags, their order and their attributes, including friendly
+// names and local key IDs, are carried over unchanged.
+func RewrapPFX(inputPath, outputPath, password, newPassword string, profile PFXProfile) (*PFXRewrapResult, error) {
+	if profile == "" {
+		profile = PFXProfileModern
+	}
+	if _, err := pfxProfileFor(profile); err != nil {
+		return nil, err
+	}
+	if err := ensureNotExists(outputPath); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := decodePFX(data, password)
+	if err != nil {
+		return nil, err
+	}
+	out, err := encodePFX(f, newPassword, profile)
+	if err != nil {
+		return nil, err
+	}
+	// Decode what was written so a broken file is never left behind.
+	if _, err := decodePFX(out, newPassword); err != nil {
+		return nil, fmt.Errorf("verify re-wrapped PFX: %w", err)
+	}
+	if err := writeFileExclusive(outputPath, out, 0o600); err != nil {
+		return nil, err
+	}
+
+	result := &PFXRewrapResult{Input: inputPath, Output: outputPath, Profile: profile}
+	seen := map[string]bool{}
+	for _, safe := range f.Safes {
+		for _, bag := range safe.Bags {
+			switch {
+			case bag.ID.Equal(oidPKCS12CertBag):
+				result.Certificates++
+			case bag.ID.Equal(oidPKCS12KeyBag), bag.ID.Equal(oidPKCS12ShroudedKeyBag):
+				result.Keys++
+			}
+			if name := bag.friendlyName(); name != "" && !seen[name] {
+				seen[name] = true
+				result.FriendlyNames = append(result.FriendlyNames, name)
+			}
+		}
+	}
+	return result, nil
+}
+
+// friendlyName returns the bag's PKCS#9 friendlyName, or "".
+func (b pfxBag) friendlyName() string {
+	for _, attr := range b.Attributes {
+		if !attr.ID.Equal(oidPKCS9FriendlyName) {
+			continue
+		}
+		var v asn1.RawValue
+		if _, err := asn1.Unmarshal(attr.Value.Bytes, &v); err != nil || v.Tag != asn1.TagBMPString || len(v.Bytes)%2 != 0 {
+			return ""
+		}
+		u := make([]uint16, len(v.Bytes)/2)
+		for i := range u {
+			u[i] = uint16(v.Bytes[2*i])<<8 | uint16(v.Bytes[2*i+1])
+		}
+		return string(utf16.Decode(u))
+	}
+	return ""
+}
+
+// localKeyID returns the bag's PKCS#9 localKeyID, or nil.
+func (b pfxBag) localKeyID() []byte {
+	for _, attr := range b.Attributes {
+		if !attr.ID.Equal(oidPKCS9LocalKeyID) {
+			continue
+		}
+		var id []byte
+		if _, err := asn1.Unmarshal(attr.Value.Bytes, &id); err != nil {
+			return nil
+		}
+		return id
+	}
+	return nil
+}
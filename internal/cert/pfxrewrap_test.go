@@ -0,0 +1,136 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nickromney/certconv/test/testutil"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+func TestRC2_RFC2268Vectors(t *testing.T) {
+	tests := []struct {
+		key, plain, cipher string
+		bits               int
+	}{
+		{"0000000000000000", "0000000000000000", "ebb773f993278eff", 63},
+		{"ffffffffffffffff", "ffffffffffffffff", "278b27e42e2f0d49", 64},
+		{"3000000000000000", "1000000000000001", "30649edf9be7d2c2", 64},
+		{"88bca90e90875a7f0f79c384627bafb2", "0000000000000000", "2269552ab0f85ca6", 128},
+	}
+	for _, tt := range tests {
+		key, _ := hex.DecodeString(tt.key)
+		plain, _ := hex.DecodeString(tt.plain)
+		want, _ := hex.DecodeString(tt.cipher)
+		c, err := newRC2Cipher(key, tt.bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, 8)
+		c.Encrypt(got, plain)
+		if !bytes.Equal(got, want) {
+			t.Errorf("key %s: encrypt = %x, want %x", tt.key, got, want)
+		}
+		c.Decrypt(got, want)
+		if !bytes.Equal(got, plain) {
+			t.Errorf("key %s: decrypt = %x, want %x", tt.key, got, plain)
+		}
+	}
+}
+
+func TestRewrapPFX_KeepsBagAttributes(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	certPEM, _ := os.ReadFile(pair.CertPath)
+	block, _ := pem.Decode(certPEM)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePrivateKeyFile(pair.KeyPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// RC2-40 certificates and 3DES keys, as old Windows exports use.
+	legacy, err := pkcs12.LegacyRC2.Encode(key, leaf, nil, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := decodePFX(legacy, "old")
+	if err != nil {
+		t.Fatalf("decode RC2 PFX: %v", err)
+	}
+	name, _ := asn1.Marshal(asn1.RawValue{Tag: asn1.TagBMPString, Bytes: []byte{0, 'w', 0, 'e', 0, 'b'}})
+	for i := range f.Safes {
+		for j := range f.Safes[i].Bags {
+			f.Safes[i].Bags[j].Attributes = append(f.Safes[i].Bags[j].Attributes, pfxAttribute{
+				ID:    oidPKCS9FriendlyName,
+				Value: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: name},
+			})
+		}
+	}
+	named, err := encodePFX(f, "old", PFXProfileLegacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	in := filepath.Join(dir, "legacy.pfx")
+	if err := os.WriteFile(in, named, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "modern.pfx")
+	r, err := RewrapPFX(in, out, "old", "new", PFXProfileModern)
+	if err != nil {
+		t.Fatalf("RewrapPFX: %v", err)
+	}
+	if r.Certificates != 1 || r.Keys != 1 || len(r.FriendlyNames) != 1 || r.FriendlyNames[0] != "web" {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+	if info, err := os.Stat(out); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("output mode: %v %v", info, err)
+	}
+
+	data, _ := os.ReadFile(out)
+	if _, _, _, err := pkcs12.DecodeChain(data, "new"); err != nil {
+		t.Fatalf("go-pkcs12 cannot read the re-wrapped file: %v", err)
+	}
+	rewrapped, err := decodePFX(data, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rewrapped.MacAlgorithm.Equal(oidSHA256) {
+		t.Fatalf("MAC algorithm = %s", rewrapped.MacAlgorithm)
+	}
+	wantID := f.Safes[0].Bags[0].localKeyID()
+	for _, safe := range rewrapped.Safes {
+		if safe.Encrypted && !safe.Algorithm.Algorithm.Equal(oidPBES2) {
+			t.Fatalf("safe still encrypted with %s", safe.Algorithm.Algorithm)
+		}
+		for _, bag := range safe.Bags {
+			if bag.friendlyName() != "web" || !bytes.Equal(bag.localKeyID(), wantID) || len(wantID) == 0 {
+				t.Fatalf("bag %s lost attributes: %q %x", bag.ID, bag.friendlyName(), bag.localKeyID())
+			}
+			if bag.ID.Equal(oidPKCS12ShroudedKeyBag) && !bag.Algorithm.Algorithm.Equal(oidPBES2) {
+				t.Fatalf("key still encrypted with %s", bag.Algorithm.Algorithm)
+			}
+		}
+	}
+
+	if _, err := RewrapPFX(in, filepath.Join(dir, "x.pfx"), "wrong", "new", PFXProfileModern); !errors.Is(err, ErrPFXIncorrectPassword) {
+		t.Fatalf("expected ErrPFXIncorrectPassword, got %v", err)
+	}
+	if _, err := RewrapPFX(in, out, "old", "new", PFXProfileLegacy); !IsOutputExists(err) {
+		t.Fatalf("expected OutputExistsError, got %v", err)
+	}
+	if _, err := RewrapPFX(in, filepath.Join(dir, "y.pfx"), "old", "new", "rc4"); err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+}
@@ -3,10 +3,12 @@ package cert
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -15,9 +17,85 @@ type FromP7BResult struct {
 	CertFiles []string `json:"cert_files"`
 }
 
+// ToP7BResult describes a certs-only PKCS#7 bundle built by BuildP7B.
+// Subjects lists the certificates in the order written.
+type ToP7BResult struct {
+	Output   string   `json:"output,omitempty"`
+	Encoding string   `json:"encoding"`
+	Subjects []string `json:"subjects"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// BuildP7B collects the certificates in inputs (PEM, DER or P7B files), drops
+// duplicates, orders them leaf → intermediate → root as chain does, and
+// encodes them as a certs-only PKCS#7: DER when der is set, otherwise PEM
+// with "PKCS7" armour.
+func BuildP7B(inputs []string, der bool) ([]byte, *ToP7BResult, error) {
+	var certs []*x509.Certificate
+	for _, in := range inputs {
+		ft, err := DetectType(in)
+		if err != nil {
+			return nil, nil, fmt.Errorf("detect type: %w", err)
+		}
+		if ft == FileTypePFX || ft == FileTypeJKS || !isCertFileType(ft) {
+			return nil, nil, fmt.Errorf("%s: expected a PEM, DER or P7B certificate file, got %s", in, ft)
+		}
+		loaded, err := loadCertificates(in, ft, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", in, err)
+		}
+		for _, c := range loaded {
+			if !slices.ContainsFunc(certs, c.Equal) {
+				certs = append(certs, c)
+			}
+		}
+	}
+	if len(certs) == 0 {
+		return nil, nil, fmt.Errorf("no certificates found")
+	}
+
+	ordered, warnings := orderCerts(certs, nil)
+	result := &ToP7BResult{Encoding: "pem", Warnings: warnings}
+	chain := make([]*x509.Certificate, 0, len(ordered))
+	for _, idx := range ordered {
+		chain = append(chain, certs[idx])
+		result.Subjects = append(result.Subjects, opensslName(certs[idx].Subject))
+	}
+	data, err := MarshalPKCS7Certificates(chain)
+	if err != nil {
+		return nil, nil, err
+	}
+	if der {
+		result.Encoding = "der"
+		return data, result, nil
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PKCS7", Bytes: data}), result, nil
+}
+
+// ToP7B writes the certificates in inputs to outputPath as a certs-only
+// PKCS#7 bundle (see BuildP7B). Pure Go on both backends.
+func (e *Engine) ToP7B(_ context.Context, inputs []string, outputPath string, der bool) (*ToP7BResult, error) {
+	if err := ensureNotExists(outputPath); err != nil {
+		return nil, err
+	}
+	data, result, err := BuildP7B(inputs, der)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileExclusive(outputPath, data, 0o644); err != nil {
+		return nil, err
+	}
+	result.Output = outputPath
+	return result, nil
+}
+
 // P7BSummary extracts the first certificate from a P7B file and returns
 // a CertSummary for it.
 func (e *Engine) P7BSummary(ctx context.Context, path string) (*CertSummary, error) {
+	if e.native() {
+		return nativeSummary(path, FileTypeP7B, "")
+	}
+
 	stdout, stderr, err := e.exec.Run(ctx, "pkcs7", "-print_certs", "-in", path)
 	if err != nil {
 		msg := strings.TrimSpace(string(stderr))
@@ -44,6 +122,10 @@ func (e *Engine) P7BSummary(ctx context.Context, path string) (*CertSummary, err
 
 // P7BDetails returns the full text details of the first certificate in a P7B.
 func (e *Engine) P7BDetails(ctx context.Context, path string) (*CertDetails, error) {
+	if e.native() {
+		return nativeDetails(path, FileTypeP7B, "")
+	}
+
 	stdout, stderr, err := e.exec.Run(ctx, "pkcs7", "-print_certs", "-in", path)
 	if err != nil {
 		msg := strings.TrimSpace(string(stderr))
@@ -79,13 +161,9 @@ func (e *Engine) P7BDetails(ctx context.Context, path string) (*CertDetails, err
 
 // FromP7B extracts all certificates from a P7B file to individual PEM files.
 func (e *Engine) FromP7B(ctx context.Context, path, outDir string) (*FromP7BResult, error) {
-	stdout, stderr, err := e.exec.Run(ctx, "pkcs7", "-print_certs", "-in", path)
+	stdout, err := e.p7bCertsPEM(ctx, path)
 	if err != nil {
-		msg := strings.TrimSpace(string(stderr))
-		if msg == "" {
-			msg = err.Error()
-		}
-		return nil, fmt.Errorf("read p7b: %s", msg)
+		return nil, err
 	}
 
 	// Split PEM output into individual certificate blocks.
@@ -131,3 +209,28 @@ func (e *Engine) FromP7B(ctx context.Context, path, outDir string) (*FromP7BResu
 
 	return result, nil
 }
+
+// p7bCertsPEM returns every certificate in a P7B file as concatenated PEM.
+func (e *Engine) p7bCertsPEM(ctx context.Context, path string) ([]byte, error) {
+	if e.native() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read p7b: %w", err)
+		}
+		certs, err := ParsePKCS7Certificates(data)
+		if err != nil {
+			return nil, fmt.Errorf("read p7b: %w", err)
+		}
+		return encodeCertsPEM(certs), nil
+	}
+
+	stdout, stderr, err := e.exec.Run(ctx, "pkcs7", "-print_certs", "-in", path)
+	if err != nil {
+		msg := strings.TrimSpace(string(stderr))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("read p7b: %s", msg)
+	}
+	return stdout, nil
+}
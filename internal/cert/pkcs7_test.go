@@ -1,8 +1,14 @@
 package cert
 
 import (
+	"bytes"
 	"context"
+	"encoding/pem"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/nickromney/certconv/test/testutil"
 )
 
 type p7bFakeExec struct{}
@@ -129,3 +135,60 @@ func TestFromP7B_Fake(t *testing.T) {
 		t.Fatalf("expected 1 cert file, got %d", len(result.CertFiles))
 	}
 }
+
+func TestBuildP7B_OrdersAndRoundTrips(t *testing.T) {
+	chain := testutil.MakeChain(t)
+	var reversed bytes.Buffer
+	for _, p := range []string{chain.RootPath, chain.IntermediatePath} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reversed.Write(data)
+	}
+	bundle := filepath.Join(chain.Dir, "ca-reversed.pem")
+	if err := os.WriteFile(bundle, reversed.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The leaf appears twice across the inputs and is written once.
+	out := filepath.Join(chain.Dir, "chain.p7b")
+	r, err := NewDefaultEngine().ToP7B(context.Background(), []string{bundle, chain.LeafPath, chain.LeafPath}, out, false)
+	if err != nil {
+		t.Fatalf("ToP7B: %v", err)
+	}
+	if len(r.Subjects) != 3 || r.Encoding != "pem" {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block, _ := pem.Decode(data); block == nil || block.Type != "PKCS7" {
+		t.Fatalf("expected PKCS7 PEM armour, got:\n%s", data)
+	}
+	certs, err := ParsePKCS7Certificates(data)
+	if err != nil {
+		t.Fatalf("ParsePKCS7Certificates: %v", err)
+	}
+	if len(certs) != 3 || certs[0].Subject.CommonName != "app.test.local" || !isSelfSigned(certs[2]) {
+		t.Fatalf("want leaf → intermediate → root, got %v", r.Subjects)
+	}
+
+	if _, err := NewDefaultEngine().ToP7B(context.Background(), []string{chain.LeafPath}, out, false); !IsOutputExists(err) {
+		t.Fatalf("expected OutputExistsError, got %v", err)
+	}
+
+	der, _, err := BuildP7B([]string{out}, true)
+	if err != nil {
+		t.Fatalf("BuildP7B from p7b: %v", err)
+	}
+	if again, err := ParsePKCS7Certificates(der); err != nil || len(again) != 3 {
+		t.Fatalf("DER round trip: %d certs, %v", len(again), err)
+	}
+
+	if _, _, err := BuildP7B([]string{chain.LeafKeyPath}, false); err == nil {
+		t.Fatalf("expected error for a key file")
+	}
+}
@@ -0,0 +1,103 @@
+package cert
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+var (
+	oidPKCS7Data       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidPKCS7SignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+)
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+// pkcs7CertsOnly is the degenerate SignedData of a certs-only bundle: no
+// digest algorithms, no content, no CRLs and no signers (RFC 2315 9.1).
+type pkcs7CertsOnly struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      struct{ ContentType asn1.ObjectIdentifier }
+	Certificates     asn1.RawValue
+	SignerInfos      asn1.RawValue
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      asn1.RawValue
+}
+
+// ParsePKCS7Certificates returns the certificates embedded in a PKCS#7
+// SignedData structure (a .p7b/.p7c "certs-only" bundle). Both PEM
+// ("PKCS7" / "CERTIFICATE" armour) and raw DER are accepted.
+func ParsePKCS7Certificates(data []byte) ([]*x509.Certificate, error) {
+	der := data
+	if block, _ := pem.Decode(data); block != nil {
+		der = block.Bytes
+	}
+
+	var ci pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		return nil, fmt.Errorf("parse pkcs7: %w", err)
+	}
+	if !ci.ContentType.Equal(oidPKCS7SignedData) {
+		return nil, fmt.Errorf("unsupported pkcs7 content type %s", ci.ContentType)
+	}
+
+	var sd pkcs7SignedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("parse pkcs7 signed data: %w", err)
+	}
+	if len(sd.Certificates.Bytes) == 0 {
+		return nil, errors.New("no certificates found in P7B file")
+	}
+
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse pkcs7 certificates: %w", err)
+	}
+	return certs, nil
+}
+
+// MarshalPKCS7Certificates encodes certs, in the order given, as a DER
+// certs-only PKCS#7 SignedData, the structure "openssl crl2pkcs7 -nocrl"
+// writes.
+func MarshalPKCS7Certificates(certs []*x509.Certificate) ([]byte, error) {
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates to encode")
+	}
+	var raw []byte
+	for _, c := range certs {
+		raw = append(raw, c.Raw...)
+	}
+	emptySet := asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: []byte{}}
+	sd := pkcs7CertsOnly{
+		Version:          1,
+		DigestAlgorithms: emptySet,
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: raw},
+		SignerInfos:      emptySet,
+	}
+	sd.ContentInfo.ContentType = oidPKCS7Data
+	sdDER, err := asn1.Marshal(sd)
+	if err != nil {
+		return nil, fmt.Errorf("encode pkcs7 signed data: %w", err)
+	}
+	der, err := asn1.Marshal(pkcs7ContentInfo{
+		ContentType: oidPKCS7SignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sdDER},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode pkcs7: %w", err)
+	}
+	return der, nil
+}
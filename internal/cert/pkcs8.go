@@ -0,0 +1,316 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// PKCS#5 v2 (RFC 8018) and scrypt (RFC 7914) object identifiers.
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidScrypt = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+type pkixAlgorithm struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkixAlgorithm
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkixAlgorithm
+	EncryptionScheme  pkixAlgorithm
+}
+
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int           `asn1:"optional"`
+	PRF        pkixAlgorithm `asn1:"optional"`
+}
+
+type scryptParams struct {
+	Salt            []byte
+	CostParameter   int
+	BlockSize       int
+	Parallelization int
+	KeyLength       int `asn1:"optional"`
+}
+
+// decryptPKCS8 decrypts a DER-encoded EncryptedPrivateKeyInfo protected with
+// PBES2 (PBKDF2 or scrypt, AES-CBC or 3DES-CBC) and returns the inner
+// PrivateKeyInfo DER.
+func decryptPKCS8(der, password []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("parse encrypted private key: %w", err)
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported private key encryption %s (only PBES2 is supported)", info.Algorithm.Algorithm)
+	}
+	return pbes2Decrypt(info.Algorithm.Parameters.FullBytes, info.EncryptedData, password)
+}
+
+// pbes2Decrypt decrypts ciphertext protected with PBES2, given the DER of
+// its PBES2-params. A padding failure is reported as ErrKeyIncorrectPassword.
+func pbes2Decrypt(paramsDER, ciphertext, password []byte) ([]byte, error) {
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(paramsDER, &params); err != nil {
+		return nil, fmt.Errorf("parse PBES2 parameters: %w", err)
+	}
+
+	keyLen, newBlock, err := pbes2Cipher(params.EncryptionScheme.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("parse cipher IV: %w", err)
+	}
+
+	key, err := pbes2DeriveKey(params.KeyDerivationFunc, password, keyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() || len(ciphertext)%block.BlockSize() != 0 || len(ciphertext) == 0 {
+		return nil, errors.New("malformed encrypted data")
+	}
+
+	plain := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, ciphertext)
+
+	plain, err = pkcs7Unpad(plain, block.BlockSize())
+	if err != nil {
+		return nil, ErrKeyIncorrectPassword
+	}
+	return plain, nil
+}
+
+// Cost parameters for keys encrypted by encryptPKCS8. PBKDF2 follows the
+// OWASP recommendation for HMAC-SHA256; scrypt uses OpenSSL's defaults.
+const (
+	pkcs8PBKDF2Iterations = 600000
+	pkcs8ScryptN          = 1 << 14
+	pkcs8ScryptR          = 8
+	pkcs8ScryptP          = 1
+)
+
+// encryptPKCS8 wraps PrivateKeyInfo DER in an EncryptedPrivateKeyInfo using
+// PBES2 with AES-256-CBC and the given key derivation function.
+func encryptPKCS8(der, password []byte, kdf KeyKDF) ([]byte, error) {
+	alg, encrypted, err := pbes2Encrypt(der, password, kdf, pkcs8PBKDF2Iterations)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encryptedPrivateKeyInfo{Algorithm: alg, EncryptedData: encrypted})
+}
+
+// pbes2Encrypt encrypts plain with PBES2 and AES-256-CBC, returning the
+// PBES2 AlgorithmIdentifier and the ciphertext. iterations applies to PBKDF2
+// (HMAC-SHA256); scrypt always uses the fixed cost above.
+func pbes2Encrypt(plain, password []byte, kdf KeyKDF, iterations int) (pkixAlgorithm, []byte, error) {
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return pkixAlgorithm{}, nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return pkixAlgorithm{}, nil, err
+	}
+
+	var kdfAlg pkixAlgorithm
+	var key []byte
+	switch kdf {
+	case KeyKDFScrypt:
+		p := scryptParams{
+			Salt:            salt,
+			CostParameter:   pkcs8ScryptN,
+			BlockSize:       pkcs8ScryptR,
+			Parallelization: pkcs8ScryptP,
+			KeyLength:       32,
+		}
+		params, err := asn1.Marshal(p)
+		if err != nil {
+			return pkixAlgorithm{}, nil, err
+		}
+		kdfAlg = pkixAlgorithm{Algorithm: oidScrypt, Parameters: asn1.RawValue{FullBytes: params}}
+		key, err = scrypt.Key(password, salt, p.CostParameter, p.BlockSize, p.Parallelization, 32)
+		if err != nil {
+			return pkixAlgorithm{}, nil, err
+		}
+	case KeyKDFPBKDF2, "":
+		p := pbkdf2Params{
+			Salt:       salt,
+			Iterations: iterations,
+			PRF:        pkixAlgorithm{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+		}
+		params, err := asn1.Marshal(p)
+		if err != nil {
+			return pkixAlgorithm{}, nil, err
+		}
+		kdfAlg = pkixAlgorithm{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: params}}
+		key, err = pbkdf2.Key(sha256.New, string(password), salt, p.Iterations, 32)
+		if err != nil {
+			return pkixAlgorithm{}, nil, err
+		}
+	default:
+		return pkixAlgorithm{}, nil, fmt.Errorf("unsupported key derivation function %q (use pbkdf2 or scrypt)", kdf)
+	}
+
+	ivParam, err := asn1.Marshal(iv)
+	if err != nil {
+		return pkixAlgorithm{}, nil, err
+	}
+	pbes2, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: kdfAlg,
+		EncryptionScheme:  pkixAlgorithm{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParam}},
+	})
+	if err != nil {
+		return pkixAlgorithm{}, nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return pkixAlgorithm{}, nil, err
+	}
+	padded := pkcs7Pad(plain, block.BlockSize())
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+	return pkixAlgorithm{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: pbes2}}, padded, nil
+}
+
+func pbes2Cipher(oid asn1.ObjectIdentifier) (int, func([]byte) (cipher.Block, error), error) {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return 16, aes.NewCipher, nil
+	case oid.Equal(oidAES192CBC):
+		return 24, aes.NewCipher, nil
+	case oid.Equal(oidAES256CBC):
+		return 32, aes.NewCipher, nil
+	case oid.Equal(oidDESEDE3CBC):
+		return 24, des.NewTripleDESCipher, nil
+	}
+	return 0, nil, fmt.Errorf("unsupported PBES2 cipher %s", oid)
+}
+
+// Key derivation parameters come from the file being read, so they are
+// capped before use: a crafted key must not demand gigabytes of memory or
+// hours of hashing. The scrypt cap is OpenSSL's default maxmem.
+const (
+	maxKDFIterations  = 10_000_000
+	maxScryptMemory   = 32 << 20
+	maxScryptBlockLen = maxScryptMemory / 128
+)
+
+func checkKDFIterations(n int) error {
+	if n < 1 || n > maxKDFIterations {
+		return fmt.Errorf("key derivation iteration count %d is out of range (1 to %d)", n, maxKDFIterations)
+	}
+	return nil
+}
+
+// checkScryptParams rejects scrypt parameters needing more than
+// maxScryptMemory, which is roughly 128*r*(N+p) bytes.
+func checkScryptParams(n, r, p int) error {
+	if n < 2 || r < 1 || p < 1 || r > maxScryptBlockLen || n > maxScryptBlockLen || p > maxScryptBlockLen ||
+		int64(128)*int64(r)*(int64(n)+int64(p)) > maxScryptMemory {
+		return fmt.Errorf("scrypt parameters N=%d, r=%d, p=%d exceed the %d MiB memory limit", n, r, p, maxScryptMemory>>20)
+	}
+	return nil
+}
+
+func pbes2DeriveKey(kdf pkixAlgorithm, password []byte, keyLen int) ([]byte, error) {
+	switch {
+	case kdf.Algorithm.Equal(oidPBKDF2):
+		var p pbkdf2Params
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &p); err != nil {
+			return nil, fmt.Errorf("parse PBKDF2 parameters: %w", err)
+		}
+		h, err := pbkdf2PRF(p.PRF.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkKDFIterations(p.Iterations); err != nil {
+			return nil, err
+		}
+		return pbkdf2.Key(h, string(password), p.Salt, p.Iterations, keyLen)
+
+	case kdf.Algorithm.Equal(oidScrypt):
+		var p scryptParams
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &p); err != nil {
+			return nil, fmt.Errorf("parse scrypt parameters: %w", err)
+		}
+		if err := checkScryptParams(p.CostParameter, p.BlockSize, p.Parallelization); err != nil {
+			return nil, err
+		}
+		return scrypt.Key(password, p.Salt, p.CostParameter, p.BlockSize, p.Parallelization, keyLen)
+	}
+	return nil, fmt.Errorf("unsupported key derivation function %s", kdf.Algorithm)
+}
+
+func pbkdf2PRF(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHMACWithSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidHMACWithSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidHMACWithSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidHMACWithSHA512):
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unsupported PBKDF2 PRF %s", oid)
+}
+
+func pkcs7Pad(b []byte, blockSize int) []byte {
+	n := blockSize - len(b)%blockSize
+	out := make([]byte, len(b), len(b)+n)
+	copy(out, b)
+	return append(out, bytes.Repeat([]byte{byte(n)}, n)...)
+}
+
+func pkcs7Unpad(b []byte, blockSize int) ([]byte, error) {
+	if len(b) == 0 || len(b)%blockSize != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	n := int(b[len(b)-1])
+	if n == 0 || n > blockSize || n > len(b) {
+		return nil, errors.New("invalid padding")
+	}
+	if !bytes.Equal(b[len(b)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, errors.New("invalid padding")
+	}
+	return b[:len(b)-n], nil
+}
@@ -13,6 +13,13 @@ func (e *Engine) CertDER(ctx context.Context, pemCertPath string) ([]byte, error
 		// Combined PEM (.key with cert) is still acceptable as long as it has a cert marker.
 		return nil, err
 	}
+	if e.native() {
+		c, err := ParseCertFile(pemCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("convert to DER: %w", err)
+		}
+		return c.Raw, nil
+	}
 	stdout, stderr, err := e.exec.Run(ctx, "x509", "-in", pemCertPath, "-outform", "DER")
 	if err != nil {
 		msg := string(stderr)
@@ -36,6 +43,9 @@ func (e *Engine) PFXBytes(ctx context.Context, certPath, keyPath, password, caPa
 	if err := ValidatePEMKey(keyPath); err != nil {
 		return nil, err
 	}
+	if e.native() {
+		return nativePFXBytes(certPath, keyPath, password, caPath, "")
+	}
 
 	// Check key matches cert.
 	m, err := e.MatchKeyToCert(ctx, certPath, keyPath, "")
@@ -0,0 +1,389 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+// ScanKind says whether a ScanRecord describes a certificate or a key.
+type ScanKind string
+
+const (
+	ScanKindCertificate ScanKind = "certificate"
+	ScanKindPrivateKey  ScanKind = "private-key"
+)
+
+// ScanRecord is one certificate or private key found by ScanDir. A file
+// holding a bundle or a container yields one record per item, numbered by
+// Index.
+type ScanRecord struct {
+	Path         string   `json:"path"`
+	Type         FileType `json:"type"`
+	Kind         ScanKind `json:"kind,omitempty"`
+	Index        int      `json:"index"`
+	Subject      string   `json:"subject,omitempty"`
+	Issuer       string   `json:"issuer,omitempty"`
+	SANs         []string `json:"sans,omitempty"`
+	NotAfter     string   `json:"not_after,omitempty"`
+	DaysLeft     *int     `json:"days_left,omitempty"`
+	Fingerprint  string   `json:"fingerprint,omitempty"`
+	KeyAlgorithm string   `json:"key_algorithm,omitempty"`
+	Encrypted    bool     `json:"encrypted,omitempty"`
+	// Error is set when the file looked relevant but could not be read,
+	// e.g. a PFX with no matching password.
+	Error string `json:"error,omitempty"`
+}
+
+// ScanPassword supplies a password for files whose name or path relative to
+// the scan root matches Pattern (path.Match syntax).
+type ScanPassword struct {
+	Pattern  string
+	Password string
+}
+
+// ScanOptions controls ScanDir.
+type ScanOptions struct {
+	Recursive bool
+	// Include and Exclude are path.Match globs tried against both the file
+	// name and the slash-separated path relative to the scan root. With no
+	// Include patterns every file is considered.
+	Include []string
+	Exclude []string
+	// Passwords are tried in order for PFX/JKS stores and encrypted keys,
+	// followed by the empty password.
+	Passwords []ScanPassword
+	// Now is the reference time for DaysLeft; zero means time.Now().
+	Now time.Time
+}
+
+// maxScanFileSize skips files too large to be a certificate or key store.
+const maxScanFileSize = 16 << 20
+
+// ScanDir walks root and returns a record for every certificate and private
+// key it finds. Hidden files and directories are skipped, as in the TUI file
+// pane. Files that are not certificates or keys produce no records.
+func ScanDir(root string, opts ScanOptions) ([]ScanRecord, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	var records []ScanRecord
+	err := walkScanFiles(root, opts, func(p, rel string, err error) {
+		if err != nil {
+			records = append(records, ScanRecord{Path: p, Type: FileTypeUnknown, Error: err.Error()})
+			return
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			records = append(records, ScanRecord{Path: p, Type: FileTypeUnknown, Error: err.Error()})
+			return
+		}
+		records = append(records, ScanBytes(p, data, scanPasswordsFor(opts.Passwords, path.Base(rel), rel), opts.Now)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// walkScanFiles calls visit for each regular file under root that passes the
+// Recursive, Include and Exclude options, or with the error for an entry that
+// could not be read. rel is slash-separated and relative to root.
+func walkScanFiles(root string, opts ScanOptions, visit func(p, rel string, err error)) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			visit(p, "", err)
+			return nil
+		}
+		if p == root {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		name := d.Name()
+		if d.IsDir() {
+			if !opts.Recursive || strings.HasPrefix(name, ".") || matchesAnyGlob(opts.Exclude, name, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || strings.HasPrefix(name, ".") {
+			return nil
+		}
+		if matchesAnyGlob(opts.Exclude, name, rel) {
+			return nil
+		}
+		if len(opts.Include) > 0 && !matchesAnyGlob(opts.Include, name, rel) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Size() > maxScanFileSize {
+			return nil
+		}
+		visit(p, rel, nil)
+		return nil
+	})
+}
+
+// ScanBytes returns the certificate and key records for one file's
+// contents, trying each password in turn for protected items.
+func ScanBytes(name string, data []byte, passwords []string, now time.Time) []ScanRecord {
+	ft := DetectTypeFromNameAndBytes(name, data)
+	base := ScanRecord{Path: name, Type: ft}
+	if len(passwords) == 0 {
+		passwords = []string{""}
+	}
+
+	var certs []*x509.Certificate
+	var keys []scannedKey
+	var err error
+	switch ft {
+	case FileTypeCert, FileTypeCombined, FileTypeKey:
+		certs, keys = scanPEM(data, passwords)
+	case FileTypeDER:
+		var c *x509.Certificate
+		if c, err = x509.ParseCertificate(data); err == nil {
+			certs = []*x509.Certificate{c}
+		}
+	case FileTypeP7B:
+		certs, err = ParsePKCS7Certificates(data)
+	case FileTypePFX:
+		certs, keys, err = scanPFX(data, passwords)
+	case FileTypeJKS:
+		certs, keys, err = scanKeystore(data, passwords)
+	case FileTypeSSHCert:
+		return []ScanRecord{scanSSHCert(base, data, now)}
+	default:
+		return nil
+	}
+	if err != nil {
+		base.Error = err.Error()
+		return []ScanRecord{base}
+	}
+
+	var out []ScanRecord
+	for _, c := range certs {
+		r := base
+		r.Kind = ScanKindCertificate
+		r.Index = len(out)
+		r.Subject = c.Subject.String()
+		r.Issuer = c.Issuer.String()
+		r.SANs = collectSANs(c)
+		r.NotAfter = c.NotAfter.UTC().Format(time.RFC3339)
+		days := int(c.NotAfter.Sub(now).Hours() / 24)
+		r.DaysLeft = &days
+		fp := sha256.Sum256(c.Raw)
+		r.Fingerprint = formatFingerprint(hex.EncodeToString(fp[:]))
+		r.KeyAlgorithm = describePublicKey(c)
+		out = append(out, r)
+	}
+	for _, k := range keys {
+		r := base
+		r.Kind = ScanKindPrivateKey
+		r.Index = len(out)
+		r.Encrypted = k.encrypted
+		if k.pub != nil {
+			r.KeyAlgorithm = describePublicKeyValue(k.pub)
+			if spki, err := x509.MarshalPKIXPublicKey(k.pub); err == nil {
+				fp := sha256.Sum256(spki)
+				r.Fingerprint = formatFingerprint(hex.EncodeToString(fp[:]))
+			}
+		}
+		if k.err != nil {
+			r.Error = k.err.Error()
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// scanSSHCert records an OpenSSH certificate: principals stand in for SANs
+// and the signing CA's fingerprint for the issuer.
+func scanSSHCert(r ScanRecord, data []byte, now time.Time) ScanRecord {
+	c, comment, err := ParseSSHCertificate(data)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	info := NewSSHCertInfo(c, comment)
+	r.Kind = ScanKindCertificate
+	r.Subject = info.Type + " " + strconv.Quote(info.KeyID)
+	r.Issuer = info.CAFingerprint
+	r.SANs = info.Principals
+	r.NotAfter = info.ValidBefore
+	r.DaysLeft = sshCertDaysLeft(c, now)
+	r.Fingerprint = info.KeyFingerprint
+	r.KeyAlgorithm = info.KeyAlgorithm
+	return r
+}
+
+// scannedKey is a private key found during a scan. Only the public half is
+// kept; fingerprints are of the SubjectPublicKeyInfo.
+type scannedKey struct {
+	pub       crypto.PublicKey
+	encrypted bool
+	err       error
+}
+
+func scanPEM(data []byte, passwords []string) ([]*x509.Certificate, []scannedKey) {
+	var certs []*x509.Certificate
+	var keys []scannedKey
+	rest := data
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		rest = r
+		switch {
+		case block.Type == "CERTIFICATE":
+			if c, err := x509.ParseCertificate(block.Bytes); err == nil {
+				certs = append(certs, c)
+			}
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			keys = append(keys, scanKeyBlock(block, passwords))
+		}
+	}
+	return certs, keys
+}
+
+func scanKeyBlock(block *pem.Block, passwords []string) scannedKey {
+	encrypted := block.Type == "ENCRYPTED PRIVATE KEY" || block.Headers["Proc-Type"] != ""
+	// An OpenSSH key's public half is readable without its passphrase.
+	var headerPub crypto.PublicKey
+	if info, err := ParseOpenSSHKeyInfo(block); err == nil {
+		encrypted = info.Encrypted
+		if cpk, ok := info.publicKey.(ssh.CryptoPublicKey); ok {
+			headerPub = cpk.CryptoPublicKey()
+		}
+	}
+	var lastErr error
+	for _, pw := range passwords {
+		if encrypted && pw == "" {
+			continue
+		}
+		key, err := parsePrivateKeyBlock(block, pw)
+		if err == nil {
+			return scannedKey{pub: key.Public(), encrypted: encrypted}
+		}
+		lastErr = err
+	}
+	if lastErr == nil || errors.Is(lastErr, ErrKeyIncorrectPassword) {
+		// No usable password: report the key as encrypted rather than as
+		// an error, since inventories routinely lack key passwords.
+		return scannedKey{pub: headerPub, encrypted: true}
+	}
+	return scannedKey{encrypted: encrypted, err: lastErr}
+}
+
+func scanPFX(data []byte, passwords []string) ([]*x509.Certificate, []scannedKey, error) {
+	var lastErr error
+	for _, pw := range passwords {
+		key, certs, err := decodePFXForScan(data, pw)
+		if err == nil {
+			var keys []scannedKey
+			if key != nil {
+				keys = append(keys, scannedKey{pub: key.Public()})
+			}
+			return certs, keys, nil
+		}
+		lastErr = err
+	}
+	return nil, nil, lastErr
+}
+
+func decodePFXForScan(data []byte, password string) (crypto.Signer, []*x509.Certificate, error) {
+	if key, leaf, caCerts, err := pkcs12.DecodeChain(data, password); err == nil {
+		signer, _ := key.(crypto.Signer)
+		return signer, append([]*x509.Certificate{leaf}, caCerts...), nil
+	}
+	// Truststores have no key; ParsePFXCertificates also classifies errors.
+	_, certs, err := ParsePFXCertificates(data, password)
+	return nil, certs, err
+}
+
+func scanKeystore(data []byte, passwords []string) ([]*x509.Certificate, []scannedKey, error) {
+	var lastErr error
+	for _, pw := range passwords {
+		ks, err := ParseKeystore(data, pw)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		var certs []*x509.Certificate
+		var keys []scannedKey
+		for _, e := range ks.Entries {
+			certs = append(certs, e.Chain...)
+			if e.Type == KeystoreEntryPrivateKey && len(e.Chain) > 0 {
+				// The key matches its leaf certificate, so there is no need
+				// to decrypt it just to describe it.
+				keys = append(keys, scannedKey{pub: e.Chain[0].PublicKey, encrypted: true})
+			}
+		}
+		return certs, keys, nil
+	}
+	return nil, nil, lastErr
+}
+
+// scanPasswordsFor returns the passwords whose pattern matches a file,
+// followed by the empty password.
+func scanPasswordsFor(rules []ScanPassword, name, rel string) []string {
+	var out []string
+	for _, r := range rules {
+		if matchesAnyGlob([]string{r.Pattern}, name, rel) {
+			out = append(out, r.Password)
+		}
+	}
+	return append(out, "")
+}
+
+func matchesAnyGlob(patterns []string, name, rel string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScanPasswords reads a password map: one PATTERN=PASSWORD per line.
+// Blank lines and lines starting with # are ignored. Only the first = splits,
+// so passwords may contain =.
+func ParseScanPasswords(data []byte) ([]ScanPassword, error) {
+	var out []ScanPassword
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		pattern, password, ok := strings.Cut(line, "=")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("line %d: expected PATTERN=PASSWORD", i+1)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("line %d: bad pattern %q: %w", i+1, pattern, err)
+		}
+		out = append(out, ScanPassword{Pattern: pattern, Password: password})
+	}
+	return out, nil
+}
@@ -0,0 +1,150 @@
+package cert
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+// copyInto copies src to dir/name, creating dir.
+func copyInto(t *testing.T, src, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestScanDir(t *testing.T) {
+	chain := testutil.MakeChain(t)
+	pair := testutil.MakeCertPair(t)
+	pfx := testutil.MakePFX(t, pair, "s3cret")
+
+	root := t.TempDir()
+	copyInto(t, chain.LeafPath, root, "leaf.pem")
+	copyInto(t, chain.LeafKeyPath, root, "leaf.key")
+	copyInto(t, chain.IntermediatePath, filepath.Join(root, "ca"), "intermediate.pem")
+	copyInto(t, pfx, filepath.Join(root, "stores"), "app.p12")
+	copyInto(t, chain.RootPath, filepath.Join(root, ".git"), "hidden.pem")
+	if err := os.WriteFile(filepath.Join(root, "README.txt"), []byte("not a cert\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	byName := func(records []ScanRecord) map[string][]ScanRecord {
+		m := map[string][]ScanRecord{}
+		for _, r := range records {
+			rel, _ := filepath.Rel(root, r.Path)
+			m[filepath.ToSlash(rel)] = append(m[filepath.ToSlash(rel)], r)
+		}
+		return m
+	}
+
+	records, err := ScanDir(root, ScanOptions{})
+	if err != nil {
+		t.Fatalf("ScanDir() error = %v", err)
+	}
+	got := byName(records)
+	if len(got) != 2 || len(got["leaf.pem"]) != 1 || len(got["leaf.key"]) != 1 {
+		t.Fatalf("non-recursive scan = %+v", got)
+	}
+	leaf := got["leaf.pem"][0]
+	if leaf.Kind != ScanKindCertificate || !strings.Contains(leaf.Subject, "app.test.local") || !strings.Contains(leaf.Issuer, "Intermediate") {
+		t.Errorf("leaf record = %+v", leaf)
+	}
+	if leaf.DaysLeft == nil || *leaf.DaysLeft < 85 || *leaf.DaysLeft > 90 || leaf.Fingerprint == "" || leaf.KeyAlgorithm != "ECDSA P-256" {
+		t.Errorf("leaf record = %+v", leaf)
+	}
+	// A key's fingerprint is of its public key, so it differs from the
+	// certificate's, but the algorithm is the same.
+	key := got["leaf.key"][0]
+	if key.Kind != ScanKindPrivateKey || key.KeyAlgorithm != leaf.KeyAlgorithm || key.Fingerprint == "" {
+		t.Errorf("key record = %+v", key)
+	}
+
+	records, err = ScanDir(root, ScanOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("ScanDir() error = %v", err)
+	}
+	got = byName(records)
+	if len(got["ca/intermediate.pem"]) != 1 || len(got[".git/hidden.pem"]) != 0 {
+		t.Errorf("recursive scan = %+v", got)
+	}
+	if p := got["stores/app.p12"]; len(p) != 1 || p[0].Error == "" {
+		t.Errorf("PFX without password = %+v, want one error record", p)
+	}
+
+	rules, err := ParseScanPasswords([]byte("# stores\nstores/*.p12=s3cret\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err = ScanDir(root, ScanOptions{Recursive: true, Include: []string{"*.p12"}, Passwords: rules})
+	if err != nil {
+		t.Fatalf("ScanDir() error = %v", err)
+	}
+	if len(records) != 2 || records[0].Kind != ScanKindCertificate || records[1].Kind != ScanKindPrivateKey || records[1].Index != 1 {
+		t.Errorf("PFX with password = %+v", records)
+	}
+
+	records, err = ScanDir(root, ScanOptions{Recursive: true, Exclude: []string{"ca", "*.key"}})
+	if err != nil {
+		t.Fatalf("ScanDir() error = %v", err)
+	}
+	got = byName(records)
+	if len(got["ca/intermediate.pem"]) != 0 || len(got["leaf.key"]) != 0 || len(got["leaf.pem"]) != 1 {
+		t.Errorf("excluded scan = %+v", got)
+	}
+}
+
+func TestScanBytes_EncryptedKeyWithoutPassword(t *testing.T) {
+	pair := testutil.MakeCertPair(t)
+	_, key := loadTestPair(t, pair)
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		t.Fatalf("key type = %T, want RSA", key)
+	}
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), []byte("pw"), x509.PEMCipherAES256) //nolint:staticcheck // legacy PEM encryption is what inventories meet
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(block)
+
+	records := ScanBytes("enc.key", data, nil, time.Now())
+	if len(records) != 1 || !records[0].Encrypted || records[0].Error != "" || records[0].KeyAlgorithm != "" {
+		t.Errorf("without password = %+v", records)
+	}
+	records = ScanBytes("enc.key", data, []string{"pw"}, time.Now())
+	if len(records) != 1 || !records[0].Encrypted || records[0].KeyAlgorithm != "RSA 2048" {
+		t.Errorf("with password = %+v", records)
+	}
+}
+
+func TestParseScanPasswords(t *testing.T) {
+	rules, err := ParseScanPasswords([]byte("\n# comment\n*.pfx=a=b\r\nprod/*.jks = x \n"))
+	if err != nil {
+		t.Fatalf("ParseScanPasswords() error = %v", err)
+	}
+	if len(rules) != 2 || rules[0] != (ScanPassword{"*.pfx", "a=b"}) || rules[1] != (ScanPassword{"prod/*.jks", " x "}) {
+		t.Errorf("rules = %+v", rules)
+	}
+	for _, bad := range []string{"no-equals", "=pw", "[=pw"} {
+		if _, err := ParseScanPasswords([]byte(bad)); err == nil {
+			t.Errorf("ParseScanPasswords(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// DefaultSplitTemplate names split output files by position and subject CN.
+const DefaultSplitTemplate = "{{.Index}}-{{.SubjectCN}}.pem"
+
+// SplitName is the data a split filename template is executed with. Index
+// counts from 1 in file order; Kind is "cert" or "key". SubjectCN is reduced
+// to filename-safe characters and is "key" for private keys.
+type SplitName struct {
+	Index     int
+	Kind      string
+	SubjectCN string
+	Serial    string
+}
+
+// SplitEntry records where one block of a bundle was written.
+type SplitEntry struct {
+	Index   int    `json:"index"`
+	Kind    string `json:"kind"`
+	Subject string `json:"subject,omitempty"`
+	Path    string `json:"path"`
+}
+
+// SplitResult reports what SplitBundle wrote. Skipped lists PEM block types
+// that are neither certificates nor private keys.
+type SplitResult struct {
+	Input     string       `json:"input"`
+	OutputDir string       `json:"output_dir"`
+	Files     []SplitEntry `json:"files"`
+	Skipped   []string     `json:"skipped,omitempty"`
+}
+
+var splitKeyBlockTypes = map[string]bool{
+	"PRIVATE KEY":           true,
+	"ENCRYPTED PRIVATE KEY": true,
+	"RSA PRIVATE KEY":       true,
+	"EC PRIVATE KEY":        true,
+}
+
+// SplitBundle writes each certificate and private key block of a PEM bundle
+// to its own file in outDir, named by nameTemplate (DefaultSplitTemplate when
+// empty). Blocks are re-encoded without surrounding text, and certificates
+// without PEM headers (key headers carry the cipher of legacy encrypted keys).
+// Every name is checked before anything is written, and existing files are
+// never overwritten.
+func SplitBundle(path, outDir, nameTemplate string) (*SplitResult, error) {
+	if strings.TrimSpace(nameTemplate) == "" {
+		nameTemplate = DefaultSplitTemplate
+	}
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name template: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	type splitOutput struct {
+		entry SplitEntry
+		block *pem.Block
+	}
+	result := &SplitResult{Input: path, OutputDir: outDir, Files: []SplitEntry{}}
+	var outputs []splitOutput
+	seen := map[string]bool{}
+	hasKey := false
+
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		name := SplitName{Index: len(outputs) + 1}
+		entry := SplitEntry{Index: name.Index}
+		switch {
+		case block.Type == "CERTIFICATE":
+			c, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("certificate %d: %w", name.Index, err)
+			}
+			name.Kind = "cert"
+			name.SubjectCN = safeFileName(c.Subject.CommonName, "cert")
+			name.Serial = opensslSerial(c.SerialNumber)
+			entry.Subject = opensslName(c.Subject)
+			block.Headers = nil
+		case splitKeyBlockTypes[block.Type]:
+			name.Kind = "key"
+			name.SubjectCN = "key"
+			hasKey = true
+		default:
+			result.Skipped = append(result.Skipped, block.Type)
+			continue
+		}
+		entry.Kind = name.Kind
+
+		var b strings.Builder
+		if err := tmpl.Execute(&b, name); err != nil {
+			return nil, fmt.Errorf("name template: %w", err)
+		}
+		file := b.String()
+		if file == "" || file == "." || file == ".." || strings.ContainsAny(file, `/\`) {
+			return nil, fmt.Errorf("name template produced %q for block %d; it must be a plain file name", file, name.Index)
+		}
+		if seen[file] {
+			return nil, fmt.Errorf("name template produced %q more than once; include {{.Index}}", file)
+		}
+		seen[file] = true
+		entry.Path = filepath.Join(outDir, file)
+		if err := ensureNotExists(entry.Path); err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, splitOutput{entry: entry, block: block})
+	}
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("no certificates or private keys found in PEM data")
+	}
+
+	// A directory that will hold private keys gets a restrictive default.
+	dirPerm := os.FileMode(0o755)
+	if hasKey {
+		dirPerm = 0o700
+	}
+	if err := os.MkdirAll(outDir, dirPerm); err != nil {
+		return nil, fmt.Errorf("create output directory: %w", err)
+	}
+	for _, o := range outputs {
+		perm := os.FileMode(0o644)
+		if o.entry.Kind == "key" {
+			perm = 0o600
+		}
+		var buf bytes.Buffer
+		if err := pem.Encode(&buf, o.block); err != nil {
+			return result, fmt.Errorf("encode PEM: %w", err)
+		}
+		if err := writeFileExclusive(o.entry.Path, buf.Bytes(), perm); err != nil {
+			return result, err
+		}
+		result.Files = append(result.Files, o.entry)
+	}
+	return result, nil
+}
+
+// safeFileName reduces s to letters, digits, '.', '-' and '_', replacing
+// anything else with '_'. A wildcard label becomes "wildcard".
+func safeFileName(s, fallback string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "*", "wildcard")
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+	s = strings.Trim(s, ".")
+	if s == "" {
+		return fallback
+	}
+	return s
+}
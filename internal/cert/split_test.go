@@ -0,0 +1,82 @@
+package cert
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickromney/certconv/test/testutil"
+)
+
+func TestSplitBundle(t *testing.T) {
+	chain := testutil.MakeChain(t)
+	var bundle bytes.Buffer
+	bundle.WriteString("subject=CN = app.test.local\n")
+	for _, p := range []string{chain.LeafPath, chain.IntermediatePath, chain.LeafKeyPath} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bundle.Write(data)
+	}
+	bundle.WriteString("-----BEGIN X509 CRL-----\nAAAA\n-----END X509 CRL-----\n")
+	in := filepath.Join(t.TempDir(), "bundle.pem")
+	if err := os.WriteFile(in, bundle.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := filepath.Join(t.TempDir(), "out")
+	r, err := SplitBundle(in, outDir, "")
+	if err != nil {
+		t.Fatalf("SplitBundle: %v", err)
+	}
+	if len(r.Files) != 3 || len(r.Skipped) != 1 || r.Skipped[0] != "X509 CRL" {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+	if got := filepath.Base(r.Files[0].Path); got != "1-app.test.local.pem" {
+		t.Fatalf("first file = %q", got)
+	}
+	if r.Files[2].Kind != "key" || filepath.Base(r.Files[2].Path) != "3-key.pem" {
+		t.Fatalf("key entry = %+v", r.Files[2])
+	}
+
+	leaf, err := os.ReadFile(r.Files[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(leaf), "-----BEGIN CERTIFICATE-----") {
+		t.Fatalf("leaf not normalised:\n%s", leaf)
+	}
+	if info, err := os.Stat(r.Files[2].Path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("key file mode: %v %v", info.Mode(), err)
+	}
+
+	// Nothing is written when any name is taken.
+	if _, err := SplitBundle(in, outDir, "{{.Index}}-{{.Serial}}.pem"); err != nil {
+		t.Fatalf("serial template: %v", err)
+	}
+	if _, err := SplitBundle(in, outDir, ""); !IsOutputExists(err) {
+		t.Fatalf("expected OutputExistsError, got %v", err)
+	}
+	for _, tmpl := range []string{"{{.Kind}}.pem", "../{{.Index}}.pem", "{{.Nope}}", "{{"} {
+		if _, err := SplitBundle(in, t.TempDir(), tmpl); err == nil {
+			t.Errorf("template %q: expected error", tmpl)
+		}
+	}
+}
+
+func TestSafeFileName(t *testing.T) {
+	tests := map[string]string{
+		"*.example.com":    "wildcard.example.com",
+		"Acme Root CA / 2": "Acme_Root_CA___2",
+		"..":               "cert",
+		"":                 "cert",
+	}
+	for in, want := range tests {
+		if got := safeFileName(in, "cert"); got != want {
+			t.Errorf("safeFileName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package cert
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sshCertNoExpiry stands in for an SSH certificate valid "forever", as
+// RFC 5280 uses 99991231235959Z for X.509 certificates without a
+// well-defined expiry.
+var sshCertNoExpiry = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+
+// SSHCertInfo describes an OpenSSH certificate (cert-v01) for display.
+type SSHCertInfo struct {
+	KeyID           string            `json:"key_id"`
+	Type            string            `json:"type"` // "user" or "host"
+	Serial          uint64            `json:"serial"`
+	KeyAlgorithm    string            `json:"key_algorithm"`
+	KeyFingerprint  string            `json:"key_fingerprint"`
+	Principals      []string          `json:"principals"`
+	ValidAfter      string            `json:"valid_after"`
+	ValidBefore     string            `json:"valid_before"` // "forever" when unbounded
+	CriticalOptions map[string]string `json:"critical_options,omitempty"`
+	Extensions      []string          `json:"extensions,omitempty"`
+	CAKeyAlgorithm  string            `json:"ca_key_algorithm"`
+	CAFingerprint   string            `json:"ca_fingerprint"`
+	SignatureFormat string            `json:"signature_algorithm"`
+	Comment         string            `json:"comment,omitempty"`
+}
+
+// isSSHCertAlgo reports whether algo names an OpenSSH certificate, such as
+// "ssh-ed25519-cert-v01@openssh.com".
+func isSSHCertAlgo(algo string) bool {
+	return strings.HasSuffix(algo, "-cert-v01@openssh.com")
+}
+
+func isSSHCertBytes(data []byte) bool {
+	line, err := ReadFirstNonEmptyLineBytes(data)
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(line)
+	return len(fields) >= 2 && isSSHCertAlgo(fields[0])
+}
+
+func hasSSHCertMarker(path string) bool {
+	line, err := ReadFirstNonEmptyLine(path)
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(line)
+	return len(fields) >= 2 && isSSHCertAlgo(fields[0])
+}
+
+// ParseSSHCertificate parses an OpenSSH certificate line as written to
+// *-cert.pub files, returning the certificate and its trailing comment.
+func ParseSSHCertificate(data []byte) (*ssh.Certificate, string, error) {
+	pub, comment, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("parse ssh certificate: %w", err)
+	}
+	c, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, "", fmt.Errorf("not an ssh certificate: %s", pub.Type())
+	}
+	return c, comment, nil
+}
+
+func readSSHCertificate(path string) (*ssh.Certificate, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return ParseSSHCertificate(data)
+}
+
+// sshCertTime converts a cert-v01 validity bound, mapping the "forever"
+// sentinel to sshCertNoExpiry.
+func sshCertTime(v uint64) time.Time {
+	if v >= uint64(sshCertNoExpiry.Unix()) {
+		return sshCertNoExpiry
+	}
+	return time.Unix(int64(v), 0).UTC()
+}
+
+// sshCertDaysLeft returns the whole days from now until c expires, or nil
+// when c is valid forever. It counts in seconds rather than a
+// time.Duration, which cannot span more than about 292 years.
+func sshCertDaysLeft(c *ssh.Certificate, now time.Time) *int {
+	if c.ValidBefore == ssh.CertTimeInfinity {
+		return nil
+	}
+	days := int((sshCertTime(c.ValidBefore).Unix() - now.Unix()) / 86400)
+	return &days
+}
+
+func sshCertType(c *ssh.Certificate) string {
+	switch c.CertType {
+	case ssh.UserCert:
+		return "user"
+	case ssh.HostCert:
+		return "host"
+	default:
+		return "unknown (" + strconv.FormatUint(uint64(c.CertType), 10) + ")"
+	}
+}
+
+// NewSSHCertInfo describes c for display.
+func NewSSHCertInfo(c *ssh.Certificate, comment string) *SSHCertInfo {
+	info := &SSHCertInfo{
+		KeyID:           c.KeyId,
+		Type:            sshCertType(c),
+		Serial:          c.Serial,
+		KeyAlgorithm:    c.Key.Type(),
+		KeyFingerprint:  ssh.FingerprintSHA256(c.Key),
+		Principals:      append([]string{}, c.ValidPrincipals...),
+		ValidAfter:      sshCertTime(c.ValidAfter).Format(time.RFC3339),
+		ValidBefore:     ExpiryForever,
+		CAKeyAlgorithm:  c.SignatureKey.Type(),
+		CAFingerprint:   ssh.FingerprintSHA256(c.SignatureKey),
+		Comment:         comment,
+		CriticalOptions: c.CriticalOptions,
+	}
+	if c.ValidAfter == 0 {
+		info.ValidAfter = "always"
+	}
+	if c.ValidBefore != ssh.CertTimeInfinity {
+		info.ValidBefore = sshCertTime(c.ValidBefore).Format(time.RFC3339)
+	}
+	if c.Signature != nil {
+		info.SignatureFormat = c.Signature.Format
+	}
+	for name := range c.Extensions {
+		info.Extensions = append(info.Extensions, name)
+	}
+	sort.Strings(info.Extensions)
+	return info
+}
+
+func sshCertSummary(s *CertSummary, c *ssh.Certificate, comment string) {
+	info := NewSSHCertInfo(c, comment)
+	s.SSHCert = info
+	s.Subject = info.KeyID
+	s.NotBefore = info.ValidAfter
+	s.NotAfter = info.ValidBefore
+	s.Serial = strconv.FormatUint(c.Serial, 10)
+	s.PublicKeyAlgorithm = info.KeyAlgorithm
+	s.PublicKeyComment = comment
+}
+
+func nativeSSHCertSummary(path string) (*CertSummary, error) {
+	s := &CertSummary{File: path, FileType: FileTypeSSHCert}
+	c, comment, err := readSSHCertificate(path)
+	if err != nil {
+		return s, err
+	}
+	sshCertSummary(s, c, comment)
+	return s, nil
+}
+
+// RenderSSHCertText renders c in the layout of "ssh-keygen -L".
+func RenderSSHCertText(c *ssh.Certificate, comment string) string {
+	info := NewSSHCertInfo(c, comment)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Type: %s %s certificate\n", c.Type(), info.Type)
+	fmt.Fprintf(&b, "Public key: %s %s\n", info.KeyAlgorithm, info.KeyFingerprint)
+	fmt.Fprintf(&b, "Signing CA: %s %s (using %s)\n", info.CAKeyAlgorithm, info.CAFingerprint, info.SignatureFormat)
+	fmt.Fprintf(&b, "Key ID: %q\n", info.KeyID)
+	fmt.Fprintf(&b, "Serial: %d\n", info.Serial)
+	switch {
+	case c.ValidAfter == 0 && c.ValidBefore == ssh.CertTimeInfinity:
+		b.WriteString("Valid: forever\n")
+	case c.ValidBefore == ssh.CertTimeInfinity:
+		fmt.Fprintf(&b, "Valid: from %s\n", info.ValidAfter)
+	default:
+		fmt.Fprintf(&b, "Valid: from %s to %s\n", info.ValidAfter, info.ValidBefore)
+	}
+	b.WriteString("Principals:")
+	if len(info.Principals) == 0 {
+		b.WriteString(" (none)\n")
+	} else {
+		b.WriteString("\n")
+		for _, p := range info.Principals {
+			fmt.Fprintf(&b, "        %s\n", p)
+		}
+	}
+	b.WriteString("Critical Options:")
+	if len(c.CriticalOptions) == 0 {
+		b.WriteString(" (none)\n")
+	} else {
+		b.WriteString("\n")
+		names := make([]string, 0, len(c.CriticalOptions))
+		for name := range c.CriticalOptions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&b, "        %s %s\n", name, c.CriticalOptions[name])
+		}
+	}
+	b.WriteString("Extensions:")
+	if len(info.Extensions) == 0 {
+		b.WriteString(" (none)\n")
+	} else {
+		b.WriteString("\n")
+		for _, name := range info.Extensions {
+			if v := c.Extensions[name]; v != "" {
+				fmt.Fprintf(&b, "        %s %s\n", name, v)
+			} else {
+				fmt.Fprintf(&b, "        %s\n", name)
+			}
+		}
+	}
+	if comment != "" {
+		fmt.Fprintf(&b, "Comment: %s\n", comment)
+	}
+	return b.String()
+}
+
+// sshCertExpiryResult reports when c stops being valid. Certificates valid
+// forever report sshCertNoExpiry.
+func sshCertExpiryResult(c *ssh.Certificate, days int) *ExpiryResult {
+	now := time.Now()
+	notAfter := sshCertTime(c.ValidBefore)
+	r := &ExpiryResult{
+		ExpiryDate: formatOpenSSLTime(notAfter),
+		ExpiresAt:  notAfter,
+		Valid:      now.Add(time.Duration(days) * 24 * time.Hour).Before(notAfter),
+		Subject:    fmt.Sprintf("%s certificate %q", sshCertType(c), c.KeyId),
+	}
+	if left := sshCertDaysLeft(c, now); left != nil {
+		r.DaysLeft = *left
+	} else {
+		r.ExpiryDate = ExpiryForever
+	}
+	return r
+}
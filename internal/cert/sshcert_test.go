@@ -0,0 +1,171 @@
+package cert
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// writeTestSSHCert signs a fresh ed25519 key with a fresh CA and writes the
+// certificate as ssh-keygen would, returning its path and the CA key.
+func writeTestSSHCert(t *testing.T, name string, mutate func(*ssh.Certificate)) (string, ssh.PublicKey) {
+	t.Helper()
+	_, caPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caSigner, err := ssh.NewSignerFromKey(caPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(userPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &ssh.Certificate{
+		Key:             pub,
+		Serial:          42,
+		CertType:        ssh.UserCert,
+		KeyId:           "alice@corp",
+		ValidPrincipals: []string{"alice", "deploy"},
+		ValidAfter:      uint64(time.Now().Add(-time.Hour).Unix()),
+		ValidBefore:     uint64(time.Now().Add(10 * 24 * time.Hour).Unix()),
+		Permissions: ssh.Permissions{
+			CriticalOptions: map[string]string{"source-address": "10.0.0.0/8"},
+			Extensions:      map[string]string{"permit-pty": "", "permit-agent-forwarding": ""},
+		},
+	}
+	if mutate != nil {
+		mutate(c)
+	}
+	if err := c.SignCert(rand.Reader, caSigner); err != nil {
+		t.Fatal(err)
+	}
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(c))) + " alice@laptop\n"
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p, caSigner.PublicKey()
+}
+
+func TestSSHCert(t *testing.T) {
+	p, caPub := writeTestSSHCert(t, "id_ed25519-cert.pub", nil)
+	ft, err := DetectType(p)
+	if err != nil || ft != FileTypeSSHCert {
+		t.Fatalf("DetectType = %q, %v", ft, err)
+	}
+	data, _ := os.ReadFile(p)
+	if got := DetectTypeFromNameAndBytes("cert", data); got != FileTypeSSHCert {
+		t.Fatalf("DetectTypeFromNameAndBytes = %q", got)
+	}
+
+	// SSH certificates never shell out, even on the openssl backend.
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+	s, err := eng.Summary(ctx, p, "")
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	c := s.SSHCert
+	if c == nil || c.KeyID != "alice@corp" || c.Type != "user" || c.Serial != 42 || c.Comment != "alice@laptop" {
+		t.Fatalf("unexpected summary: %+v", c)
+	}
+	if strings.Join(c.Principals, ",") != "alice,deploy" || strings.Join(c.Extensions, ",") != "permit-agent-forwarding,permit-pty" {
+		t.Fatalf("unexpected principals/extensions: %+v", c)
+	}
+	if c.CriticalOptions["source-address"] != "10.0.0.0/8" || c.CAFingerprint != ssh.FingerprintSHA256(caPub) {
+		t.Fatalf("unexpected critical options/CA: %+v", c)
+	}
+
+	d, err := eng.Details(ctx, p, "")
+	if err != nil {
+		t.Fatalf("Details: %v", err)
+	}
+	for _, want := range []string{"user certificate", `Key ID: "alice@corp"`, "Serial: 42", "        deploy\n", "        source-address 10.0.0.0/8\n"} {
+		if !strings.Contains(d.RawText, want) {
+			t.Fatalf("Details missing %q:\n%s", want, d.RawText)
+		}
+	}
+
+	exp, err := eng.Expiry(ctx, p, 30)
+	if err != nil {
+		t.Fatalf("Expiry: %v", err)
+	}
+	if exp.Valid || exp.DaysLeft > 10 || exp.Subject != `user certificate "alice@corp"` {
+		t.Fatalf("unexpected expiry: %+v", exp)
+	}
+
+	records := ScanBytes(p, data, nil, time.Now())
+	if len(records) != 1 || records[0].Kind != ScanKindCertificate || records[0].DaysLeft == nil || len(records[0].SANs) != 2 {
+		t.Fatalf("unexpected scan records: %+v", records)
+	}
+}
+
+func TestSSHCert_HostForever(t *testing.T) {
+	p, _ := writeTestSSHCert(t, "ssh_host_ed25519_key-cert.pub", func(c *ssh.Certificate) {
+		c.CertType = ssh.HostCert
+		c.ValidAfter = 0
+		c.ValidBefore = ssh.CertTimeInfinity
+		c.Permissions = ssh.Permissions{}
+	})
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+	s, err := eng.Summary(ctx, p, "")
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if s.SSHCert.Type != "host" || s.SSHCert.ValidBefore != "forever" || s.SSHCert.ValidAfter != "always" {
+		t.Fatalf("unexpected summary: %+v", s.SSHCert)
+	}
+	exp, err := eng.Expiry(ctx, p, 30)
+	if err != nil {
+		t.Fatalf("Expiry: %v", err)
+	}
+	if !exp.Valid || exp.ExpiryDate != ExpiryForever {
+		t.Fatalf("unexpected expiry: %+v", exp)
+	}
+	d, err := eng.Details(ctx, p, "")
+	if err != nil || !strings.Contains(d.RawText, "Valid: forever") {
+		t.Fatalf("Details = %v:\n%s", err, d.RawText)
+	}
+
+	// Fleet reports and scans say "forever" too, with no days-left count.
+	report, err := eng.ExpiryReport(ctx, []string{p}, ExpiryReportOptions{ExpiryThresholds: ExpiryThresholds{WarnDays: 30, CritDays: 7}})
+	if err != nil {
+		t.Fatalf("ExpiryReport: %v", err)
+	}
+	if e := report.Entries[0]; e.Status != ExpiryOK || e.NotAfter != "forever" || e.DaysLeft != nil {
+		t.Fatalf("unexpected report entry: %+v", e)
+	}
+	data, _ := os.ReadFile(p)
+	if records := ScanBytes(p, data, nil, time.Now()); records[0].NotAfter != "forever" || records[0].DaysLeft != nil {
+		t.Fatalf("unexpected scan record: %+v", records[0])
+	}
+}
+
+func TestSSHCert_FarFutureDaysLeft(t *testing.T) {
+	// Beyond time.Duration's ~292-year range, but not "forever".
+	validBefore := time.Date(2500, 1, 1, 0, 0, 0, 0, time.UTC)
+	p, _ := writeTestSSHCert(t, "far-cert.pub", func(c *ssh.Certificate) {
+		c.ValidBefore = uint64(validBefore.Unix())
+	})
+	data, _ := os.ReadFile(p)
+	now := time.Now()
+	records := ScanBytes(p, data, nil, now)
+	want := int((validBefore.Unix() - now.Unix()) / 86400) // well over the 106751 a Duration allows
+	if got := records[0].DaysLeft; got == nil || *got != want {
+		t.Fatalf("DaysLeft = %v, want about %d", got, want)
+	}
+}
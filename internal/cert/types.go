@@ -6,15 +6,22 @@ import "time"
 type FileType string
 
 const (
-	FileTypeCert      FileType = "cert"
-	FileTypeKey       FileType = "key"
-	FileTypePublicKey FileType = "public-key"
-	FileTypeCombined  FileType = "combined"
-	FileTypePFX       FileType = "pfx"
-	FileTypeDER       FileType = "der"
-	FileTypeBase64    FileType = "base64"
-	FileTypeP7B       FileType = "p7b"
-	FileTypeUnknown   FileType = "unknown"
+	FileTypeCert       FileType = "cert"
+	FileTypeKey        FileType = "key"
+	FileTypePublicKey  FileType = "public-key"
+	FileTypeCombined   FileType = "combined"
+	FileTypePFX        FileType = "pfx"
+	FileTypeDER        FileType = "der"
+	FileTypeBase64     FileType = "base64"
+	FileTypeP7B        FileType = "p7b"
+	FileTypeJKS        FileType = "jks"
+	FileTypeCSR        FileType = "csr"
+	FileTypeCRL        FileType = "crl"
+	FileTypeK8sSecret  FileType = "k8s-secret"
+	FileTypeKubeconfig FileType = "kubeconfig"
+	FileTypeSSHCert    FileType = "ssh-cert"
+	FileTypeJWK        FileType = "jwk"
+	FileTypeUnknown    FileType = "unknown"
 )
 
 // KeyType represents the type of a private key.
@@ -24,6 +31,8 @@ const (
 	KeyTypeRSA   KeyType = "RSA"
 	KeyTypeEC    KeyType = "EC"
 	KeyTypePKCS8 KeyType = "PKCS#8"
+	// KeyTypeOpenSSH is the openssh-key-v1 format written by ssh-keygen.
+	KeyTypeOpenSSH KeyType = "OpenSSH"
 )
 
 // CertSummary holds the basic properties parsed from a certificate.
@@ -50,6 +59,53 @@ type CertSummary struct {
 	IsCA               bool
 	IsSelfSigned       bool
 	Fingerprint        string // SHA-256 of DER
+
+	// For certificate signing requests.
+	RequestedExtensions []string `json:",omitempty"`
+	SignatureValid      *bool    `json:",omitempty"` // CSR self-signature check
+
+	// For certificate revocation lists. Issuer and SignatureAlgorithm are
+	// shared with certificates.
+	ThisUpdate string            `json:",omitempty"`
+	NextUpdate string            `json:",omitempty"`
+	CRLNumber  string            `json:",omitempty"`
+	Revoked    []RevokedCertInfo `json:",omitempty"`
+
+	// For Java keystores: one entry per alias.
+	KeystoreType    string              `json:",omitempty"`
+	KeystoreEntries []KeystoreEntryInfo `json:",omitempty"`
+
+	// For Kubernetes Secret manifests: one entry per TLS Secret.
+	K8sSecrets []K8sSecretInfo `json:",omitempty"`
+
+	// For kubeconfigs: one entry per cluster CA and client certificate.
+	KubeconfigEntries []KubeconfigEntryInfo `json:",omitempty"`
+
+	// For OpenSSH certificates (*-cert.pub).
+	SSHCert *SSHCertInfo `json:",omitempty"`
+
+	// For OpenSSH private keys (KeyTypeOpenSSH).
+	OpenSSHKey *OpenSSHKeyInfo `json:",omitempty"`
+
+	// For JWK and JWK Set files: one entry per key.
+	JWKs []JWKInfo `json:",omitempty"`
+}
+
+// KeystoreEntryInfo describes one alias in a JKS/JCEKS keystore.
+type KeystoreEntryInfo struct {
+	Alias   string            `json:"alias"`
+	Type    KeystoreEntryType `json:"type"`
+	Created string            `json:"created"`
+	// Chain lists certificate subjects, leaf first.
+	Chain    []string `json:"chain,omitempty"`
+	NotAfter string   `json:"not_after,omitempty"`
+}
+
+// RevokedCertInfo is one entry in a CRL's revoked certificate list.
+type RevokedCertInfo struct {
+	Serial    string `json:"serial"`
+	RevokedAt string `json:"revoked_at"`
+	Reason    string `json:"reason,omitempty"`
 }
 
 // CertDetails holds the full text output from openssl x509 -text.
@@ -59,12 +115,17 @@ type CertDetails struct {
 	RawText  string
 }
 
+// ExpiryForever is the ExpiryDate reported for a certificate that never
+// expires, such as an OpenSSH certificate without a valid-before bound.
+const ExpiryForever = "forever"
+
 // ExpiryResult holds the result of an expiry check.
 type ExpiryResult struct {
 	ExpiryDate string
 	ExpiresAt  time.Time
 	DaysLeft   int
-	Valid      bool // true if cert is valid for the checked period
+	Valid      bool   // true if cert is valid for the checked period
+	Subject    string `json:",omitempty"`
 }
 
 // VerifyResult holds the result of a chain verification.
@@ -72,6 +133,65 @@ type VerifyResult struct {
 	Valid   bool
 	Output  string
 	Details string // additional diagnostic info
+
+	// Reason and FailingDepth locate a failure (depth 0 is the leaf). Both
+	// are unset when Valid, and FailingDepth is nil when openssl did not
+	// report one.
+	Reason       VerifyReason `json:",omitempty"`
+	FailingDepth *int         `json:",omitempty"`
+	// Chains lists the verified chains, leaf first. On failure it holds
+	// the best-effort path that was tried, which FailingDepth indexes. The
+	// openssl backend does not report chains.
+	Chains [][]VerifyChainCert `json:",omitempty"`
+}
+
+// VerifyReason is a machine-readable chain verification failure code.
+type VerifyReason string
+
+const (
+	VerifyReasonExpired              VerifyReason = "expired"
+	VerifyReasonNotYetValid          VerifyReason = "not_yet_valid"
+	VerifyReasonUnknownAuthority     VerifyReason = "unknown_authority"
+	VerifyReasonBadSignature         VerifyReason = "bad_signature"
+	VerifyReasonHostnameMismatch     VerifyReason = "hostname_mismatch"
+	VerifyReasonIncompatibleUsage    VerifyReason = "incompatible_usage"
+	VerifyReasonNotAuthorizedToSign  VerifyReason = "not_authorized_to_sign"
+	VerifyReasonNameConstraints      VerifyReason = "name_constraints"
+	VerifyReasonTooManyIntermediates VerifyReason = "too_many_intermediates"
+	VerifyReasonRevoked              VerifyReason = "revoked"
+	VerifyReasonCRLUnavailable       VerifyReason = "crl_unavailable"
+	VerifyReasonCRLExpired           VerifyReason = "crl_expired"
+	VerifyReasonOther                VerifyReason = "other"
+)
+
+// VerifyChainCert describes one certificate in a verification chain.
+type VerifyChainCert struct {
+	Subject   string `json:"subject"`
+	Issuer    string `json:"issuer"`
+	Serial    string `json:"serial"`
+	NotBefore string `json:"not_before"`
+	NotAfter  string `json:"not_after"`
+}
+
+// VerifyOptions adjusts a chain verification.
+type VerifyOptions struct {
+	// CRLFiles are local CRLs (PEM or DER) to check the leaf against. When
+	// set, a current CRL from the leaf's issuer must be among them.
+	CRLFiles []string
+
+	// The options below are only supported by the crypto/x509 verifier,
+	// which is used for them whatever the backend.
+
+	// Hostname must be covered by the leaf's SANs.
+	Hostname string
+	// Purpose is the required extended key usage: serverAuth, clientAuth
+	// or codeSigning. Empty accepts any.
+	Purpose string
+	// At checks validity at this time instead of now.
+	At time.Time
+	// IntermediatesFile holds untrusted intermediates, kept apart from the
+	// trusted roots in the CA file.
+	IntermediatesFile string
 }
 
 // MatchResult holds the result of a key-to-cert match check.
@@ -85,3 +205,23 @@ type FromPFXResult struct {
 	KeyFile  string
 	CAFile   string // empty if no CA certs found
 }
+
+// FromJKSResult holds the output paths from a JKS/JCEKS extraction.
+type FromJKSResult struct {
+	Entries []FromJKSEntry `json:"entries"`
+}
+
+// FromJKSEntry holds the files written for a single keystore alias.
+type FromJKSEntry struct {
+	Alias    string            `json:"alias"`
+	Type     KeystoreEntryType `json:"type"`
+	CertFile string            `json:"cert_file,omitempty"`
+	KeyFile  string            `json:"key_file,omitempty"`
+	CAFile   string            `json:"ca_file,omitempty"`
+}
+
+// ToKeystoreResult describes a keystore written by ToJKS or ToTruststore.
+type ToKeystoreResult struct {
+	Output  string   `json:"output"`
+	Aliases []string `json:"aliases"`
+}
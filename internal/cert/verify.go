@@ -2,15 +2,41 @@ package cert
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // VerifyChain verifies a certificate against a CA bundle.
 func (e *Engine) VerifyChain(ctx context.Context, certPath, caPath string) (*VerifyResult, error) {
-	stdout, stderr, err := e.exec.Run(ctx, "verify", "-CAfile", caPath, certPath)
+	return e.VerifyChainWithOptions(ctx, certPath, caPath, VerifyOptions{})
+}
+
+// VerifyChainWithOptions verifies a certificate against a CA bundle, with
+// optional offline revocation checking against local CRL files. Hostname,
+// purpose, point-in-time and separate intermediates checks always use the
+// crypto/x509 verifier, which also reports the chains it built.
+func (e *Engine) VerifyChainWithOptions(ctx context.Context, certPath, caPath string, opts VerifyOptions) (*VerifyResult, error) {
+	if e.native() || opts.needsNative() {
+		return nativeVerifyChain(certPath, caPath, opts)
+	}
+
+	args := []string{"verify"}
+	if len(opts.CRLFiles) > 0 {
+		args = append(args, "-crl_check")
+		for _, f := range opts.CRLFiles {
+			args = append(args, "-CRLfile", f)
+		}
+	}
+	args = append(args, "-CAfile", caPath, certPath)
+	stdout, stderr, err := e.exec.Run(ctx, args...)
 	output := string(stdout)
 	if len(stderr) > 0 {
 		output += string(stderr)
@@ -25,6 +51,8 @@ func (e *Engine) VerifyChain(ctx context.Context, certPath, caPath string) (*Ver
 		return result, nil
 	}
 
+	result.Reason, result.FailingDepth = parseOpenSSLVerifyError(output)
+
 	// Build diagnostic details
 	var details []string
 	if strings.Contains(output, "expired") || strings.Contains(output, "Expire") {
@@ -36,21 +64,68 @@ func (e *Engine) VerifyChain(ctx context.Context, certPath, caPath string) (*Ver
 	if strings.Contains(output, "self") && strings.Contains(output, "signed") {
 		details = append(details, "Certificate is self-signed")
 	}
+	if strings.Contains(output, "certificate revoked") {
+		details = append(details, opensslRevocationDetail(certPath, opts.CRLFiles))
+	}
+	if strings.Contains(output, "unable to get certificate CRL") {
+		details = append(details, "No CRL from the certificate's issuer in the supplied CRL files")
+	}
+	if strings.Contains(output, "CRL has expired") {
+		details = append(details, "CRL has expired")
+	}
 	result.Details = strings.Join(details, "; ")
 
 	return result, nil
 }
 
+// opensslRevocationDetail names the revoked serial and date by looking the
+// leaf up in the CRL files; openssl's own message says neither.
+func opensslRevocationDetail(certPath string, crlFiles []string) string {
+	const fallback = "Certificate has been revoked"
+	ft, err := DetectType(certPath)
+	if err != nil {
+		return fallback
+	}
+	certs, err := loadCertificates(certPath, ft, "")
+	if err != nil {
+		return fallback
+	}
+	crls, err := loadCRLFiles(crlFiles)
+	if err != nil {
+		return fallback
+	}
+	if entry := findRevocation(certs[0], crls); entry != nil {
+		return revocationDetail(certs[0], entry)
+	}
+	return fallback
+}
+
 // MatchKeyToCert checks whether a private key matches a certificate by comparing
-// their derived public keys (works for RSA and EC).
+// their derived public keys (works for RSA, EC and Ed25519). certPath may
+// also be a CSR, to confirm the request was generated from the key, or a PEM
+// or OpenSSH public key.
 //
 // keyPassword may be empty. We always provide an explicit -passin argument so
 // openssl does not try to prompt interactively in non-TTY contexts.
 func (e *Engine) MatchKeyToCert(ctx context.Context, certPath, keyPath string, keyPassword string) (*MatchResult, error) {
 	ft, _ := DetectType(certPath)
+	// openssl pkey reads neither OpenSSH private keys nor OpenSSH public
+	// key lines, so those are always compared natively.
+	if e.native() || ft == FileTypePublicKey || detectKeyType(keyPath) == KeyTypeOpenSSH {
+		if ft == FileTypeCSR {
+			return nativeMatchKeyToCSR(certPath, keyPath, keyPassword)
+		}
+		return nativeMatchKeyToCert(certPath, keyPath, keyPassword)
+	}
+
 	args := []string{"x509", "-in", certPath, "-pubkey", "-noout"}
-	if ft == FileTypeDER {
+	switch {
+	case ft == FileTypeDER:
 		args = []string{"x509", "-in", certPath, "-inform", "DER", "-pubkey", "-noout"}
+	case ft == FileTypeCSR && hasCSRMarker(certPath):
+		args = []string{"req", "-in", certPath, "-pubkey", "-noout"}
+	case ft == FileTypeCSR:
+		args = []string{"req", "-in", certPath, "-inform", "DER", "-pubkey", "-noout"}
 	}
 
 	certPub, certStderr, err := e.exec.Run(ctx, args...)
@@ -138,3 +213,165 @@ func ValidatePEMKey(path string) error {
 	}
 	return fmt.Errorf("not a PEM private key: %s", path)
 }
+
+// verifyReasonText is openssl's wording for each failure reason, used in
+// VerifyResult.Output by the crypto/x509 verifier where Go has no error of
+// its own.
+var verifyReasonText = map[VerifyReason]string{
+	VerifyReasonRevoked:        "certificate revoked",
+	VerifyReasonCRLUnavailable: "unable to get certificate CRL",
+	VerifyReasonCRLExpired:     "CRL has expired",
+}
+
+// opensslVerifyErrors maps openssl X509_V_ERR codes to reasons.
+var opensslVerifyErrors = map[int]VerifyReason{
+	2:  VerifyReasonUnknownAuthority, // unable to get issuer certificate
+	3:  VerifyReasonCRLUnavailable,
+	7:  VerifyReasonBadSignature,
+	9:  VerifyReasonNotYetValid,
+	10: VerifyReasonExpired,
+	11: VerifyReasonCRLUnavailable, // CRL is not yet valid
+	12: VerifyReasonCRLExpired,
+	18: VerifyReasonUnknownAuthority, // self-signed certificate
+	19: VerifyReasonUnknownAuthority, // self-signed certificate in chain
+	20: VerifyReasonUnknownAuthority, // unable to get local issuer certificate
+	21: VerifyReasonUnknownAuthority, // unable to verify the first certificate
+	23: VerifyReasonRevoked,
+	24: VerifyReasonNotAuthorizedToSign,  // invalid CA certificate
+	25: VerifyReasonTooManyIntermediates, // path length constraint exceeded
+	26: VerifyReasonIncompatibleUsage,
+	47: VerifyReasonNameConstraints, // permitted subtree violation
+	48: VerifyReasonNameConstraints, // excluded subtree violation
+	62: VerifyReasonHostnameMismatch,
+}
+
+var opensslVerifyErrorRE = regexp.MustCompile(`error (\d+) at (\d+) depth lookup`)
+
+// parseOpenSSLVerifyError extracts the first "error N at D depth lookup"
+// line from openssl verify output.
+func parseOpenSSLVerifyError(output string) (VerifyReason, *int) {
+	m := opensslVerifyErrorRE.FindStringSubmatch(output)
+	if m == nil {
+		return VerifyReasonOther, nil
+	}
+	code, _ := strconv.Atoi(m[1])
+	depth, _ := strconv.Atoi(m[2])
+	reason, ok := opensslVerifyErrors[code]
+	if !ok {
+		reason = VerifyReasonOther
+	}
+	return reason, &depth
+}
+
+// needsNative reports whether opts use features only the crypto/x509
+// verifier supports.
+func (o VerifyOptions) needsNative() bool {
+	return o.Hostname != "" || o.Purpose != "" || !o.At.IsZero() || o.IntermediatesFile != ""
+}
+
+var verifyPurposes = map[string]x509.ExtKeyUsage{
+	"serverauth":  x509.ExtKeyUsageServerAuth,
+	"clientauth":  x509.ExtKeyUsageClientAuth,
+	"codesigning": x509.ExtKeyUsageCodeSigning,
+}
+
+// ParseVerifyPurpose maps a purpose name (serverAuth, clientAuth or
+// codeSigning; case-insensitive) to an extended key usage.
+func ParseVerifyPurpose(s string) (x509.ExtKeyUsage, error) {
+	if eku, ok := verifyPurposes[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return eku, nil
+	}
+	return 0, fmt.Errorf("unknown purpose %q (want serverAuth, clientAuth or codeSigning)", s)
+}
+
+func failedVerifyResult(certPath string, reason VerifyReason, depth int, chain []*x509.Certificate, detail string) *VerifyResult {
+	r := &VerifyResult{
+		Output:       fmt.Sprintf("%s: verification failed: %s", certPath, verifyReasonText[reason]),
+		Details:      detail,
+		Reason:       reason,
+		FailingDepth: &depth,
+	}
+	if len(chain) > 0 {
+		r.Chains = [][]VerifyChainCert{describeVerifyChain(chain)}
+	}
+	return r
+}
+
+func describeVerifyChain(chain []*x509.Certificate) []VerifyChainCert {
+	out := make([]VerifyChainCert, 0, len(chain))
+	for _, c := range chain {
+		out = append(out, VerifyChainCert{
+			Subject:   opensslName(c.Subject),
+			Issuer:    opensslName(c.Issuer),
+			Serial:    opensslSerial(c.SerialNumber),
+			NotBefore: formatOpenSSLTime(c.NotBefore),
+			NotAfter:  formatOpenSSLTime(c.NotAfter),
+		})
+	}
+	return out
+}
+
+// bestEffortChain walks from leaf towards a root through candidates,
+// ignoring validity and usage, so a failure can be placed at a depth. It
+// stops at a self-signed certificate or when no issuer is found.
+func bestEffortChain(leaf *x509.Certificate, candidates []*x509.Certificate) []*x509.Certificate {
+	chain := []*x509.Certificate{leaf}
+	cur := leaf
+	for len(chain) < 10 && !bytes.Equal(cur.RawIssuer, cur.RawSubject) {
+		var next *x509.Certificate
+		for _, c := range candidates {
+			if bytes.Equal(c.RawSubject, cur.RawIssuer) && cur.CheckSignatureFrom(c) == nil {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		chain = append(chain, next)
+		cur = next
+	}
+	return chain
+}
+
+// classifyVerifyError maps a crypto/x509 verification error to a reason
+// and the depth in path of the certificate it concerns.
+func classifyVerifyError(err error, path []*x509.Certificate, at time.Time) (VerifyReason, int) {
+	depthOf := func(c *x509.Certificate) int {
+		for i, p := range path {
+			if c != nil && bytes.Equal(p.Raw, c.Raw) {
+				return i
+			}
+		}
+		return 0
+	}
+
+	var invalid x509.CertificateInvalidError
+	var unknown x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	switch {
+	case errors.As(err, &hostname):
+		return VerifyReasonHostnameMismatch, 0
+	case errors.As(err, &unknown):
+		// The leaf's chain ends at the last certificate we could link.
+		return VerifyReasonUnknownAuthority, len(path) - 1
+	case errors.As(err, &invalid):
+		depth := depthOf(invalid.Cert)
+		switch invalid.Reason {
+		case x509.Expired:
+			if c := path[depth]; at.Before(c.NotBefore) {
+				return VerifyReasonNotYetValid, depth
+			}
+			return VerifyReasonExpired, depth
+		case x509.IncompatibleUsage, x509.CANotAuthorizedForExtKeyUsage:
+			return VerifyReasonIncompatibleUsage, depth
+		case x509.NotAuthorizedToSign:
+			return VerifyReasonNotAuthorizedToSign, depth
+		case x509.TooManyIntermediates:
+			return VerifyReasonTooManyIntermediates, depth
+		case x509.CANotAuthorizedForThisName, x509.NameConstraintsWithoutSANs, x509.UnconstrainedName, x509.TooManyConstraints:
+			return VerifyReasonNameConstraints, depth
+		}
+	}
+	return VerifyReasonOther, 0
+}
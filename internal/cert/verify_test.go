@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/nickromney/certconv/test/testutil"
 )
@@ -40,6 +41,67 @@ func TestVerifyChain_WrongCA(t *testing.T) {
 	}
 }
 
+func TestVerifyChainWithOptions_Structured(t *testing.T) {
+	set := testutil.MakeChain(t)
+	// The native options route to crypto/x509 on either backend.
+	eng := NewEngine(refusingExec{t: t})
+	ctx := context.Background()
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name      string
+		opts      VerifyOptions
+		wantValid bool
+		reason    VerifyReason
+		depth     int
+	}{
+		{"valid", VerifyOptions{IntermediatesFile: set.IntermediatePath, Hostname: "app.test.local", Purpose: "serverAuth"}, true, "", 0},
+		{"missing intermediate", VerifyOptions{Hostname: "app.test.local"}, false, VerifyReasonUnknownAuthority, 0},
+		{"hostname", VerifyOptions{IntermediatesFile: set.IntermediatePath, Hostname: "other.test.local"}, false, VerifyReasonHostnameMismatch, 0},
+		{"purpose", VerifyOptions{IntermediatesFile: set.IntermediatePath, Purpose: "clientAuth"}, false, VerifyReasonIncompatibleUsage, 0},
+		{"intermediate expired", VerifyOptions{IntermediatesFile: set.IntermediatePath, At: time.Now().Add(75 * day)}, false, VerifyReasonExpired, 1},
+		{"leaf expired", VerifyOptions{IntermediatesFile: set.IntermediatePath, At: time.Now().Add(100 * day)}, false, VerifyReasonExpired, 0},
+		{"leaf not yet valid", VerifyOptions{IntermediatesFile: set.IntermediatePath, At: time.Now().Add(-2 * time.Hour)}, false, VerifyReasonNotYetValid, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := eng.VerifyChainWithOptions(ctx, set.LeafPath, set.RootPath, tc.opts)
+			if err != nil {
+				t.Fatalf("VerifyChainWithOptions() error = %v", err)
+			}
+			if r.Valid != tc.wantValid || r.Reason != tc.reason {
+				t.Fatalf("result = %+v, want valid=%v reason=%q", r, tc.wantValid, tc.reason)
+			}
+			if len(r.Chains) == 0 {
+				t.Fatalf("expected a chain in %+v", r)
+			}
+			if tc.wantValid {
+				if r.FailingDepth != nil || len(r.Chains[0]) != 3 || r.Chains[0][2].Subject != "CN = CertConv Test Root" {
+					t.Errorf("valid result = %+v", r)
+				}
+				return
+			}
+			if r.FailingDepth == nil || *r.FailingDepth != tc.depth {
+				t.Errorf("FailingDepth = %v, want %d", r.FailingDepth, tc.depth)
+			}
+		})
+	}
+
+	if _, err := eng.VerifyChainWithOptions(ctx, set.LeafPath, set.RootPath, VerifyOptions{Purpose: "emailProtection"}); err == nil {
+		t.Error("expected error for unknown purpose")
+	}
+}
+
+func TestParseOpenSSLVerifyError(t *testing.T) {
+	reason, depth := parseOpenSSLVerifyError("CN = x\nerror 10 at 1 depth lookup: certificate has expired\nerror x.pem: verification failed")
+	if reason != VerifyReasonExpired || depth == nil || *depth != 1 {
+		t.Errorf("got %q, %v", reason, depth)
+	}
+	if reason, depth := parseOpenSSLVerifyError("garbage"); reason != VerifyReasonOther || depth != nil {
+		t.Errorf("got %q, %v", reason, depth)
+	}
+}
+
 func TestMatchKeyToCert_Match(t *testing.T) {
 	pair := testutil.MakeCertPair(t)
 	eng := NewDefaultEngine()
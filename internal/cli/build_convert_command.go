@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/nickromney/certconv/internal/cert"
@@ -97,6 +98,171 @@ func buildToPFXCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.
 	return cmd
 }
 
+func buildToJKSCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
+	var password, ca, keyPassword, alias string
+	var passwordStdin, keyPasswordStdin bool
+	var passwordFile, keyPasswordFile string
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "to-jks CERT KEY OUTPUT",
+		Short: "Convert PEM cert + key to a Java keystore (JKS)",
+		Long: `Create a JKS keystore with a single private-key entry.
+
+The key is protected with the keystore password (keytool's default). The
+alias defaults to the certificate's subject CN; Java looks aliases up in
+lower case, so aliases are always stored lower-cased.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resolvedArgs, err := resolveInputArgs(cmd, args, 3, pathInput)
+			if err != nil {
+				return err
+			}
+			args = resolvedArgs
+
+			// stdin can only be consumed once. Disallow sourcing both secrets from stdin.
+			pwFromStdin := passwordStdin || strings.TrimSpace(passwordFile) == "-"
+			kpwFromStdin := keyPasswordStdin || strings.TrimSpace(keyPasswordFile) == "-"
+			if pwFromStdin && kpwFromStdin {
+				return &ExitError{Code: 2, Msg: "only one secret may be read from stdin; use --password-file for one secret and --key-password-file for the other"}
+			}
+
+			inlineExportProvided := strings.TrimSpace(password) != ""
+			inlineKeyProvided := strings.TrimSpace(keyPassword) != ""
+			pw, err := loadSecret(cmd, password, passwordStdin, passwordFile, "password", "password-stdin", "password-file")
+			if err != nil {
+				return err
+			}
+			password = pw
+			kpw, err := loadSecret(cmd, keyPassword, keyPasswordStdin, keyPasswordFile, "key-password", "key-password-stdin", "key-password-file")
+			if err != nil {
+				return err
+			}
+			keyPassword = kpw
+			if inlineExportProvided && strings.TrimSpace(password) != "" && !passwordStdin && strings.TrimSpace(passwordFile) == "" {
+				warnInlineSecretFlag("password")
+			}
+			if inlineKeyProvided && strings.TrimSpace(keyPassword) != "" && !keyPasswordStdin && strings.TrimSpace(keyPasswordFile) == "" {
+				warnInlineSecretFlag("key-password")
+			}
+			if password == "" {
+				return &ExitError{Code: 2, Msg: "a keystore password is required (--password, --password-stdin, or --password-file)"}
+			}
+
+			certPath := resolvePath(args[0])
+			keyPath := resolvePath(args[1])
+			output := args[2]
+			if err := requireFile(certPath); err != nil {
+				return err
+			}
+			if err := requireFile(keyPath); err != nil {
+				return err
+			}
+
+			caPath := ""
+			if ca != "" {
+				caPath = resolvePath(ca)
+				if err := requireFile(caPath); err != nil {
+					return err
+				}
+			}
+
+			if !jsonOut {
+				step("Creating keystore...")
+			}
+			result, err := engine.ToJKS(context.Background(), certPath, keyPath, output, password, caPath, keyPassword, alias)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetEscapeHTML(false)
+				return enc.Encode(result)
+			}
+			success(fmt.Sprintf("Created: %s (alias: %s)", result.Output, strings.Join(result.Aliases, ", ")))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&password, "password", "p", "", "Keystore password")
+	cmd.Flags().StringVar(&keyPassword, "key-password", "", "Private key password (for encrypted keys)")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read keystore password from stdin")
+	cmd.Flags().BoolVar(&keyPasswordStdin, "key-password-stdin", false, "Read private key password from stdin")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "Read keystore password from file (use '-' for stdin)")
+	cmd.Flags().StringVar(&keyPasswordFile, "key-password-file", "", "Read private key password from file (use '-' for stdin)")
+	cmd.Flags().StringVarP(&ca, "ca", "a", "", "CA bundle file")
+	cmd.Flags().StringVar(&alias, "alias", "", "Entry alias (default: certificate subject CN)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func buildToTruststoreCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
+	var password string
+	var passwordStdin bool
+	var passwordFile string
+	var aliases []string
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "to-truststore BUNDLE OUTPUT",
+		Short: "Convert a CA bundle to a Java truststore (JKS)",
+		Long: `Create a JKS truststore with one trusted-certificate entry per certificate
+in BUNDLE (PEM, DER, or P7B).
+
+Aliases default to each certificate's subject CN (lower-cased, with a numeric
+suffix when two certificates share a CN). Pass --alias once per certificate,
+in bundle order, to set them explicitly.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resolvedArgs, err := resolveInputArgs(cmd, args, 2, pathInput)
+			if err != nil {
+				return err
+			}
+			args = resolvedArgs
+
+			inlineProvided := strings.TrimSpace(password) != ""
+			pw, err := loadSecret(cmd, password, passwordStdin, passwordFile, "password", "password-stdin", "password-file")
+			if err != nil {
+				return err
+			}
+			password = pw
+			if inlineProvided && strings.TrimSpace(password) != "" && !passwordStdin && strings.TrimSpace(passwordFile) == "" {
+				warnInlineSecretFlag("password")
+			}
+			if password == "" {
+				return &ExitError{Code: 2, Msg: "a truststore password is required (--password, --password-stdin, or --password-file)"}
+			}
+
+			bundle := resolvePath(args[0])
+			output := args[1]
+			if err := requireFile(bundle); err != nil {
+				return err
+			}
+
+			if !jsonOut {
+				step("Creating truststore...")
+			}
+			result, err := engine.ToTruststore(context.Background(), bundle, output, password, aliases)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetEscapeHTML(false)
+				return enc.Encode(result)
+			}
+			success(fmt.Sprintf("Created: %s (%d certificates)", result.Output, len(result.Aliases)))
+			for _, a := range result.Aliases {
+				kv("Alias", a)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&password, "password", "p", "", "Truststore password")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read truststore password from stdin")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "Read truststore password from file (use '-' for stdin)")
+	cmd.Flags().StringArrayVar(&aliases, "alias", nil, "Entry alias, once per certificate in bundle order (default: subject CN)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
 func buildFromPFXCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
 	var password string
 	var passwordStdin bool
@@ -156,6 +322,107 @@ func buildFromPFXCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobr
 	return cmd
 }
 
+func buildFromJKSCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
+	var password string
+	var passwordStdin bool
+	var passwordFile string
+	var keyPassword string
+	var keyPasswordStdin bool
+	var keyPasswordFile string
+	var alias string
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "from-jks INPUT OUTDIR",
+		Short: "Extract PEM from a Java keystore (JKS/JCEKS)",
+		Long: `Extract PEM files from a JKS or JCEKS keystore.
+
+Private-key entries produce a certificate, PKCS#8 private key and (when the
+chain has intermediates) a CA bundle, named like from-pfx output. Trusted
+certificate entries produce a single .crt file. When the keystore holds more
+than one entry, each file name is suffixed with the entry alias; use --alias
+to extract a single entry.
+
+The key password defaults to the store password, as keytool does.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resolvedArgs, err := resolveInputArgs(cmd, args, 2, pathInput)
+			if err != nil {
+				return err
+			}
+			args = resolvedArgs
+
+			pwFromStdin := passwordStdin || strings.TrimSpace(passwordFile) == "-"
+			kpwFromStdin := keyPasswordStdin || strings.TrimSpace(keyPasswordFile) == "-"
+			if pwFromStdin && kpwFromStdin {
+				return &ExitError{Code: 2, Msg: "only one secret may be read from stdin; use --password-file for one secret and --key-password-file for the other"}
+			}
+
+			inlineProvided := strings.TrimSpace(password) != ""
+			inlineKeyProvided := strings.TrimSpace(keyPassword) != ""
+			pw, err := loadSecret(cmd, password, passwordStdin, passwordFile, "password", "password-stdin", "password-file")
+			if err != nil {
+				return err
+			}
+			password = pw
+			kpw, err := loadSecret(cmd, keyPassword, keyPasswordStdin, keyPasswordFile, "key-password", "key-password-stdin", "key-password-file")
+			if err != nil {
+				return err
+			}
+			keyPassword = kpw
+			if inlineProvided && strings.TrimSpace(password) != "" && !passwordStdin && strings.TrimSpace(passwordFile) == "" {
+				warnInlineSecretFlag("password")
+			}
+			if inlineKeyProvided && strings.TrimSpace(keyPassword) != "" && !keyPasswordStdin && strings.TrimSpace(keyPasswordFile) == "" {
+				warnInlineSecretFlag("key-password")
+			}
+
+			input := resolvePath(args[0])
+			outDir := args[1]
+			if err := requireFile(input); err != nil {
+				return err
+			}
+
+			if !jsonOut {
+				step("Extracting from keystore...")
+			}
+			result, err := engine.FromJKS(context.Background(), input, outDir, password, keyPassword, alias)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetEscapeHTML(false)
+				return enc.Encode(result)
+			}
+			for _, e := range result.Entries {
+				switch e.Type {
+				case cert.KeystoreEntrySecretKey:
+					warn(fmt.Sprintf("%s: secret key entry skipped (no PEM form)", e.Alias))
+					continue
+				case cert.KeystoreEntryTrustedCert:
+					success(fmt.Sprintf("%s: Trusted certificate: %s", e.Alias, e.CertFile))
+					continue
+				}
+				success(fmt.Sprintf("%s: Certificate: %s", e.Alias, e.CertFile))
+				success(fmt.Sprintf("%s: Private key: %s", e.Alias, e.KeyFile))
+				if e.CAFile != "" {
+					success(fmt.Sprintf("%s: CA bundle: %s", e.Alias, e.CAFile))
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&password, "password", "p", "", "Keystore password")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read keystore password from stdin")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "Read keystore password from file (use '-' for stdin)")
+	cmd.Flags().StringVar(&keyPassword, "key-password", "", "Private key password (default: keystore password)")
+	cmd.Flags().BoolVar(&keyPasswordStdin, "key-password-stdin", false, "Read private key password from stdin")
+	cmd.Flags().StringVar(&keyPasswordFile, "key-password-file", "", "Read private key password from file (use '-' for stdin)")
+	cmd.Flags().StringVar(&alias, "alias", "", "Extract only this alias")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
 func buildToDERCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
 	var isKey bool
 	var keyPassword string
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nickromney/certconv/internal/cert"
+	"github.com/spf13/cobra"
+)
+
+func buildCoversCommand(pathInput *pathInputOptions) *cobra.Command {
+	var password string
+	var passwordStdin bool
+	var passwordFile string
+	var legacyCN, jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "covers CERT HOST...",
+		Short: "Check which hostnames and IPs a certificate covers",
+		Long: `Check each HOST (a hostname or IP address) against the certificate's
+Subject Alternative Names, following RFC 6125:
+
+  - names compare case-insensitively, ignoring a trailing dot
+  - a wildcard must be the whole leftmost label (*.example.com) and matches
+    exactly one label: not example.com, not a.b.example.com
+  - IP addresses match IP SANs only, never DNS SANs or wildcards
+  - the subject CN is ignored unless --legacy-cn is given, and even then
+    only for certificates without DNS SANs
+
+Each host is reported with the SAN that matched it, or why nothing did.
+CERT may be a PEM or DER certificate, bundle, PFX, P7B or keystore; the
+first certificate is checked.
+
+Exit codes: 0 = every host covered, 1 = at least one is not.
+Pure Go — no external tools required.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			args, err := collectInputArgs(cmd, args, pathInput)
+			if err != nil {
+				return err
+			}
+			if len(args) < 2 {
+				return &ExitError{Code: 2, Msg: fmt.Sprintf("%s requires a certificate and at least 1 host", cmd.CommandPath())}
+			}
+
+			inlineProvided := strings.TrimSpace(password) != ""
+			pw, err := loadSecret(cmd, password, passwordStdin, passwordFile, "password", "password-stdin", "password-file")
+			if err != nil {
+				return err
+			}
+			password = pw
+			if inlineProvided && strings.TrimSpace(password) != "" && !passwordStdin && strings.TrimSpace(passwordFile) == "" {
+				warnInlineSecretFlag("password")
+			}
+
+			path := resolvePath(args[0])
+			if err := requireFile(path); err != nil {
+				return err
+			}
+			result, err := cert.CoversFile(path, password, args[1:], legacyCN)
+			if err != nil {
+				return fmt.Errorf("covers: %w", err)
+			}
+
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetEscapeHTML(false)
+				if err := enc.Encode(result); err != nil {
+					return err
+				}
+			} else {
+				printCoversResult(result)
+			}
+			if !result.Covered {
+				return &ExitError{Code: 1, Silent: true}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&legacyCN, "legacy-cn", false, "Fall back to the subject CN for certificates without DNS SANs")
+	cmd.Flags().StringVarP(&password, "password", "p", "", "PFX or keystore password")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read PFX or keystore password from stdin")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "Read PFX or keystore password from file (use '-' for stdin)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func printCoversResult(r *cert.CoversResult) {
+	fmt.Fprintln(outStdout)
+	kv("Subject", r.Subject)
+	sans := strings.Join(r.SANs, ", ")
+	if sans == "" {
+		sans = "(none)"
+	}
+	kv("SANs", sans)
+	fmt.Fprintln(outStdout)
+	for _, h := range r.Hosts {
+		if h.Covered {
+			success(fmt.Sprintf("%s: covered by %s", h.Host, h.MatchedBy))
+		} else {
+			errMsg(fmt.Sprintf("%s: not covered (%s)", h.Host, h.Reason))
+		}
+	}
+	fmt.Fprintln(outStdout)
+}
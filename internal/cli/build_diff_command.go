@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nickromney/certconv/internal/cert"
+	"github.com/spf13/cobra"
+)
+
+func buildDiffCommand(pathInput *pathInputOptions) *cobra.Command {
+	var password string
+	var passwordStdin bool
+	var passwordFile string
+	var requireSANSuperset, jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "Show what changed between two certificates",
+		Long: `Compare two certificates, typically a certificate and its renewal: subject,
+issuer, serial, key algorithm and size, whether the key was reused, signature
+algorithm, validity, key usage, basic constraints, and the SANs, EKUs and
+extensions added or removed.
+
+OLD and NEW may be PEM or DER certificates, bundles, PFX, P7B or keystores;
+the leaf of each is compared. --password applies to either file.
+
+With --require-san-superset the command exits 1 when NEW dropped a SAN that
+OLD had. Pure Go — no external tools required.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resolvedArgs, err := resolveInputArgs(cmd, args, 2, pathInput)
+			if err != nil {
+				return err
+			}
+
+			inlineProvided := strings.TrimSpace(password) != ""
+			pw, err := loadSecret(cmd, password, passwordStdin, passwordFile, "password", "password-stdin", "password-file")
+			if err != nil {
+				return err
+			}
+			password = pw
+			if inlineProvided && strings.TrimSpace(password) != "" && !passwordStdin && strings.TrimSpace(passwordFile) == "" {
+				warnInlineSecretFlag("password")
+			}
+
+			oldPath, newPath := resolvePath(resolvedArgs[0]), resolvePath(resolvedArgs[1])
+			for _, p := range []string{oldPath, newPath} {
+				if err := requireFile(p); err != nil {
+					return err
+				}
+			}
+			d, err := cert.DiffFiles(oldPath, newPath, password)
+			if err != nil {
+				return fmt.Errorf("diff: %w", err)
+			}
+
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetEscapeHTML(false)
+				if err := enc.Encode(d); err != nil {
+					return err
+				}
+			} else {
+				printCertDiff(d)
+			}
+			if requireSANSuperset && !d.SANSuperset() {
+				if !jsonOut {
+					errMsg(fmt.Sprintf("New certificate dropped %d SAN(s): %s", len(d.SANsRemoved), strings.Join(d.SANsRemoved, ", ")))
+				}
+				return &ExitError{Code: 1, Silent: true}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&requireSANSuperset, "require-san-superset", false, "Exit 1 if NEW does not keep every SAN of OLD")
+	cmd.Flags().StringVarP(&password, "password", "p", "", "PFX or keystore password")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read PFX or keystore password from stdin")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "Read PFX or keystore password from file (use '-' for stdin)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+// printCertDiff prints unchanged attributes plainly, changed ones marked "~"
+// with old → new, and set entries added ("+") or removed ("-").
+func printCertDiff(d *cert.CertDiff) {
+	fmt.Fprintln(outStdout)
+	kv("Old", d.OldFile)
+	kv("New", d.NewFile)
+	fmt.Fprintln(outStdout)
+	if d.Identical {
+		success("Certificates are identical")
+		fmt.Fprintln(outStdout)
+		return
+	}
+
+	arrow := sym("→", "->")
+	for _, f := range d.Fields {
+		if !f.Changed {
+			kv(f.Name, f.Old)
+			continue
+		}
+		diffLine("~", "\033[0;33m", fmt.Sprintf("%s: %s %s %s", f.Name, f.Old, arrow, f.New))
+	}
+	if d.SameKey {
+		kv("Key Reused", "yes (same public key)")
+	} else {
+		diffLine("~", "\033[0;33m", "Key Reused: no (new key pair)")
+	}
+
+	sets := []struct {
+		name           string
+		added, removed []string
+	}{
+		{"SANs", d.SANsAdded, d.SANsRemoved},
+		{"Extended Key Usage", d.EKUsAdded, d.EKUsRemoved},
+		{"Extensions", d.ExtensionsAdded, d.ExtensionsRemoved},
+	}
+	for _, s := range sets {
+		if len(s.added) == 0 && len(s.removed) == 0 {
+			kv(s.name, "unchanged")
+			continue
+		}
+		fmt.Fprintf(outStdout, "  %s%s:%s\n", colorSeq("\033[1m"), s.name, colorSeq("\033[0m"))
+		for _, v := range s.added {
+			diffLine("+", "\033[0;32m", "  "+v)
+		}
+		for _, v := range s.removed {
+			diffLine("-", "\033[0;31m", "  "+v)
+		}
+	}
+	fmt.Fprintln(outStdout)
+}
+
+func diffLine(marker, color, text string) {
+	fmt.Fprintf(outStdout, "%s%s %s%s\n", colorSeq(color), marker, text, colorSeq("\033[0m"))
+}
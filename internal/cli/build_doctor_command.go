@@ -37,6 +37,9 @@ Reports the status and version of each optional dependency:
   openssl   — certificate inspection, conversion, PFX, DER, P7B
   fzf       — TUI fuzzy file picker
 
+When openssl is missing, certconv falls back to its built-in Go backend
+(see --backend).
+
 Works on macOS, Linux, and Windows/WSL2.`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -64,6 +67,9 @@ Works on macOS, Linux, and Windows/WSL2.`,
 			}
 
 			fmt.Fprintln(outStdout)
+			if !openSSLFound(checks) {
+				info("openssl not found; using the built-in Go backend (--backend go)")
+			}
 			if allFound {
 				success("All external tools available")
 			} else {
@@ -83,6 +89,15 @@ func runToolChecks() []toolCheck {
 	}
 }
 
+func openSSLFound(checks []toolCheck) bool {
+	for _, c := range checks {
+		if c.Name == "openssl" {
+			return c.Found
+		}
+	}
+	return false
+}
+
 func checkTool(name string, versionArgs []string, features string) toolCheck {
 	tc := toolCheck{Name: name, Features: features}
 	path, err := lookPathFn(name)
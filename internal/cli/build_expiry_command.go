@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nickromney/certconv/internal/cert"
+	"github.com/spf13/cobra"
+)
+
+func buildExpiryCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
+	var days, warnDays, critDays int
+	var recursive, jsonOut bool
+	var promFile string
+	cmd := &cobra.Command{
+		Use:   "expiry PATH...",
+		Short: "Check certificate expiration",
+		Long: `Check when certificates expire.
+
+With a single file and only --days, reports whether the certificate is valid
+for at least that many more days (exit 1 if not).
+
+Given several files, a directory, or any of --warn, --crit, --recursive or
+--prometheus, expiry checks every certificate file and exits like a Nagios
+plugin, with the worst state found:
+
+  0 OK        every certificate is valid beyond --warn days
+  1 WARNING   a certificate expires within --warn days
+  2 CRITICAL  a certificate expires within --crit days, or has expired
+  3 UNKNOWN   a file could not be read, or the thresholds are invalid
+
+--warn defaults to --days, and --crit to 7 (or --warn, if lower). Directories
+are searched for certificate files (PEM, DER, PFX, P7B, JKS); hidden entries
+are skipped. PFX files are read with an empty password.
+
+--prometheus FILE writes certconv_cert_not_after_seconds{path,subject} gauges
+for the node_exporter textfile collector. FILE is replaced atomically on each
+run.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths, err := resolveInputPaths(cmd, args, pathInput)
+			if err != nil {
+				return err
+			}
+			for i, p := range paths {
+				paths[i] = resolvePath(p)
+			}
+
+			fleet := len(paths) > 1 || recursive || promFile != "" ||
+				cmd.Flags().Changed("warn") || cmd.Flags().Changed("crit")
+			if !fleet {
+				if info, err := os.Stat(paths[0]); err == nil && info.IsDir() {
+					fleet = true
+				}
+			}
+			if !fleet {
+				return runSingleExpiry(cmd, engine, paths[0], days, jsonOut)
+			}
+
+			if !cmd.Flags().Changed("warn") {
+				warnDays = days
+			}
+			if !cmd.Flags().Changed("crit") {
+				critDays = min(critDays, warnDays)
+			}
+			report, err := engine.ExpiryReport(context.Background(), paths, cert.ExpiryReportOptions{
+				ExpiryThresholds: cert.ExpiryThresholds{WarnDays: warnDays, CritDays: critDays},
+				Recursive:        recursive,
+			})
+			if err != nil {
+				return &ExitError{Code: cert.ExpiryUnknown.ExitCode(), Msg: err.Error()}
+			}
+			if promFile != "" {
+				if err := writePrometheusTextfile(expandHomePath(promFile), report); err != nil {
+					return &ExitError{Code: cert.ExpiryUnknown.ExitCode(), Msg: "write prometheus file: " + err.Error()}
+				}
+			}
+
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetEscapeHTML(false)
+				if err := enc.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				printExpiryReport(cmd.OutOrStdout(), report)
+			}
+			if code := report.Status.ExitCode(); code != 0 {
+				return &ExitError{Code: code, Silent: true}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&days, "days", 30, "Number of days to check")
+	cmd.Flags().IntVar(&warnDays, "warn", 30, "WARNING when a certificate expires within this many days (default --days)")
+	cmd.Flags().IntVar(&critDays, "crit", 7, "CRITICAL when a certificate expires within this many days")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Descend into subdirectories of directory arguments")
+	cmd.Flags().StringVar(&promFile, "prometheus", "", "Write a Prometheus textfile-collector file")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func runSingleExpiry(cmd *cobra.Command, engine *cert.Engine, path string, days int, jsonOut bool) error {
+	if err := requireFile(path); err != nil {
+		return err
+	}
+
+	result, err := engine.Expiry(context.Background(), path, days)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+		if !result.Valid {
+			return &ExitError{Code: 1, Silent: true}
+		}
+		return nil
+	}
+
+	info("Expiration: " + result.ExpiryDate)
+	if result.ExpiryDate == cert.ExpiryForever {
+		success("Certificate never expires")
+		return nil
+	}
+	if result.Valid {
+		success("Certificate valid for at least " + strconv.Itoa(days) + " more days")
+		return nil
+	}
+	warn("Certificate expires within " + strconv.Itoa(days) + " days (or already expired)")
+	return fmt.Errorf("certificate expiring")
+}
+
+// printExpiryReport writes a Nagios-style status line followed by one line
+// per file.
+func printExpiryReport(w io.Writer, r *cert.ExpiryReport) {
+	var counts []string
+	for _, s := range []cert.ExpiryStatus{cert.ExpiryCritical, cert.ExpiryWarning, cert.ExpiryUnknown, cert.ExpiryOK} {
+		if n := r.Counts[s]; n > 0 || s == cert.ExpiryOK {
+			counts = append(counts, fmt.Sprintf("%d %s", n, strings.ToLower(string(s))))
+		}
+	}
+	fmt.Fprintf(w, "EXPIRY %s - %s (warn %dd, crit %dd)\n", r.Status, strings.Join(counts, ", "), r.WarnDays, r.CritDays)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, e := range r.Entries {
+		if e.Error != "" {
+			fmt.Fprintf(tw, "%s\t\t\t%s\t%s\n", e.Status, e.Path, e.Error)
+			continue
+		}
+		days := ""
+		if e.DaysLeft != nil {
+			days = strconv.Itoa(*e.DaysLeft) + "d"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.Status, days, formatSummaryTimestamp(e.NotAfter), e.Path, e.Subject)
+	}
+	_ = tw.Flush()
+}
+
+// writePrometheusTextfile replaces path with the report's gauges. The file
+// is written beside path and renamed into place, so the collector never
+// reads a partial file.
+func writePrometheusTextfile(path string, r *cert.ExpiryReport) error {
+	var b strings.Builder
+	b.WriteString("# HELP certconv_cert_not_after_seconds Certificate expiry (notAfter) as a Unix timestamp.\n")
+	b.WriteString("# TYPE certconv_cert_not_after_seconds gauge\n")
+	for _, e := range r.Entries {
+		if e.Error == "" {
+			fmt.Fprintf(&b, "certconv_cert_not_after_seconds{path=\"%s\",subject=\"%s\"} %d\n",
+				promLabelValue(e.Path), promLabelValue(e.Subject), e.ExpiresAt.Unix())
+		}
+	}
+	b.WriteString("# HELP certconv_cert_read_error Whether certconv could not read a certificate file.\n")
+	b.WriteString("# TYPE certconv_cert_read_error gauge\n")
+	for _, e := range r.Entries {
+		v := 0
+		if e.Error != "" {
+			v = 1
+		}
+		fmt.Fprintf(&b, "certconv_cert_read_error{path=\"%s\"} %d\n", promLabelValue(e.Path), v)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// promLabelValue escapes a label value for the Prometheus text format.
+func promLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nickromney/certconv/internal/cert"
+	"github.com/spf13/cobra"
+)
+
+// jwkFlags are the options shared by to-jwk and to-jwks.
+type jwkFlags struct {
+	includePrivate   bool
+	use, alg         string
+	keyPassword      string
+	keyPasswordStdin bool
+	keyPasswordFile  string
+	jsonOut          bool
+}
+
+func (f *jwkFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.includePrivate, "include-private", false, "Include the private key (d, p, q, ...) in the output")
+	cmd.Flags().StringVar(&f.use, "use", "", `Public key use: "sig" or "enc" (omitted by default)`)
+	cmd.Flags().StringVar(&f.alg, "alg", "", "Intended algorithm, e.g. RS256 or ES256 (omitted by default)")
+	cmd.Flags().StringVar(&f.keyPassword, "key-password", "", "Private key password (for encrypted keys)")
+	cmd.Flags().BoolVar(&f.keyPasswordStdin, "key-password-stdin", false, "Read private key password from stdin")
+	cmd.Flags().StringVar(&f.keyPasswordFile, "key-password-file", "", "Read private key password from file (use '-' for stdin)")
+}
+
+// options validates the shared flags and loads the key password.
+func (f *jwkFlags) options(cmd *cobra.Command) (cert.JWKOptions, error) {
+	switch f.use {
+	case "", "sig", "enc":
+	default:
+		return cert.JWKOptions{}, &ExitError{Code: 2, Msg: fmt.Sprintf("unknown --use %q (want sig or enc)", f.use)}
+	}
+	inlineProvided := strings.TrimSpace(f.keyPassword) != ""
+	kpw, err := loadSecret(cmd, f.keyPassword, f.keyPasswordStdin, f.keyPasswordFile, "key-password", "key-password-stdin", "key-password-file")
+	if err != nil {
+		return cert.JWKOptions{}, err
+	}
+	if inlineProvided && strings.TrimSpace(kpw) != "" && !f.keyPasswordStdin && strings.TrimSpace(f.keyPasswordFile) == "" {
+		warnInlineSecretFlag("key-password")
+	}
+	return cert.JWKOptions{
+		IncludePrivate: f.includePrivate,
+		KeyPassword:    kpw,
+		Use:            f.use,
+		Alg:            f.alg,
+	}, nil
+}
+
+// report prints the result of writing a JWK or JWKS file.
+func (f *jwkFlags) report(cmd *cobra.Command, result *cert.ToJWKResult, what string) error {
+	if f.jsonOut {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetEscapeHTML(false)
+		return enc.Encode(result)
+	}
+	kind := "public"
+	if result.Private {
+		kind = "private"
+	}
+	success(fmt.Sprintf("Created: %s (%s, %s)", result.Output, what, kind))
+	for _, kid := range result.Kids {
+		kv("kid", kid)
+	}
+	return nil
+}
+
+func buildToJWKCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
+	var f jwkFlags
+	var keyPath, kid string
+	cmd := &cobra.Command{
+		Use:   "to-jwk INPUT [OUTPUT]",
+		Short: "Export a key or certificate as a JSON Web Key",
+		Long: `Export the public key in INPUT as a JSON Web Key (RFC 7517), as OIDC and
+JWT services publish them.
+
+INPUT may be a certificate (PEM, DER or P7B), a combined PEM, a private key
+(PEM, DER or OpenSSH) or a public key (PEM or OpenSSH). Certificates are
+written as x5c, ordered leaf first, with the leaf's x5t#S256. The kid is the
+key's RFC 7638 thumbprint unless --kid is given.
+
+Only the public key is written unless --include-private is set; then the key
+comes from INPUT, or from --key when INPUT is a certificate.
+
+The JWK is written to stdout when OUTPUT is omitted. OUTPUT is created with
+0600 permissions when it holds a private key.
+
+  certconv to-jwk cert.pem --use sig --alg RS256
+
+Pure Go — no external tools required.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			args, err := collectInputArgs(cmd, args, pathInput)
+			if err != nil {
+				return err
+			}
+			if len(args) != 1 && len(args) != 2 {
+				return &ExitError{Code: 2, Msg: fmt.Sprintf("%s requires 1 or 2 argument(s), got %d", cmd.CommandPath(), len(args))}
+			}
+			if f.jsonOut && len(args) == 1 {
+				return &ExitError{Code: 2, Msg: "--json requires OUTPUT; the JWK itself is JSON on stdout"}
+			}
+			opts, err := f.options(cmd)
+			if err != nil {
+				return err
+			}
+			opts.Kid = kid
+
+			inputPath := resolvePath(args[0])
+			if err := requireFile(inputPath); err != nil {
+				return err
+			}
+			if keyPath != "" {
+				opts.KeyPath = resolvePath(keyPath)
+				if err := requireFile(opts.KeyPath); err != nil {
+					return err
+				}
+			}
+
+			if len(args) == 1 {
+				data, _, err := cert.BuildJWK(inputPath, opts)
+				if err != nil {
+					return err
+				}
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+
+			if !f.jsonOut {
+				step("Creating JWK...")
+			}
+			result, err := engine.ToJWK(context.Background(), inputPath, args[1], opts)
+			if err != nil {
+				return err
+			}
+			return f.report(cmd, result, "JWK")
+		},
+	}
+	f.register(cmd)
+	cmd.Flags().StringVar(&keyPath, "key", "", "Private key for a certificate INPUT (checked against the certificate)")
+	cmd.Flags().StringVar(&kid, "kid", "", "Key ID (default: RFC 7638 thumbprint)")
+	cmd.Flags().BoolVar(&f.jsonOut, "json", false, "Output JSON describing the written file (requires OUTPUT)")
+	return cmd
+}
+
+func buildToJWKSCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
+	var f jwkFlags
+	var output string
+	cmd := &cobra.Command{
+		Use:   "to-jwks INPUT...",
+		Short: "Export keys or certificates as a JSON Web Key Set",
+		Long: `Export one JWK per INPUT as a JSON Web Key Set ({"keys": [...]}), ready to
+serve as an OIDC jwks_uri document. INPUT accepts the same files as to-jwk;
+each key's kid is its RFC 7638 thumbprint.
+
+Only public keys are written unless --include-private is set, which needs
+a private key in every INPUT (a key file or combined PEM).
+
+The set is written to stdout unless --output is given. The output file is
+created with 0600 permissions when it holds a private key.
+
+  certconv to-jwks current.pem next.pem -o jwks.json
+
+Pure Go — no external tools required.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			args, err := collectInputArgs(cmd, args, pathInput)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return &ExitError{Code: 2, Msg: fmt.Sprintf("%s requires at least one input", cmd.CommandPath())}
+			}
+			if f.jsonOut && output == "" {
+				return &ExitError{Code: 2, Msg: "--json requires --output; the JWKS itself is JSON on stdout"}
+			}
+			opts, err := f.options(cmd)
+			if err != nil {
+				return err
+			}
+
+			inputs := make([]string, 0, len(args))
+			for _, a := range args {
+				p := resolvePath(a)
+				if err := requireFile(p); err != nil {
+					return err
+				}
+				inputs = append(inputs, p)
+			}
+
+			if output == "" {
+				data, _, err := cert.BuildJWKS(inputs, opts)
+				if err != nil {
+					return err
+				}
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+
+			if !f.jsonOut {
+				step("Creating JWKS...")
+			}
+			result, err := engine.ToJWKS(context.Background(), inputs, output, opts)
+			if err != nil {
+				return err
+			}
+			return f.report(cmd, result, fmt.Sprintf("JWKS, %d keys", len(result.Kids)))
+		},
+	}
+	f.register(cmd)
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the JWKS to this file instead of stdout")
+	cmd.Flags().BoolVar(&f.jsonOut, "json", false, "Output JSON describing the written file (requires --output)")
+	return cmd
+}
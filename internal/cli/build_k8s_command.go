@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nickromney/certconv/internal/cert"
+	"github.com/spf13/cobra"
+)
+
+func buildToK8sSecretCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
+	var name, namespace, caPath, format string
+	var keyPassword string
+	var keyPasswordStdin bool
+	var keyPasswordFile string
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "to-k8s-secret CERT KEY [OUTPUT]",
+		Short: "Create a Kubernetes TLS Secret manifest",
+		Long: `Create a kubernetes.io/tls Secret manifest, as "kubectl create secret tls"
+would, from a certificate and its private key.
+
+tls.crt holds the leaf followed by any other certificates in CERT; tls.key
+holds the key as unencrypted PKCS#8, since Kubernetes cannot use an encrypted
+key. --ca adds a ca.crt key, as cert-manager and many ingress controllers
+expect.
+
+The manifest is written to stdout when OUTPUT is omitted, ready for
+"kubectl apply -f -". OUTPUT is created with 0600 permissions.
+
+  certconv to-k8s-secret cert.pem key.pem --name web-tls -n prod | kubectl apply -f -
+
+Pure Go — no external tools required.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			args, err := collectInputArgs(cmd, args, pathInput)
+			if err != nil {
+				return err
+			}
+			if len(args) != 2 && len(args) != 3 {
+				return &ExitError{Code: 2, Msg: fmt.Sprintf("%s requires 2 or 3 argument(s), got %d", cmd.CommandPath(), len(args))}
+			}
+			if strings.TrimSpace(name) == "" {
+				return &ExitError{Code: 2, Msg: "--name is required"}
+			}
+			switch format {
+			case "yaml", "json":
+			default:
+				return &ExitError{Code: 2, Msg: fmt.Sprintf("unknown --format %q (want yaml or json)", format)}
+			}
+			if jsonOut && len(args) == 2 {
+				return &ExitError{Code: 2, Msg: "--json requires OUTPUT; use --format json for a JSON manifest on stdout"}
+			}
+
+			inlineProvided := strings.TrimSpace(keyPassword) != ""
+			kpw, err := loadSecret(cmd, keyPassword, keyPasswordStdin, keyPasswordFile, "key-password", "key-password-stdin", "key-password-file")
+			if err != nil {
+				return err
+			}
+			keyPassword = kpw
+			if inlineProvided && strings.TrimSpace(keyPassword) != "" && !keyPasswordStdin && strings.TrimSpace(keyPasswordFile) == "" {
+				warnInlineSecretFlag("key-password")
+			}
+
+			certPath := resolvePath(args[0])
+			keyPath := resolvePath(args[1])
+			for _, p := range []string{certPath, keyPath} {
+				if err := requireFile(p); err != nil {
+					return err
+				}
+			}
+			opts := cert.K8sSecretOptions{
+				Name:        name,
+				Namespace:   namespace,
+				KeyPassword: keyPassword,
+				JSON:        format == "json",
+			}
+			if caPath != "" {
+				opts.CAPath = resolvePath(caPath)
+				if err := requireFile(opts.CAPath); err != nil {
+					return err
+				}
+			}
+
+			if len(args) == 2 {
+				data, _, err := cert.BuildK8sSecret(certPath, keyPath, opts)
+				if err != nil {
+					return err
+				}
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+
+			if !jsonOut {
+				step("Creating Kubernetes Secret...")
+			}
+			result, err := engine.ToK8sSecret(context.Background(), certPath, keyPath, args[2], opts)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetEscapeHTML(false)
+				return enc.Encode(result)
+			}
+			id := result.Name
+			if result.Namespace != "" {
+				id = result.Namespace + "/" + result.Name
+			}
+			success(fmt.Sprintf("Created: %s (Secret %s, %s)", result.Output, id, strings.ToUpper(result.Format)))
+			kv("Subject", result.Subject)
+			kv("Chain", fmt.Sprintf("%d certificate(s) after the leaf", result.Chain))
+			if result.CACerts > 0 {
+				kv("ca.crt", fmt.Sprintf("%d certificate(s)", result.CACerts))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "Secret name (required)")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Secret namespace (omit to use the kubectl context's namespace)")
+	cmd.Flags().StringVar(&caPath, "ca", "", "CA certificate(s) to add as ca.crt (PEM, DER or P7B)")
+	cmd.Flags().StringVar(&format, "format", "yaml", "Manifest format: yaml or json")
+	cmd.Flags().StringVar(&keyPassword, "key-password", "", "Private key password (for encrypted keys)")
+	cmd.Flags().BoolVar(&keyPasswordStdin, "key-password-stdin", false, "Read private key password from stdin")
+	cmd.Flags().StringVar(&keyPasswordFile, "key-password-file", "", "Read private key password from file (use '-' for stdin)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON describing the written file (requires OUTPUT)")
+	return cmd
+}
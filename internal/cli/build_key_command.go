@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nickromney/certconv/internal/cert"
+	"github.com/spf13/cobra"
+)
+
+func buildKeyCommand(pathInput *pathInputOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "key",
+		Short: "Convert and re-protect private keys",
+	}
+	cmd.AddCommand(
+		buildKeyConvertCommand(pathInput),
+		buildKeyPasswdCommand(pathInput),
+	)
+	return cmd
+}
+
+func buildKeyConvertCommand(pathInput *pathInputOptions) *cobra.Command {
+	var to, kdf string
+	var encrypt, decrypt bool
+	var secrets keySecretFlags
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "convert KEY OUTPUT",
+		Short: "Convert a private key between PKCS#8, PKCS#1, SEC1 and OpenSSH",
+		Long: `Re-encode a private key. --to selects the output encoding:
+
+  pkcs8    PRIVATE KEY (any algorithm; the only format that can be encrypted)
+  pkcs1    RSA PRIVATE KEY (RSA only)
+  sec1     EC PRIVATE KEY (ECDSA only)
+  openssh  OPENSSH PRIVATE KEY (RSA, ECDSA and Ed25519; unencrypted)
+
+An encrypted input is never silently written in the clear: pass --encrypt to
+re-encrypt the output (PKCS#8 with AES-256-CBC and PBKDF2 or scrypt, using
+the --new-password-* secret) or --decrypt to write it unencrypted.
+
+The output is written with 0600 permissions and never overwrites an
+existing file.
+
+Pure Go — no external tools required.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resolvedArgs, err := resolveInputArgs(cmd, args, 2, pathInput)
+			if err != nil {
+				return err
+			}
+			args = resolvedArgs
+
+			if strings.TrimSpace(to) == "" {
+				return &ExitError{Code: 2, Msg: "--to is required (pkcs8, pkcs1, sec1 or openssh)"}
+			}
+			format, err := cert.ParseKeyFormat(to)
+			if err != nil {
+				return &ExitError{Code: 2, Msg: err.Error()}
+			}
+			if encrypt && decrypt {
+				return &ExitError{Code: 2, Msg: "--encrypt and --decrypt are mutually exclusive"}
+			}
+			if encrypt && format != cert.KeyFormatPKCS8 {
+				return &ExitError{Code: 2, Msg: "--encrypt is only supported with --to pkcs8"}
+			}
+			keyKDF := cert.KeyKDF(strings.ToLower(strings.TrimSpace(kdf)))
+			if keyKDF != cert.KeyKDFPBKDF2 && keyKDF != cert.KeyKDFScrypt {
+				return &ExitError{Code: 2, Msg: fmt.Sprintf("unknown --kdf %q (use pbkdf2 or scrypt)", kdf)}
+			}
+
+			if err := secrets.load(cmd); err != nil {
+				return err
+			}
+			if encrypt && secrets.newPassword() == "" {
+				return &ExitError{Code: 2, Msg: "--encrypt requires a new password (--new-password, --new-password-stdin, or --new-password-file)"}
+			}
+
+			input := resolvePath(args[0])
+			output := args[1]
+			if err := requireFile(input); err != nil {
+				return err
+			}
+
+			result, err := cert.ConvertKey(input, output, cert.KeyConvertOptions{
+				To:          format,
+				Password:    secrets.keyPassword(),
+				Encrypt:     encrypt,
+				Decrypt:     decrypt,
+				NewPassword: secrets.newPassword(),
+				KDF:         keyKDF,
+			})
+			if errors.Is(err, cert.ErrKeyEncryptedInput) {
+				return &ExitError{Code: 2, Msg: "input key is encrypted; pass --encrypt to keep the output encrypted or --decrypt to write it unencrypted"}
+			}
+			if err != nil {
+				return fmt.Errorf("key convert: %w", err)
+			}
+			return printKeyResult(cmd, result, jsonOut)
+		},
+	}
+	cmd.Flags().StringVar(&to, "to", "", "Output encoding: pkcs8, pkcs1, sec1 or openssh")
+	cmd.Flags().BoolVar(&encrypt, "encrypt", false, "Encrypt the output (PKCS#8 with AES-256-CBC)")
+	cmd.Flags().StringVar(&kdf, "kdf", string(cert.KeyKDFPBKDF2), "Key derivation for --encrypt: pbkdf2 or scrypt")
+	cmd.Flags().BoolVar(&decrypt, "decrypt", false, "Write an encrypted input key unencrypted")
+	secrets.register(cmd)
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func buildKeyPasswdCommand(pathInput *pathInputOptions) *cobra.Command {
+	var kdf string
+	var noPassword bool
+	var secrets keySecretFlags
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "passwd KEY OUTPUT",
+		Short: "Change or remove a private key passphrase",
+		Long: `Write a copy of a private key under a new passphrase, or without one.
+
+The current passphrase comes from --key-password-file or --key-password-stdin
+and the new one from --new-password-file or --new-password-stdin (only one of
+them may use stdin). The new key is encrypted PKCS#8 (AES-256-CBC with PBKDF2
+or scrypt); keys using legacy PEM encryption are upgraded to PKCS#8.
+
+--no-password writes the key unencrypted, in its original encoding.
+
+KEY is never modified: OUTPUT must be a new path and is written with 0600
+permissions.
+
+Pure Go — no external tools required.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resolvedArgs, err := resolveInputArgs(cmd, args, 2, pathInput)
+			if err != nil {
+				return err
+			}
+			args = resolvedArgs
+
+			keyKDF := cert.KeyKDF(strings.ToLower(strings.TrimSpace(kdf)))
+			if keyKDF != cert.KeyKDFPBKDF2 && keyKDF != cert.KeyKDFScrypt {
+				return &ExitError{Code: 2, Msg: fmt.Sprintf("unknown --kdf %q (use pbkdf2 or scrypt)", kdf)}
+			}
+			if err := secrets.load(cmd); err != nil {
+				return err
+			}
+			if noPassword && secrets.newPassword() != "" {
+				return &ExitError{Code: 2, Msg: "--no-password cannot be combined with a new password"}
+			}
+			if !noPassword && secrets.newPassword() == "" {
+				return &ExitError{Code: 2, Msg: "a new password is required (--new-password-file or --new-password-stdin), or pass --no-password"}
+			}
+
+			input := resolvePath(args[0])
+			output := args[1]
+			if err := requireFile(input); err != nil {
+				return err
+			}
+
+			result, err := cert.ChangeKeyPassword(input, output, cert.KeyPasswdOptions{
+				Password:    secrets.keyPassword(),
+				NewPassword: secrets.newPassword(),
+				NoPassword:  noPassword,
+				KDF:         keyKDF,
+			})
+			if errors.Is(err, cert.ErrKeyPasswordRequired) {
+				return &ExitError{Code: 2, Msg: "the input key is encrypted; supply its password with --key-password-file or --key-password-stdin"}
+			}
+			if err != nil {
+				return fmt.Errorf("key passwd: %w", err)
+			}
+			if noPassword {
+				// stderr, so --json output stays parseable.
+				fmt.Fprintf(outStderr, "Warning: wrote an unencrypted private key; protect %s accordingly\n", result.Output)
+			}
+			return printKeyResult(cmd, result, jsonOut)
+		},
+	}
+	cmd.Flags().BoolVar(&noPassword, "no-password", false, "Write the key without a passphrase")
+	cmd.Flags().StringVar(&kdf, "kdf", string(cert.KeyKDFPBKDF2), "Key derivation for the new passphrase: pbkdf2 or scrypt")
+	secrets.register(cmd)
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
+
+func printKeyResult(cmd *cobra.Command, result *cert.KeyConvertResult, jsonOut bool) error {
+	if jsonOut {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetEscapeHTML(false)
+		return enc.Encode(result)
+	}
+	detail := fmt.Sprintf("%s, %s", result.Algorithm, result.Format)
+	if result.Encrypted {
+		detail += ", encrypted with " + string(result.KDF)
+	}
+	success(fmt.Sprintf("Created: %s (%s)", result.Output, detail))
+	return nil
+}
+
+// keySecretFlags holds the current (--key-password-*) and new
+// (--new-password-*) passphrases shared by the key subcommands.
+type keySecretFlags struct {
+	secretPair
+}
+
+func (s *keySecretFlags) register(cmd *cobra.Command) {
+	s.current = secretFlag{name: "key-password"}
+	s.next = secretFlag{name: "new-password"}
+	s.current.register(cmd, "", "Input private key password (for encrypted keys)", "Read input private key password from stdin", "Read input private key password from file (use '-' for stdin)")
+	s.next.register(cmd, "", "Password to encrypt the output with", "Read output password from stdin", "Read output password from file (use '-' for stdin)")
+}
+
+// keyPassword is the current passphrase, resolved by load.
+func (s *keySecretFlags) keyPassword() string { return s.current.value }
+
+// newPassword is the output passphrase, resolved by load.
+func (s *keySecretFlags) newPassword() string { return s.next.value }
@@ -3,73 +3,329 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/nickromney/certconv/internal/cert"
+	"github.com/nickromney/certconv/internal/config"
 	"github.com/spf13/cobra"
 )
 
-func buildLintCommand(pathInput *pathInputOptions) *cobra.Command {
-	var jsonOut bool
+var lintFormats = []string{"text", "json", "sarif"}
+
+func buildLintCommand(pathInput *pathInputOptions, version string) *cobra.Command {
+	var jsonOut, listRules, recursive bool
+	var profile, format string
+	var enable, disable, severity []string
 	cmd := &cobra.Command{
-		Use:   "lint FILE",
-		Short: "Lint a certificate for common issues",
-		Long: `Lint a PEM or DER certificate for common configuration issues.
-
-Checks performed:
-  weak-key        RSA key < 2048 bits (error)
-  sha1-signature  SHA-1 signature algorithm (warning)
-  missing-sans    No Subject Alternative Names (warning)
-  expired         Certificate has expired (error)
-  not-yet-valid   Certificate is not yet valid (error)
-  ca-as-leaf      CA=true with ServerAuth but no CertSign (warning)
-  long-validity   Leaf cert validity > 398 days (warning)
-
-Exit codes: 0 = clean, 1 = issues found.
+		Use:   "lint PATH...",
+		Short: "Lint certificates for common issues",
+		Long: `Lint PEM or DER certificates for common configuration issues.
+Every certificate in a PEM bundle, PFX (read with an empty password) or P7B
+is linted, and findings are labelled with the certificate's chain position
+(leaf, intermediate N, root). JKS/JCEKS keystores are linted entry by entry. CSRs get the weak-key,
+sha1-signature and missing-sans checks plus a self-signature check
+(bad-csr-signature).
+
+Rules (default severity):
+  weak-key           RSA key < 2048 bits (error)
+  sha1-signature     SHA-1 signature algorithm (warning)
+  missing-sans       No Subject Alternative Names (warning)
+  expired            Certificate has expired (error)
+  not-yet-valid      Certificate is not yet valid (error)
+  ca-as-leaf         CA=true with ServerAuth but no CertSign (warning)
+  long-validity      Leaf cert validity > 398 days (warning)
+  bad-csr-signature  CSR self-signature does not verify (error)
+
+Bundles with more than one certificate also get chain checks, in both
+profiles (ordering follows certconv chain):
+  chain-order                 Not in leaf→intermediate→root order (warning)
+  chain-duplicate             Same certificate more than once (warning)
+  chain-includes-root         Self-signed root included (warning)
+  chain-missing-intermediate  Certificates do not link up to the leaf (error)
+  chain-issuer-mismatch       Issuer name or signature does not match (error)
+Files holding only CA certificates (trust bundles) get chain-duplicate only.
+
+--profile cabf-br checks a publicly trusted TLS server certificate against
+the CA/Browser Forum Baseline Requirements instead: serial number length
+and entropy, key and signature algorithms, maximum validity for the
+issuance date, EKU and key usage, forbidden subject fields, SAN/CN
+consistency, wildcard placement, internal names, and AIA/CRL distribution
+points. Each finding cites the BR section it enforces; CA certificates
+only get the serial, key and signature checks.
+
+Rules can be turned off with --disable, back on with --enable (including
+rules from another profile), and re-graded with --severity
+RULE=error|warning. The same settings can live in config.yml, and flags
+override them:
+
+  lint:
+    profile: cabf-br
+    disable: long-validity
+    severity: sha1-signature=error
+
+--list-rules prints the profile's rules with their default and effective
+settings and, for cabf-br, their citations.
+
+Several files, or directories, can be linted in one run. Directories are
+searched for certificates, CSRs and keystores (with -r, recursively);
+hidden entries are skipped. With several files --json prints a list of
+results, and files that cannot be read are reported rather than stopping
+the run.
+
+--format sarif writes a SARIF 2.1.0 log for code-scanning tools: one run
+whose rules are the enabled lint rules and whose artifacts are the linted
+files. Relative paths stay relative (to %SRCROOT%, the working directory),
+so run it from the repository root.
+
+Exit codes: 0 = clean, 1 = issues found (text output) or a file could not
+be read. With --format json or sarif, findings do not change the exit code.
 Pure Go — no external tools required.`,
 		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			resolvedArgs, err := resolveInputArgs(cmd, args, 1, pathInput)
+			format = strings.ToLower(strings.TrimSpace(format))
+			if jsonOut {
+				if cmd.Flags().Changed("format") && format != "json" {
+					return &ExitError{Code: 2, Msg: "--json cannot be combined with --format " + format}
+				}
+				format = "json"
+			}
+			if !containsString(lintFormats, format) {
+				return &ExitError{Code: 2, Msg: fmt.Sprintf("--format must be one of %s", strings.Join(lintFormats, ", "))}
+			}
+
+			lintCfg, err := resolveLintConfig(profile, enable, disable, severity)
 			if err != nil {
 				return err
 			}
-			args = resolvedArgs
+			if listRules {
+				return printLintRules(cmd.OutOrStdout(), lintCfg, format == "json")
+			}
 
-			path := resolvePath(args[0])
-			if err := requireFile(path); err != nil {
+			paths, err := resolveInputPaths(cmd, args, pathInput)
+			if err != nil {
 				return err
 			}
+			for i, p := range paths {
+				paths[i] = resolvePath(p)
+			}
 
-			result, err := cert.LintFile(path)
-			if err != nil {
-				return fmt.Errorf("lint: %w", err)
+			// A single file keeps the original contract: read errors fail the
+			// command, and --json prints one object rather than a list.
+			single := len(paths) == 1
+			if single {
+				if info, err := os.Stat(paths[0]); err == nil && info.IsDir() {
+					single = false
+				}
+			}
+			var results []cert.LintResult
+			if single {
+				if err := requireFile(paths[0]); err != nil {
+					return err
+				}
+				result, err := cert.LintFileWithConfig(paths[0], lintCfg)
+				if err != nil {
+					return fmt.Errorf("lint: %w", err)
+				}
+				results = []cert.LintResult{*result}
+			} else {
+				results = cert.LintPaths(paths, recursive, lintCfg)
 			}
 
-			if jsonOut {
+			failed, issues := 0, 0
+			for _, r := range results {
+				if r.Error != "" {
+					failed++
+				}
+				issues += len(r.Issues)
+			}
+
+			switch format {
+			case "sarif":
+				if err := writeLintSARIF(cmd.OutOrStdout(), results, lintCfg, version); err != nil {
+					return err
+				}
+			case "json":
 				enc := json.NewEncoder(cmd.OutOrStdout())
 				enc.SetEscapeHTML(false)
 				enc.SetIndent("", "  ")
-				return enc.Encode(result)
+				var v any = results
+				if single {
+					v = results[0]
+				}
+				if err := enc.Encode(v); err != nil {
+					return err
+				}
+			default:
+				printLintResults(results, single)
+				if issues > 0 {
+					failed++
+				}
 			}
-
-			if result.Clean {
-				success("No issues found")
-				return nil
+			if failed > 0 {
+				return &ExitError{Code: 1, Silent: true}
 			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON (same as --format json)")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text, json or sarif")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Descend into subdirectories of directory arguments")
+	cmd.Flags().BoolVar(&listRules, "list-rules", false, "List lint rules and exit")
+	cmd.Flags().StringVar(&profile, "profile", "", "Rule profile: default or cabf-br")
+	cmd.Flags().StringSliceVar(&enable, "enable", nil, "Enable rules by ID (comma-separated or repeated)")
+	cmd.Flags().StringSliceVar(&disable, "disable", nil, "Disable rules by ID (comma-separated or repeated)")
+	cmd.Flags().StringSliceVar(&severity, "severity", nil, "Override a rule's severity: RULE=error|warning (repeatable)")
+	return cmd
+}
 
-			fmt.Fprintln(outStdout)
-			for _, issue := range result.Issues {
-				switch issue.Severity {
-				case cert.LintError:
-					errMsg(fmt.Sprintf("[%s] %s", issue.Code, issue.Message))
-				case cert.LintWarning:
-					warn(fmt.Sprintf("[%s] %s", issue.Code, issue.Message))
-				}
+// resolveLintConfig layers the lint flags over the config file's lint
+// section. Unknown rules and severities, and a config file that cannot be
+// read, are usage errors: a policy meant to fail CI must not silently vanish.
+func resolveLintConfig(profile string, enable, disable, severity []string) (cert.LintConfig, error) {
+	cfg, err := config.Load() // a missing file is not an error
+	if err != nil {
+		return cert.LintConfig{}, &ExitError{Code: 2, Msg: "config: " + err.Error()}
+	}
+	sev := make([]string, 0, len(cfg.Lint.Severity))
+	for rule, s := range cfg.Lint.Severity {
+		sev = append(sev, rule+"="+s)
+	}
+	base, err := buildLintConfig(cfg.Lint.Profile, cfg.Lint.Enable, cfg.Lint.Disable, sev)
+	if err != nil {
+		return cert.LintConfig{}, &ExitError{Code: 2, Msg: "config lint: " + err.Error()}
+	}
+	flags, err := buildLintConfig(profile, enable, disable, severity)
+	if err != nil {
+		return cert.LintConfig{}, &ExitError{Code: 2, Msg: err.Error()}
+	}
+	return base.Merge(flags), nil
+}
+
+func buildLintConfig(profile string, enable, disable, severity []string) (cert.LintConfig, error) {
+	out := cert.LintConfig{Profile: strings.TrimSpace(profile), Enabled: map[string]bool{}, Severity: map[string]cert.LintSeverity{}}
+	for _, id := range disable {
+		out.Enabled[strings.TrimSpace(id)] = false
+	}
+	for _, id := range enable {
+		id = strings.TrimSpace(id)
+		if on, ok := out.Enabled[id]; ok && !on {
+			return out, fmt.Errorf("rule %s is both enabled and disabled", id)
+		}
+		out.Enabled[id] = true
+	}
+	for _, item := range severity {
+		id, s, ok := strings.Cut(item, "=")
+		if !ok {
+			return out, fmt.Errorf("--severity must be RULE=error|warning, got %q", item)
+		}
+		sev, err := cert.ParseLintSeverity(s)
+		if err != nil {
+			return out, fmt.Errorf("rule %s: %w", strings.TrimSpace(id), err)
+		}
+		out.Severity[strings.TrimSpace(id)] = sev
+	}
+	return out, out.Validate()
+}
+
+type lintRuleView struct {
+	cert.LintRule
+	Severity cert.LintSeverity `json:"severity"`
+	Enabled  bool              `json:"enabled"`
+}
+
+// printLintRules lists the rules of cfg's profile, plus any rule cfg names
+// explicitly.
+func printLintRules(w io.Writer, cfg cert.LintConfig, jsonOut bool) error {
+	profile := cfg.Profile
+	if profile == "" {
+		profile = cert.LintProfileDefault
+	}
+	rules := cert.LintRules()
+	views := make([]lintRuleView, 0, len(rules))
+	for _, r := range rules {
+		if _, named := cfg.Enabled[r.ID]; !named && !slices.Contains(r.Profiles, profile) {
+			continue
+		}
+		views = append(views, lintRuleView{LintRule: r, Severity: cfg.RuleSeverity(r), Enabled: cfg.RuleEnabled(r)})
+	}
+	if jsonOut {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(views)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RULE\tDEFAULT\tEFFECTIVE\tDESCRIPTION")
+	for _, v := range views {
+		effective := string(v.Severity)
+		if !v.Enabled {
+			effective = "disabled"
+		}
+		desc := v.Description
+		if v.CSR && !v.CSROnly() {
+			desc += " (also CSRs)"
+		}
+		if v.BundleOnly() {
+			desc += " (bundles)"
+		}
+		if v.Citation != "" {
+			desc += " [" + v.Citation + "]"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", v.ID, v.DefaultSeverity, effective, desc)
+	}
+	return tw.Flush()
+}
+
+// printLintResults writes the human-readable findings. Several results get a
+// heading per file with findings, and a closing summary.
+func printLintResults(results []cert.LintResult, single bool) {
+	if single && results[0].Clean {
+		success("No issues found")
+		return
+	}
+
+	withIssues := 0
+	fmt.Fprintln(outStdout)
+	for _, r := range results {
+		if r.Error != "" {
+			errMsg(fmt.Sprintf("%s: %s", r.File, r.Error))
+			continue
+		}
+		if len(r.Issues) == 0 {
+			continue
+		}
+		withIssues++
+		if !single {
+			info(r.File)
+		}
+		for _, issue := range r.Issues {
+			msg := fmt.Sprintf("[%s] %s", issue.Code, issue.Message)
+			if issue.Position != "" {
+				msg = fmt.Sprintf("[%s] %s: %s", issue.Code, issue.Position, issue.Message)
+			}
+			if issue.Citation != "" {
+				msg += " (" + issue.Citation + ")"
 			}
-			fmt.Fprintln(outStdout)
+			switch issue.Severity {
+			case cert.LintError:
+				errMsg(msg)
+			case cert.LintWarning:
+				warn(msg)
+			}
+		}
+	}
+	fmt.Fprintln(outStdout)
 
-			return &ExitError{Code: 1, Silent: true}
-		},
+	if !single {
+		if withIssues == 0 {
+			success(fmt.Sprintf("No issues found in %d file(s)", len(results)))
+		} else {
+			info(fmt.Sprintf("Issues found in %d of %d file(s)", withIssues, len(results)))
+		}
 	}
-	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
-	return cmd
 }
@@ -3,6 +3,8 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/nickromney/certconv/internal/cert"
 	"github.com/spf13/cobra"
@@ -52,3 +54,63 @@ Requires openssl. Each certificate is written as a separate file.`,
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
 	return cmd
 }
+
+func buildToP7BCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
+	var der, jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "to-p7b CERT [CHAIN...] OUTPUT",
+		Short: "Create a certs-only PKCS#7 (.p7b) bundle",
+		Long: `Create a certs-only PKCS#7 bundle, as Windows and many appliances import,
+from one or more PEM, DER or P7B certificate files.
+
+Certificates are de-duplicated and ordered leaf → intermediate(s) → root with
+the same logic as "certconv chain", whatever order the inputs are in.
+Output is PEM ("-----BEGIN PKCS7-----") by default, or DER with --der.
+
+Pure Go — no external tools required.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			args, err := collectInputArgs(cmd, args, pathInput)
+			if err != nil {
+				return err
+			}
+			if len(args) < 2 {
+				return &ExitError{Code: 2, Msg: fmt.Sprintf("%s requires at least one certificate and an output path", cmd.CommandPath())}
+			}
+
+			output := args[len(args)-1]
+			inputs := make([]string, 0, len(args)-1)
+			for _, a := range args[:len(args)-1] {
+				p := resolvePath(a)
+				if err := requireFile(p); err != nil {
+					return err
+				}
+				inputs = append(inputs, p)
+			}
+
+			if !jsonOut {
+				step("Creating P7B...")
+			}
+			result, err := engine.ToP7B(context.Background(), inputs, output, der)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetEscapeHTML(false)
+				return enc.Encode(result)
+			}
+			for _, w := range result.Warnings {
+				warn(w)
+			}
+			success(fmt.Sprintf("Created: %s (%d certificates, %s)", result.Output, len(result.Subjects), strings.ToUpper(result.Encoding)))
+			for i, s := range result.Subjects {
+				kv(fmt.Sprintf("%d", i), s)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&der, "der", false, "Write DER instead of PEM")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
+	return cmd
+}
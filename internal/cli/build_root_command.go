@@ -31,6 +31,7 @@ func NewRootCmd(engine *cert.Engine, runTUI func(startDir string) error, buildIn
 		flagPlain               bool
 		flagQuiet               bool
 		flagNoWarnInlineSecrets bool
+		flagBackend             string
 		pathInput               pathInputOptions
 		quickDER                bool
 		quickPassword           string
@@ -157,6 +158,17 @@ func NewRootCmd(engine *cert.Engine, runTUI func(startDir string) error, buildIn
 		}
 		setOutputOptions(cmd.OutOrStdout(), cmd.ErrOrStderr(), outputOptions{color: color, unicode: unicode, quiet: flagQuiet})
 		setInlineSecretWarnings(!flagNoWarnInlineSecrets)
+
+		if strings.TrimSpace(flagBackend) != "" && engine != nil {
+			b, err := cert.ParseBackend(flagBackend)
+			if err != nil {
+				return &ExitError{Code: 2, Msg: err.Error()}
+			}
+			if b == cert.BackendOpenSSL && !cert.OpenSSLAvailable() {
+				return &ExitError{Code: 2, Msg: "--backend openssl: openssl not found in PATH (use --backend go)"}
+			}
+			engine.SetBackend(b)
+		}
 		return nil
 	}
 	root.Version = buildInfo.Version + "\nbuild_time: " + buildInfo.BuildTime + "\ngit_commit: " + buildInfo.GitCommit
@@ -175,6 +187,7 @@ stdin and stdout are a TTY. Use subcommands for scripting and pipelines.
 	root.PersistentFlags().BoolVarP(&flagQuiet, "quiet", "q", false, "Suppress status output (errors still print)")
 	root.PersistentFlags().BoolVar(&flagPlain, "plain", false, "Plain output (implies --no-color and --ascii)")
 	root.PersistentFlags().BoolVar(&flagNoWarnInlineSecrets, "no-warn-inline-secrets", false, "Disable warnings for inline secret flags")
+	root.PersistentFlags().StringVar(&flagBackend, "backend", "", "Certificate backend: go or openssl (default: openssl when installed, else go)")
 	root.PersistentFlags().BoolVar(&pathInput.pathStdin, "path-stdin", false, "Read missing path args from stdin (newline-delimited)")
 	root.PersistentFlags().BoolVar(&pathInput.path0Stdin, "path0-stdin", false, "Read missing path args from stdin (NUL-delimited)")
 	root.Flags().BoolVarP(&quickDER, "der", "d", false, "Quick convert FILE to DER and write bytes to stdout")
@@ -191,17 +204,30 @@ stdin and stdout are a TTY. Use subcommands for scripting and pipelines.
 		buildShowFullCommand(engine, &pathInput),
 		buildVerifyCommand(engine, &pathInput),
 		buildMatchCommand(engine, &pathInput),
+		buildCoversCommand(&pathInput),
+		buildDiffCommand(&pathInput),
 		buildExpiryCommand(engine, &pathInput),
 		buildToPFXCommand(engine, &pathInput),
 		buildFromPFXCommand(engine, &pathInput),
+		buildPFXCommand(&pathInput),
+		buildToJKSCommand(engine, &pathInput),
+		buildToTruststoreCommand(engine, &pathInput),
+		buildFromJKSCommand(engine, &pathInput),
 		buildToDERCommand(engine, &pathInput),
 		buildFromDERCommand(engine, &pathInput),
 		buildToBase64Command(engine, &pathInput),
 		buildFromBase64Command(engine, &pathInput),
 		buildCombineCommand(engine, &pathInput),
+		buildToP7BCommand(engine, &pathInput),
 		buildFromP7BCommand(engine, &pathInput),
-		buildLintCommand(&pathInput),
+		buildToK8sSecretCommand(engine, &pathInput),
+		buildToJWKCommand(engine, &pathInput),
+		buildToJWKSCommand(engine, &pathInput),
+		buildLintCommand(&pathInput, buildInfo.Version),
 		buildChainCommand(&pathInput),
+		buildSplitCommand(&pathInput),
+		buildKeyCommand(&pathInput),
+		buildScanCommand(&pathInput),
 		buildDoctorCommand(),
 		buildLocalCACommand(),
 		buildVersionCommand(buildInfo),
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nickromney/certconv/internal/cert"
+	"github.com/spf13/cobra"
+)
+
+var scanFormats = []string{"table", "json", "ndjson", "csv"}
+
+func buildScanCommand(pathInput *pathInputOptions) *cobra.Command {
+	var recursive bool
+	var format string
+	var include, exclude []string
+	var passwordMap string
+	cmd := &cobra.Command{
+		Use:   "scan DIR",
+		Short: "Inventory certificates and keys in a directory",
+		Long: `Walk DIR and report every certificate and private key found, one record per
+item: a PEM bundle, PFX or keystore yields a record for each certificate and
+key it holds.
+
+Records carry path, type, kind, subject, issuer, SANs, not-after, days left,
+SHA-256 fingerprint (of the certificate, or of a key's public key) and key
+algorithm. The table shows a subset; json, ndjson and csv carry every field.
+
+--include and --exclude take globs matched against both the file name and the
+path relative to DIR, and may be repeated. Hidden files and directories are
+skipped.
+
+--password-map names a file of PATTERN=PASSWORD lines (# comments allowed).
+Passwords whose pattern matches a PFX, keystore or encrypted key are tried in
+order, then the empty password. Encrypted keys with no matching password are
+still listed, as encrypted.
+
+Pure Go — no external tools required.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resolvedArgs, err := resolveInputArgs(cmd, args, 1, pathInput)
+			if err != nil {
+				return err
+			}
+			dir := resolvePath(resolvedArgs[0])
+
+			format = strings.ToLower(strings.TrimSpace(format))
+			if !containsString(scanFormats, format) {
+				return &ExitError{Code: 2, Msg: fmt.Sprintf("--format must be one of %s", strings.Join(scanFormats, ", "))}
+			}
+			info, err := os.Stat(dir)
+			if err != nil {
+				return fmt.Errorf("inspect path %q: %w", dir, err)
+			}
+			if !info.IsDir() {
+				return &ExitError{Code: 2, Msg: fmt.Sprintf("not a directory: %s", dir)}
+			}
+
+			opts := cert.ScanOptions{Recursive: recursive, Include: include, Exclude: exclude}
+			if strings.TrimSpace(passwordMap) != "" {
+				data, err := os.ReadFile(resolvePath(passwordMap))
+				if err != nil {
+					return err
+				}
+				if opts.Passwords, err = cert.ParseScanPasswords(data); err != nil {
+					return &ExitError{Code: 2, Msg: "--password-map: " + err.Error()}
+				}
+			}
+
+			records, err := cert.ScanDir(dir, opts)
+			if err != nil {
+				return fmt.Errorf("scan: %w", err)
+			}
+			if records == nil {
+				records = []cert.ScanRecord{}
+			}
+			return writeScanRecords(cmd.OutOrStdout(), format, records)
+		},
+	}
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Descend into subdirectories")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format: table, json, ndjson or csv")
+	cmd.Flags().StringArrayVar(&include, "include", nil, "Only scan files matching this glob (repeatable)")
+	cmd.Flags().StringArrayVar(&exclude, "exclude", nil, "Skip files and directories matching this glob (repeatable)")
+	cmd.Flags().StringVar(&passwordMap, "password-map", "", "File of PATTERN=PASSWORD lines for PFX/keystores and encrypted keys")
+	return cmd
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func writeScanRecords(w io.Writer, format string, records []cert.ScanRecord) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(records)
+
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"path", "type", "kind", "index", "subject", "issuer", "sans", "not_after", "days_left", "fingerprint", "key_algorithm", "encrypted", "error"})
+		for _, r := range records {
+			_ = cw.Write([]string{
+				r.Path, string(r.Type), string(r.Kind), strconv.Itoa(r.Index),
+				r.Subject, r.Issuer, strings.Join(r.SANs, ";"), r.NotAfter, scanDaysLeft(r),
+				r.Fingerprint, r.KeyAlgorithm, strconv.FormatBool(r.Encrypted), r.Error,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "PATH\tTYPE\tKIND\tSUBJECT\tSANS\tNOT AFTER\tDAYS\tKEY")
+		for _, r := range records {
+			kind := string(r.Kind)
+			if r.Error != "" {
+				kind = "error: " + r.Error
+			} else if r.Encrypted && r.KeyAlgorithm == "" {
+				kind += " (encrypted)"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				r.Path, r.Type, kind, r.Subject, cert.FormatSANsShort(r.SANs),
+				formatSummaryTimestamp(r.NotAfter), scanDaysLeft(r), r.KeyAlgorithm)
+		}
+		return tw.Flush()
+	}
+}
+
+func scanDaysLeft(r cert.ScanRecord) string {
+	if r.DaysLeft == nil {
+		return ""
+	}
+	return strconv.Itoa(*r.DaysLeft)
+}
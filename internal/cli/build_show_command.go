@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -61,9 +62,9 @@ func buildShowCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.C
 			return nil
 		},
 	}
-	cmd.Flags().StringVarP(&password, "password", "p", "", "PFX password")
-	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read PFX password from stdin")
-	cmd.Flags().StringVar(&passwordFile, "password-file", "", "Read PFX password from file (use '-' for stdin)")
+	cmd.Flags().StringVarP(&password, "password", "p", "", "PFX or keystore password")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read PFX or keystore password from stdin")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "Read PFX or keystore password from file (use '-' for stdin)")
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
 	return cmd
 }
@@ -109,9 +110,17 @@ func printSummaryHuman(s *cert.CertSummary) {
 	kv("Type", string(s.FileType))
 	fmt.Fprintln(outStdout)
 
-	if s.FileType == cert.FileTypeKey {
+	switch s.FileType {
+	case cert.FileTypeKey:
 		kv("Key Type", string(s.KeyType))
-	} else {
+		printOpenSSHKeySummaryHuman(s)
+	case cert.FileTypeCSR:
+		printCSRSummaryHuman(s)
+	case cert.FileTypeCRL:
+		printCRLSummaryHuman(s)
+	case cert.FileTypeSSHCert:
+		printSSHCertSummaryHuman(s)
+	default:
 		if s.Subject != "" {
 			kv("Subject", s.Subject)
 		}
@@ -128,7 +137,202 @@ func printSummaryHuman(s *cert.CertSummary) {
 			kv("Serial", s.Serial)
 		}
 	}
+	if s.FileType == cert.FileTypeJKS {
+		printKeystoreEntriesHuman(s)
+	}
+	if s.FileType == cert.FileTypeK8sSecret {
+		printK8sSecretsHuman(s)
+	}
+	if s.FileType == cert.FileTypeKubeconfig {
+		printKubeconfigHuman(s)
+	}
+	if s.FileType == cert.FileTypeJWK {
+		printJWKsHuman(s)
+	}
+	fmt.Fprintln(outStdout)
+}
+
+func printCSRSummaryHuman(s *cert.CertSummary) {
+	kv("Subject", s.Subject)
+	if len(s.SANs) > 0 {
+		kv("SANs", strings.Join(s.SANs, ", "))
+	}
+	if s.PublicKeyInfo != "" {
+		kv("Public Key", s.PublicKeyInfo)
+	}
+	if s.SignatureAlgorithm != "" {
+		kv("Sig Algo", s.SignatureAlgorithm)
+	}
+	if s.SignatureValid != nil {
+		if *s.SignatureValid {
+			kv("Signature", "valid")
+		} else {
+			kv("Signature", "INVALID")
+		}
+	}
+	if len(s.RequestedExtensions) > 0 {
+		kv("Requested Exts", strings.Join(s.RequestedExtensions, ", "))
+	}
+}
+
+func printCRLSummaryHuman(s *cert.CertSummary) {
+	kv("Issuer", s.Issuer)
+	kv("This Update", formatSummaryTimestamp(s.ThisUpdate))
+	if s.NextUpdate != "" {
+		kv("Next Update", formatSummaryTimestamp(s.NextUpdate))
+	}
+	if s.CRLNumber != "" {
+		kv("CRL Number", s.CRLNumber)
+	}
+	if s.SignatureAlgorithm != "" {
+		kv("Sig Algo", s.SignatureAlgorithm)
+	}
+	kv("Revoked", fmt.Sprint(len(s.Revoked)))
+	for _, r := range s.Revoked {
+		line := r.Serial + "  " + formatSummaryTimestamp(r.RevokedAt)
+		if r.Reason != "" {
+			line += "  " + r.Reason
+		}
+		kv("  Serial", line)
+	}
+}
+
+func printSSHCertSummaryHuman(s *cert.CertSummary) {
+	c := s.SSHCert
+	if c == nil {
+		return
+	}
+	kv("Key ID", c.KeyID)
+	kv("Cert Type", c.Type)
+	if len(c.Principals) > 0 {
+		kv("Principals", strings.Join(c.Principals, ", "))
+	} else {
+		kv("Principals", "(none: valid for any)")
+	}
+	kv("Valid After", formatSummaryTimestamp(c.ValidAfter))
+	kv("Valid Before", formatSummaryTimestamp(c.ValidBefore))
+	kv("Serial", s.Serial)
+	kv("Key", c.KeyAlgorithm+" "+c.KeyFingerprint)
+	kv("Signing CA", c.CAKeyAlgorithm+" "+c.CAFingerprint)
+	names := make([]string, 0, len(c.CriticalOptions))
+	for name := range c.CriticalOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		kv("Critical Option", name+" "+c.CriticalOptions[name])
+	}
+	if len(c.Extensions) > 0 {
+		kv("Extensions", strings.Join(c.Extensions, ", "))
+	}
+}
+
+func printOpenSSHKeySummaryHuman(s *cert.CertSummary) {
+	k := s.OpenSSHKey
+	if k == nil {
+		return
+	}
+	kv("Algorithm", k.Algorithm)
+	kv("Bits", fmt.Sprintf("%d", k.Bits))
+	kv("Fingerprint", k.Fingerprint)
+	if k.Comment != "" {
+		kv("Comment", k.Comment)
+	}
+	if !k.Encrypted {
+		kv("Encrypted", "no")
+		return
+	}
+	enc := "yes (" + k.Cipher
+	if k.KDF != "" {
+		enc += ", " + k.KDF
+		if k.KDFRounds > 0 {
+			enc += fmt.Sprintf(" %d rounds", k.KDFRounds)
+		}
+	}
+	kv("Encrypted", enc+")")
+}
+
+func printKeystoreEntriesHuman(s *cert.CertSummary) {
 	fmt.Fprintln(outStdout)
+	kv("Keystore", fmt.Sprintf("%s (%d entries)", s.KeystoreType, len(s.KeystoreEntries)))
+	for _, e := range s.KeystoreEntries {
+		fmt.Fprintln(outStdout)
+		kv("Alias", e.Alias)
+		kv("Entry Type", string(e.Type))
+		kv("Created", formatSummaryTimestamp(e.Created))
+		for i, subj := range e.Chain {
+			kv(fmt.Sprintf("Chain[%d]", i), subj)
+		}
+		if e.NotAfter != "" {
+			kv("Not After", formatSummaryTimestamp(e.NotAfter))
+		}
+	}
+}
+
+func printK8sSecretsHuman(s *cert.CertSummary) {
+	fmt.Fprintln(outStdout)
+	kv("Secrets", fmt.Sprint(len(s.K8sSecrets)))
+	for _, sec := range s.K8sSecrets {
+		fmt.Fprintln(outStdout)
+		kv("Secret", sec.ID())
+		kv("Secret Type", sec.Type)
+		kv("Subject", sec.Subject)
+		kv("Not After", formatSummaryTimestamp(sec.NotAfter))
+		kv("tls.crt", fmt.Sprintf("%d certificate(s)", sec.Certificates))
+		if sec.HasKey {
+			kv("tls.key", "present")
+		} else {
+			kv("tls.key", "missing")
+		}
+		if sec.CACerts > 0 {
+			kv("ca.crt", fmt.Sprintf("%d certificate(s)", sec.CACerts))
+		}
+	}
+}
+
+func printKubeconfigHuman(s *cert.CertSummary) {
+	for _, e := range s.KubeconfigEntries {
+		fmt.Fprintln(outStdout)
+		if e.Kind == cert.KubeconfigCluster {
+			kv("Cluster", e.Name)
+			kv("CA Subject", e.Subject)
+		} else {
+			kv("User", e.Name)
+			kv("Subject", e.Subject)
+			kv("Issuer", e.Issuer)
+		}
+		kv("Not After", formatSummaryTimestamp(e.NotAfter))
+		if e.Certificates > 1 {
+			kv("Certificates", fmt.Sprint(e.Certificates))
+		}
+		if e.Source != "embedded" {
+			kv("Source", e.Source)
+		}
+	}
+}
+
+func printJWKsHuman(s *cert.CertSummary) {
+	for _, k := range s.JWKs {
+		fmt.Fprintln(outStdout)
+		kv("Key ID", k.Kid)
+		kv("Key", k.Key)
+		if k.Alg != "" {
+			kv("Algorithm", k.Alg)
+		}
+		if k.Use != "" {
+			kv("Use", k.Use)
+		}
+		kv("Private", fmt.Sprint(k.Private))
+		kv("Thumbprint", k.Thumbprint)
+		if k.Certificates > 0 {
+			kv("x5c", fmt.Sprintf("%d certificate(s)", k.Certificates))
+			kv("Subject", k.Subject)
+			kv("Not After", formatSummaryTimestamp(k.NotAfter))
+		}
+		for _, w := range k.Warnings {
+			warn(w)
+		}
+	}
 }
 
 func buildShowFullCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
@@ -170,8 +374,8 @@ func buildShowFullCommand(engine *cert.Engine, pathInput *pathInputOptions) *cob
 			return nil
 		},
 	}
-	cmd.Flags().StringVarP(&password, "password", "p", "", "PFX password")
-	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read PFX password from stdin")
-	cmd.Flags().StringVar(&passwordFile, "password-file", "", "Read PFX password from file (use '-' for stdin)")
+	cmd.Flags().StringVarP(&password, "password", "p", "", "PFX or keystore password")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read PFX or keystore password from stdin")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "Read PFX or keystore password from file (use '-' for stdin)")
 	return cmd
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nickromney/certconv/internal/cert"
 	"github.com/spf13/cobra"
@@ -13,10 +14,24 @@ import (
 
 func buildVerifyCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
 	var jsonOut bool
+	var crlFiles []string
+	var hostname, purpose, at, intermediates string
 	cmd := &cobra.Command{
 		Use:   "verify CERT CA",
 		Short: "Verify certificate chain",
-		Args:  cobra.ArbitraryArgs,
+		Long: `Verify a certificate against a CA bundle.
+
+With --crl, revocation is also checked offline: the leaf's issuer must have a
+current CRL among the given files, and the leaf must not be listed in it.
+
+--hostname, --purpose, --at and --intermediates always use the built-in
+crypto/x509 verifier, whatever the backend. --at takes a date (2027-01-01) or
+an RFC 3339 time, and is useful for renewal planning. CA holds the trusted
+roots; untrusted intermediates go in --intermediates.
+
+With --json, the result carries a Reason code and FailingDepth (0 is the
+leaf) on failure, and the verified Chains on success.`,
+		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			resolvedArgs, err := resolveInputArgs(cmd, args, 2, pathInput)
 			if err != nil {
@@ -32,11 +47,37 @@ func buildVerifyCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra
 			if err := requireFile(caPath); err != nil {
 				return err
 			}
+			opts := cert.VerifyOptions{Hostname: strings.TrimSpace(hostname), Purpose: strings.TrimSpace(purpose)}
+			if opts.Purpose != "" {
+				if _, err := cert.ParseVerifyPurpose(opts.Purpose); err != nil {
+					return &ExitError{Code: 2, Msg: "--purpose: " + err.Error()}
+				}
+			}
+			if strings.TrimSpace(at) != "" {
+				t, err := parseVerifyTime(at)
+				if err != nil {
+					return &ExitError{Code: 2, Msg: err.Error()}
+				}
+				opts.At = t
+			}
+			if strings.TrimSpace(intermediates) != "" {
+				opts.IntermediatesFile = resolvePath(intermediates)
+				if err := requireFile(opts.IntermediatesFile); err != nil {
+					return err
+				}
+			}
+			for _, f := range crlFiles {
+				p := resolvePath(f)
+				if err := requireFile(p); err != nil {
+					return err
+				}
+				opts.CRLFiles = append(opts.CRLFiles, p)
+			}
 
 			if !jsonOut {
 				step("Verifying certificate chain...")
 			}
-			result, err := engine.VerifyChain(context.Background(), certPath, caPath)
+			result, err := engine.VerifyChainWithOptions(context.Background(), certPath, caPath, opts)
 			if err != nil {
 				return err
 			}
@@ -55,12 +96,21 @@ func buildVerifyCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra
 
 			if result.Valid {
 				success("Certificate chain verified")
+				printVerifyChains(result)
 				return nil
 			}
 
 			errMsg("Chain verification failed")
 			fmt.Fprintln(outStdout)
 			fmt.Fprintf(outStdout, "  %s\n", result.Output)
+			if result.Reason != "" {
+				fmt.Fprintln(outStdout)
+				kv("Reason", string(result.Reason))
+				if result.FailingDepth != nil {
+					kv("Depth", strconv.Itoa(*result.FailingDepth))
+				}
+			}
+			printVerifyChains(result)
 			if result.Details != "" {
 				fmt.Fprintln(outStdout)
 				warn(result.Details)
@@ -68,18 +118,53 @@ func buildVerifyCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra
 			return fmt.Errorf("verification failed")
 		},
 	}
+	cmd.Flags().StringArrayVar(&crlFiles, "crl", nil, "Check revocation against a local CRL file (PEM or DER; repeatable)")
+	cmd.Flags().StringVar(&hostname, "hostname", "", "Require the leaf to cover this hostname or IP")
+	cmd.Flags().StringVar(&purpose, "purpose", "", "Require an extended key usage: serverAuth, clientAuth or codeSigning")
+	cmd.Flags().StringVar(&at, "at", "", "Check validity at this date/time instead of now (e.g. 2027-01-01)")
+	cmd.Flags().StringVar(&intermediates, "intermediates", "", "Untrusted intermediate certificates (PEM bundle)")
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
 	return cmd
 }
 
+// parseVerifyTime accepts a date (taken as midnight UTC) or an RFC 3339 time.
+func parseVerifyTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("--at: invalid time %q (use YYYY-MM-DD or RFC 3339)", s)
+}
+
+func printVerifyChains(result *cert.VerifyResult) {
+	for i, chain := range result.Chains {
+		fmt.Fprintln(outStdout)
+		if result.Valid {
+			kv("Chain", strconv.Itoa(i+1))
+		} else {
+			kv("Chain", "attempted path")
+		}
+		for depth, c := range chain {
+			marker := ""
+			if !result.Valid && result.FailingDepth != nil && *result.FailingDepth == depth {
+				marker = "  <- " + string(result.Reason)
+			}
+			kv(fmt.Sprintf("  [%d]", depth), c.Subject+" (until "+c.NotAfter+")"+marker)
+		}
+	}
+}
+
 func buildMatchCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
 	var keyPassword string
 	var keyPasswordStdin bool
 	var keyPasswordFile string
 	var jsonOut bool
 	cmd := &cobra.Command{
-		Use:   "match CERT KEY",
-		Short: "Check if key matches certificate",
+		Use:   "match CERT|CSR|PUBKEY KEY",
+		Short: "Check if key matches certificate (or CSR or public key)",
 		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			resolvedArgs, err := resolveInputArgs(cmd, args, 2, pathInput)
@@ -107,8 +192,15 @@ func buildMatchCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.
 				return err
 			}
 
+			subject := "certificate"
+			switch ft, _ := cert.DetectType(certPath); ft {
+			case cert.FileTypeCSR:
+				subject = "CSR"
+			case cert.FileTypePublicKey:
+				subject = "public key"
+			}
 			if !jsonOut {
-				step("Checking if key matches certificate...")
+				step("Checking if key matches " + subject + "...")
 			}
 			result, err := engine.MatchKeyToCert(context.Background(), certPath, keyPath, keyPassword)
 			if err != nil {
@@ -128,10 +220,10 @@ func buildMatchCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.
 			}
 
 			if result.Match {
-				success("Private key matches certificate")
+				success("Private key matches " + subject)
 				return nil
 			}
-			errMsg("Private key does NOT match certificate")
+			errMsg("Private key does NOT match " + subject)
 			return fmt.Errorf("key mismatch")
 		},
 	}
@@ -141,53 +233,3 @@ func buildMatchCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
 	return cmd
 }
-
-func buildExpiryCommand(engine *cert.Engine, pathInput *pathInputOptions) *cobra.Command {
-	var days int
-	var jsonOut bool
-	cmd := &cobra.Command{
-		Use:   "expiry CERT",
-		Short: "Check certificate expiration",
-		Args:  cobra.ArbitraryArgs,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			resolvedArgs, err := resolveInputArgs(cmd, args, 1, pathInput)
-			if err != nil {
-				return err
-			}
-			args = resolvedArgs
-
-			path := resolvePath(args[0])
-			if err := requireFile(path); err != nil {
-				return err
-			}
-
-			result, err := engine.Expiry(context.Background(), path, days)
-			if err != nil {
-				return err
-			}
-
-			if jsonOut {
-				enc := json.NewEncoder(cmd.OutOrStdout())
-				enc.SetEscapeHTML(false)
-				if err := enc.Encode(result); err != nil {
-					return err
-				}
-				if !result.Valid {
-					return &ExitError{Code: 1, Silent: true}
-				}
-				return nil
-			}
-
-			info("Expiration: " + result.ExpiryDate)
-			if result.Valid {
-				success("Certificate valid for at least " + strconv.Itoa(days) + " more days")
-				return nil
-			}
-			warn("Certificate expires within " + strconv.Itoa(days) + " days (or already expired)")
-			return fmt.Errorf("certificate expiring")
-		},
-	}
-	cmd.Flags().IntVar(&days, "days", 30, "Number of days to check")
-	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output JSON")
-	return cmd
-}
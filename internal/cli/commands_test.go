@@ -3,14 +3,20 @@ package cli
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nickromney/certconv/internal/cert"
 	"github.com/nickromney/certconv/test/testutil"
+	"golang.org/x/crypto/ssh"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
 )
 
 type showFakeExec struct{}
@@ -966,6 +972,69 @@ func TestVerify_JSON_ExitCodeAndOutput(t *testing.T) {
 	})
 }
 
+func TestVerify_CRL_JSON_ReportsRevokedSerial(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	set := testutil.MakeCRLSet(t, time.Now().Add(24*time.Hour))
+	cmd := NewRootCmd(cert.NewGoEngine(), nil, BuildInfo{Version: "test"})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"verify", set.RevokedPath, set.CAPath, "--crl", set.CRLPath, "--json"})
+	err := cmd.Execute()
+	code, silent, ok := ExitCode(err)
+	if !ok || code != 1 || !silent {
+		t.Fatalf("expected silent exit code 1, got %T: %v", err, err)
+	}
+	var r cert.VerifyResult
+	if err := json.Unmarshal(out.Bytes(), &r); err != nil {
+		t.Fatalf("expected valid JSON, got %q err=%v", out.String(), err)
+	}
+	if r.Valid || !strings.Contains(r.Details, "serial 1001 was revoked") {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestVerify_NativeOptions_JSON(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	set := testutil.MakeChain(t)
+	run := func(args ...string) (cert.VerifyResult, error) {
+		t.Helper()
+		// The fake exec would fail any openssl call: these options must go native.
+		cmd := NewRootCmd(cert.NewEngine(verifyFakeExec{ok: false}), nil, BuildInfo{Version: "test"})
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"verify", set.LeafPath, set.RootPath, "--json"}, args...))
+		err := cmd.Execute()
+		var r cert.VerifyResult
+		if out.Len() > 0 {
+			if jerr := json.Unmarshal(out.Bytes(), &r); jerr != nil {
+				t.Fatalf("expected valid JSON, got %q err=%v", out.String(), jerr)
+			}
+		}
+		return r, err
+	}
+
+	r, err := run("--intermediates", set.IntermediatePath, "--hostname", "app.test.local", "--purpose", "serverAuth")
+	if err != nil || !r.Valid || len(r.Chains) != 1 || len(r.Chains[0]) != 3 {
+		t.Fatalf("expected valid 3-cert chain, got %+v err=%v", r, err)
+	}
+
+	r, err = run("--intermediates", set.IntermediatePath, "--at", "2999-01-01")
+	if code, _, _ := ExitCode(err); code != 1 || r.Reason != cert.VerifyReasonExpired || r.FailingDepth == nil {
+		t.Fatalf("expected expired failure, got %+v err=%v", r, err)
+	}
+
+	_, err = run("--at", "next tuesday")
+	if code, _, ok := ExitCode(err); !ok || code != 2 {
+		t.Fatalf("expected exit 2 for bad --at, got %v", err)
+	}
+}
+
 func TestExpiry_JSON_ExitCodeAndOutput(t *testing.T) {
 	oldIsTTY := isTerminalFn
 	t.Cleanup(func() { isTerminalFn = oldIsTTY })
@@ -1254,3 +1323,728 @@ func TestShow_PathStdin_ConflictsWithSecretStdin(t *testing.T) {
 		t.Fatalf("expected exit code 2, got %T: %v", err, err)
 	}
 }
+
+func TestBackendFlag_GoBypassesExecutor(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	pair := testutil.MakeCertPair(t)
+
+	// The fake executor always reports failure, so a valid result proves the
+	// Go backend handled the request.
+	engine := cert.NewEngine(verifyFakeExec{ok: false})
+	cmd := NewRootCmd(engine, nil, BuildInfo{Version: "test"})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--backend", "go", "verify", pair.CertPath, pair.CertPath, "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var r cert.VerifyResult
+	if err := json.Unmarshal(out.Bytes(), &r); err != nil {
+		t.Fatalf("expected valid JSON, got %q err=%v", out.String(), err)
+	}
+	if !r.Valid {
+		t.Fatalf("expected valid true, got %+v", r)
+	}
+	if engine.Backend() != cert.BackendGo {
+		t.Fatalf("expected engine backend go, got %q", engine.Backend())
+	}
+}
+
+func TestBackendFlag_InvalidValue_Exit2(t *testing.T) {
+	engine := cert.NewEngine(showFakeExec{})
+	cmd := NewRootCmd(engine, nil, BuildInfo{Version: "test"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--backend", "libressl", "version"})
+	err := cmd.Execute()
+	code, _, ok := ExitCode(err)
+	if !ok || code != 2 {
+		t.Fatalf("expected exit code 2, got %T: %v", err, err)
+	}
+}
+
+func TestScan_Formats(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	set := testutil.MakeChain(t)
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := NewRootCmd(cert.NewGoEngine(), nil, BuildInfo{Version: "test"})
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"scan", set.Dir}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("--format", "ndjson", "--include", "leaf*")
+	if err != nil {
+		t.Fatalf("scan ndjson: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a certificate and a key record, got %q", out)
+	}
+	var r cert.ScanRecord
+	for _, line := range lines {
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("expected NDJSON, got %q err=%v", line, err)
+		}
+	}
+
+	out, err = run("--format", "csv")
+	if err != nil {
+		t.Fatalf("scan csv: %v", err)
+	}
+	if !strings.HasPrefix(out, "path,type,kind,index,subject,issuer,sans,not_after,days_left,fingerprint,key_algorithm,encrypted,error\n") ||
+		!strings.Contains(out, "CN=app.test.local") {
+		t.Fatalf("unexpected CSV:\n%s", out)
+	}
+
+	_, err = run("--format", "xml")
+	if code, _, ok := ExitCode(err); !ok || code != 2 {
+		t.Fatalf("expected exit code 2 for bad --format, got %T: %v", err, err)
+	}
+}
+
+func TestExpiry_Fleet_NagiosAndPrometheus(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	set := testutil.MakeChain(t) // root 10y, intermediate 60d, leaf 90d
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := NewRootCmd(cert.NewGoEngine(), nil, BuildInfo{Version: "test"})
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"expiry"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run(set.RootPath, set.LeafPath, "--warn", "30", "--crit", "7"); err != nil {
+		t.Fatalf("expected OK, got %v", err)
+	}
+	out, err := run(set.RootPath, set.LeafPath, "--warn", "95")
+	if code, _, ok := ExitCode(err); !ok || code != 1 || !strings.HasPrefix(out, "EXPIRY WARNING") {
+		t.Fatalf("expected WARNING exit 1, got %v:\n%s", err, out)
+	}
+
+	prom := filepath.Join(t.TempDir(), "certs.prom")
+	out, err = run(set.Dir, "--warn", "95", "--crit", "70", "--prometheus", prom, "--json")
+	if code, _, ok := ExitCode(err); !ok || code != 2 {
+		t.Fatalf("expected CRITICAL exit 2, got %v", err)
+	}
+	var r cert.ExpiryReport
+	if err := json.Unmarshal([]byte(out), &r); err != nil || r.Status != cert.ExpiryCritical || len(r.Entries) != 3 {
+		t.Fatalf("unexpected report %q err=%v", out, err)
+	}
+	data, err := os.ReadFile(prom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `certconv_cert_not_after_seconds{path="` + set.LeafPath + `",subject="CN = app.test.local"} `
+	if !strings.Contains(string(data), want) {
+		t.Fatalf("prometheus file missing %q:\n%s", want, data)
+	}
+
+	_, err = run(set.Dir, "--warn", "5", "--crit", "10")
+	if code, _, ok := ExitCode(err); !ok || code != 3 {
+		t.Fatalf("expected UNKNOWN exit 3 for crit > warn, got %v", err)
+	}
+}
+
+func TestExpiry_Fleet_IncludesSSHCertificates(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	_, caPriv, _ := ed25519.GenerateKey(rand.Reader)
+	ca, err := ssh.NewSignerFromKey(caPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	pub, _ := ssh.NewPublicKey(userPub)
+	c := &ssh.Certificate{
+		Key:             pub,
+		CertType:        ssh.HostCert,
+		KeyId:           "bastion-1",
+		ValidPrincipals: []string{"bastion.example.com"},
+		ValidBefore:     uint64(time.Now().Add(5 * 24 * time.Hour).Unix()),
+	}
+	if err := c.SignCert(rand.Reader, ca); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ssh_host_ed25519_key-cert.pub"), ssh.MarshalAuthorizedKey(c), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"expiry", dir, "--warn", "30", "--crit", "7", "--json"})
+	err = cmd.Execute()
+	if code, _, ok := ExitCode(err); !ok || code != 2 {
+		t.Fatalf("expected CRITICAL exit 2, got %v", err)
+	}
+	var r cert.ExpiryReport
+	if err := json.Unmarshal(out.Bytes(), &r); err != nil || len(r.Entries) != 1 {
+		t.Fatalf("unexpected report %q err=%v", out.String(), err)
+	}
+	if e := r.Entries[0]; e.Status != cert.ExpiryCritical || e.Subject != `host certificate "bastion-1"` {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+}
+
+func TestExpiry_SSHCertificateForever(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	_, caPriv, _ := ed25519.GenerateKey(rand.Reader)
+	ca, err := ssh.NewSignerFromKey(caPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	pub, _ := ssh.NewPublicKey(hostPub)
+	c := &ssh.Certificate{
+		Key:         pub,
+		CertType:    ssh.HostCert,
+		KeyId:       "bastion-1",
+		ValidBefore: ssh.CertTimeInfinity,
+	}
+	if err := c.SignCert(rand.Reader, ca); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "ssh_host_ed25519_key-cert.pub")
+	if err := os.WriteFile(p, ssh.MarshalAuthorizedKey(c), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"expiry", p, "--days", "30"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expiry: %v", err)
+	}
+	if !strings.Contains(out.String(), "Expiration: "+cert.ExpiryForever) || !strings.Contains(out.String(), "never expires") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestCovers_ExitCodesAndJSON(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	chain := testutil.MakeChain(t)
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"covers"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run(chain.LeafPath, "APP.test.local."); err != nil {
+		t.Fatalf("covered host: %v", err)
+	}
+
+	out, err := run(chain.LeafPath, "app.test.local", "www.app.test.local", "--json")
+	if code, silent, ok := ExitCode(err); !ok || code != 1 || !silent {
+		t.Fatalf("expected silent exit 1 on a miss, got %v", err)
+	}
+	var result cert.CoversResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("expected JSON, got %q err=%v", out, err)
+	}
+	if result.Covered || len(result.Hosts) != 2 || result.Hosts[0].MatchedBy != "DNS: app.test.local" || result.Hosts[1].Reason == "" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	_, err = run(chain.LeafPath)
+	if code, _, ok := ExitCode(err); !ok || code != 2 {
+		t.Fatalf("expected exit 2 without hosts, got %v", err)
+	}
+}
+
+func TestDiff_RequireSANSuperset(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	chain := testutil.MakeChain(t)
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"diff"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run(chain.LeafPath, chain.LeafPath, "--require-san-superset"); err != nil {
+		t.Fatalf("same cert: %v", err)
+	}
+
+	out, err := run(chain.LeafPath, chain.IntermediatePath, "--json")
+	if err != nil {
+		t.Fatalf("diff without --require-san-superset should succeed: %v", err)
+	}
+	var d cert.CertDiff
+	if err := json.Unmarshal([]byte(out), &d); err != nil {
+		t.Fatalf("expected JSON, got %q err=%v", out, err)
+	}
+	if d.SameKey || len(d.SANsRemoved) != 1 {
+		t.Fatalf("unexpected diff: %+v", d)
+	}
+
+	_, err = run(chain.LeafPath, chain.IntermediatePath, "--require-san-superset")
+	if code, _, ok := ExitCode(err); !ok || code != 1 {
+		t.Fatalf("expected exit 1 for dropped SAN, got %v", err)
+	}
+	_, err = run(chain.LeafPath)
+	if code, _, ok := ExitCode(err); !ok || code != 2 {
+		t.Fatalf("expected exit 2 with one argument, got %v", err)
+	}
+}
+
+func TestSplit_JSONReportsFiles(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	chain := testutil.MakeChain(t)
+	var bundle []byte
+	for _, p := range []string{chain.LeafPath, chain.IntermediatePath, chain.RootPath} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bundle = append(bundle, data...)
+	}
+	in := filepath.Join(chain.Dir, "fullchain.pem")
+	if err := os.WriteFile(in, bundle, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"split", in, filepath.Join(chain.Dir, "parts"), "--name", "{{.Index}}.crt", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	var result cert.SplitResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("expected JSON, got %q err=%v", out.String(), err)
+	}
+	if len(result.Files) != 3 || filepath.Base(result.Files[2].Path) != "3.crt" || result.Files[0].Subject == "" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	for _, f := range result.Files {
+		if _, err := os.Stat(f.Path); err != nil {
+			t.Fatalf("missing output %s: %v", f.Path, err)
+		}
+	}
+}
+
+func TestKeyConvert_EncryptThenDecrypt(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	chain := testutil.MakeChain(t)
+	pwFile := filepath.Join(chain.Dir, "pw.txt")
+	if err := os.WriteFile(pwFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	enc := filepath.Join(chain.Dir, "key.enc.pem")
+
+	run := func(stdin string, args ...string) (string, error) {
+		cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(strings.NewReader(stdin))
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("", "key", "convert", chain.LeafKeyPath, enc, "--to", "pkcs8", "--encrypt", "--kdf", "scrypt", "--new-password-file", pwFile, "--json")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	var result cert.KeyConvertResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("expected JSON, got %q err=%v", out, err)
+	}
+	if !result.Encrypted || result.KDF != cert.KeyKDFScrypt || result.Format != cert.KeyFormatPKCS8 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// An encrypted input must not be written in the clear by accident.
+	if _, err := run("s3cret", "key", "convert", enc, filepath.Join(chain.Dir, "plain.pem"), "--to", "pkcs8", "--key-password-stdin"); err == nil {
+		t.Fatal("expected refusal without --decrypt")
+	} else if code, _, ok := ExitCode(err); !ok || code != 2 {
+		t.Fatalf("expected exit 2, got %v", err)
+	}
+	if _, err := run("s3cret", "key", "convert", enc, filepath.Join(chain.Dir, "x.pem"), "--to", "pkcs8", "--encrypt", "--key-password-stdin", "--new-password-file", "-"); err == nil {
+		t.Fatal("expected error reading two secrets from stdin")
+	}
+
+	plain := filepath.Join(chain.Dir, "plain.pem")
+	if _, err := run("s3cret", "key", "convert", enc, plain, "--to", "pkcs8", "--decrypt", "--key-password-stdin"); err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if _, err := cert.ParsePrivateKeyFile(plain, ""); err != nil {
+		t.Fatalf("decrypted key unreadable: %v", err)
+	}
+}
+
+func TestKeyPasswd_RotateAndStrip(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	chain := testutil.MakeChain(t)
+	dir := chain.Dir
+	for name, pw := range map[string]string{"old.txt": "old\n", "new.txt": "new\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(pw), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(stdin string, args ...string) error {
+		cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(strings.NewReader(stdin))
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	enc := filepath.Join(dir, "enc.pem")
+	if err := run("old", "key", "passwd", chain.LeafKeyPath, enc, "--new-password-stdin"); err != nil {
+		t.Fatalf("add passphrase: %v", err)
+	}
+	rotated := filepath.Join(dir, "rotated.pem")
+	if err := run("new", "key", "passwd", enc, rotated, "--key-password-file", filepath.Join(dir, "old.txt"), "--new-password-stdin"); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if _, err := cert.ParsePrivateKeyFile(rotated, "new"); err != nil {
+		t.Fatalf("rotated key: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"key", "passwd", rotated, filepath.Join(dir, "a.pem")},
+		{"key", "passwd", rotated, filepath.Join(dir, "b.pem"), "--no-password", "--new-password-file", filepath.Join(dir, "new.txt")},
+		{"key", "passwd", rotated, filepath.Join(dir, "c.pem"), "--no-password"},
+	} {
+		if code, _, ok := ExitCode(run("", args...)); !ok || code != 2 {
+			t.Fatalf("%v: expected exit 2, got %d", args, code)
+		}
+	}
+
+	plain := filepath.Join(dir, "plain.pem")
+	if err := run("new", "key", "passwd", rotated, plain, "--key-password-stdin", "--no-password"); err != nil {
+		t.Fatalf("strip: %v", err)
+	}
+	if _, err := cert.ParsePrivateKeyFile(plain, ""); err != nil {
+		t.Fatalf("stripped key: %v", err)
+	}
+
+	// --json output stays a single JSON object; the warning goes to stderr.
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	var out, errOut bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{"key", "passwd", enc, filepath.Join(dir, "plain2.pem"), "--key-password-file", filepath.Join(dir, "old.txt"), "--no-password", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("strip --json: %v", err)
+	}
+	var r cert.KeyConvertResult
+	if err := json.Unmarshal(out.Bytes(), &r); err != nil || r.Encrypted {
+		t.Fatalf("expected unencrypted JSON result, got %q err=%v", out.String(), err)
+	}
+	if !strings.Contains(errOut.String(), "unencrypted private key") {
+		t.Fatalf("expected the unencrypted-key warning on stderr, got %q", errOut.String())
+	}
+}
+
+func TestMatch_OpenSSHKeyAgainstPublicKey(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	// The encrypted key's public half is readable without its passphrase.
+	keyPath := testutil.FixturePath(t, "openssh-ed25519-encrypted")
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"match", keyPath + ".pub", keyPath, "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("match: %v", err)
+	}
+	var r cert.MatchResult
+	if err := json.Unmarshal(out.Bytes(), &r); err != nil || !r.Match {
+		t.Fatalf("expected a match, got %q err=%v", out.String(), err)
+	}
+
+	out.Reset()
+	cmd = NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"show", keyPath, "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	var s cert.CertSummary
+	if err := json.Unmarshal(out.Bytes(), &s); err != nil {
+		t.Fatalf("expected valid JSON, got %q err=%v", out.String(), err)
+	}
+	if s.KeyType != cert.KeyTypeOpenSSH || s.OpenSSHKey == nil || !s.OpenSSHKey.Encrypted || s.OpenSSHKey.KDFRounds != 16 {
+		t.Fatalf("unexpected summary: %+v", s.OpenSSHKey)
+	}
+}
+
+func TestPFXRewrap_LegacyProfileKeepsPassword(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	pair := testutil.MakeCertPair(t)
+	in := testutil.MakePFX(t, pair, "changeit")
+	out := filepath.Join(t.TempDir(), "legacy.pfx")
+
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader("changeit\n"))
+	cmd.SetArgs([]string{"pfx", "rewrap", in, out, "--profile", "legacy", "--password-stdin", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("pfx rewrap: %v", err)
+	}
+	var result cert.PFXRewrapResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("expected valid JSON, got %q err=%v", stdout.String(), err)
+	}
+	if result.Profile != cert.PFXProfileLegacy || result.Certificates != 1 || result.Keys != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := pkcs12.DecodeChain(data, "changeit"); err != nil {
+		t.Fatalf("re-wrapped PFX should keep the password: %v", err)
+	}
+
+	cmd = NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"pfx", "rewrap", in, filepath.Join(t.TempDir(), "x.pfx"), "--profile", "rc2"})
+	if code, _, ok := ExitCode(cmd.Execute()); !ok || code != 2 {
+		t.Fatalf("expected exit 2 for an unknown profile")
+	}
+}
+
+func TestPFXInspect_ReportsMACAndPairing(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	pair := testutil.MakeCertPair(t)
+	in := testutil.MakePFX(t, pair, "changeit")
+
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader("changeit\n"))
+	cmd.SetArgs([]string{"pfx", "inspect", in, "--password-stdin", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("pfx inspect: %v", err)
+	}
+	var result cert.PFXInspection
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("expected valid JSON, got %q err=%v", stdout.String(), err)
+	}
+	if result.MAC == nil || result.MAC.Algorithm != "HMAC-SHA-256" || len(result.Bags) != 2 || len(result.Pairs) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	cmd = NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	stdout.Reset()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader("changeit\n"))
+	cmd.SetArgs([]string{"pfx", "inspect", in, "--password-stdin"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("pfx inspect: %v", err)
+	}
+	for _, want := range []string{"Safe 1:", "Local key ID:", "Older Windows"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestToK8sSecret_StdoutThenShow(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	chain := testutil.MakeChain(t)
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"to-k8s-secret", chain.LeafPath, chain.LeafKeyPath, "--name", "web-tls", "-n", "prod", "--ca", chain.RootPath})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("to-k8s-secret: %v", err)
+	}
+	manifest := filepath.Join(t.TempDir(), "web-tls.yaml")
+	if err := os.WriteFile(manifest, stdout.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd = NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	stdout.Reset()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"show", manifest, "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	var s cert.CertSummary
+	if err := json.Unmarshal(stdout.Bytes(), &s); err != nil {
+		t.Fatalf("expected valid JSON, got %q err=%v", stdout.String(), err)
+	}
+	if s.FileType != cert.FileTypeK8sSecret || len(s.K8sSecrets) != 1 {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+	if sec := s.K8sSecrets[0]; sec.ID() != "prod/web-tls" || !sec.HasKey || sec.CACerts != 1 {
+		t.Fatalf("unexpected secret: %+v", sec)
+	}
+
+	cmd = NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"to-k8s-secret", chain.LeafPath, chain.LeafKeyPath})
+	if code, _, ok := ExitCode(cmd.Execute()); !ok || code != 2 {
+		t.Fatalf("expected exit 2 without --name")
+	}
+}
+
+func TestShow_KubeconfigListsClusterAndUserCerts(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	chain := testutil.MakeChain(t)
+	root, _ := os.ReadFile(chain.RootPath)
+	leaf, _ := os.ReadFile(chain.LeafPath)
+	kubeconfig := "apiVersion: v1\nkind: Config\nclusters:\n- name: prod\n  cluster:\n    certificate-authority-data: " +
+		base64.StdEncoding.EncodeToString(root) + "\nusers:\n- name: alice\n  user:\n    client-certificate-data: " +
+		base64.StdEncoding.EncodeToString(leaf) + "\n"
+	dir := filepath.Join(t.TempDir(), ".kube")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(dir, "config")
+	if err := os.WriteFile(p, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"show", p, "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	var s cert.CertSummary
+	if err := json.Unmarshal(stdout.Bytes(), &s); err != nil {
+		t.Fatalf("expected valid JSON, got %q err=%v", stdout.String(), err)
+	}
+	if s.FileType != cert.FileTypeKubeconfig || len(s.KubeconfigEntries) != 2 {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+	if s.KubeconfigEntries[0].ID() != "cluster/prod" || s.KubeconfigEntries[1].ID() != "user/alice" {
+		t.Fatalf("unexpected entries: %+v", s.KubeconfigEntries)
+	}
+}
+
+func TestToJWK_StdoutThenShow(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	chain := testutil.MakeChain(t)
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"to-jwks", chain.LeafPath, chain.RootPath})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("to-jwks: %v", err)
+	}
+	if strings.Contains(stdout.String(), `"d"`) {
+		t.Fatalf("public JWKS should not carry private members:\n%s", stdout.String())
+	}
+	jwks := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(jwks, stdout.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd = NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	stdout.Reset()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"show", jwks, "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	var s cert.CertSummary
+	if err := json.Unmarshal(stdout.Bytes(), &s); err != nil {
+		t.Fatalf("expected valid JSON, got %q err=%v", stdout.String(), err)
+	}
+	if s.FileType != cert.FileTypeJWK || len(s.JWKs) != 2 {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+	if k := s.JWKs[0]; k.Kty != "EC" || k.Private || k.Certificates != 1 || k.Kid != k.Thumbprint {
+		t.Fatalf("unexpected key: %+v", k)
+	}
+
+	cmd = NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"to-jwk", chain.LeafPath, "--use", "verify"})
+	if code, _, ok := ExitCode(cmd.Execute()); !ok || code != 2 {
+		t.Fatalf("expected exit 2 for an unknown --use")
+	}
+}
@@ -285,6 +285,45 @@ func TestFromP7B_JSON_Output(t *testing.T) {
 	}
 }
 
+func TestToP7B_DER_JSON(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	chain := testutil.MakeChain(t)
+	out := filepath.Join(chain.Dir, "chain.p7b")
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"to-p7b", chain.IntermediatePath, chain.LeafPath, out, "--der", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("to-p7b: %v", err)
+	}
+	var result cert.ToP7BResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("expected valid JSON, got %q err=%v", stdout.String(), err)
+	}
+	if result.Encoding != "der" || len(result.Subjects) != 2 || result.Subjects[0] != "CN = app.test.local" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if certs, err := cert.ParsePKCS7Certificates(data); err != nil || len(certs) != 2 {
+		t.Fatalf("output not a 2-cert P7B: %v", err)
+	}
+
+	cmd = NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"to-p7b", out})
+	if code, _, ok := ExitCode(cmd.Execute()); !ok || code != 2 {
+		t.Fatalf("expected exit 2 without an output path")
+	}
+}
+
 func TestFromP7B_HumanOutput(t *testing.T) {
 	oldIsTTY := isTerminalFn
 	t.Cleanup(func() { isTerminalFn = oldIsTTY })
@@ -343,3 +382,356 @@ func TestFromP7B_NoArgs(t *testing.T) {
 		t.Fatalf("expected exit code 2, got %T: %v", err, err)
 	}
 }
+
+// --- from-jks CLI tests ---
+
+func TestFromJKS_NotAKeystore(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	pair := testutil.MakeCertPair(t)
+
+	engine := cert.NewDefaultEngine()
+	cmd := NewRootCmd(engine, nil, BuildInfo{Version: "test"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"from-jks", pair.CertPath, filepath.Join(pair.Dir, "out")})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "not a JKS or JCEKS keystore") {
+		t.Fatalf("expected not-a-keystore error, got %v", err)
+	}
+}
+
+func TestFromJKS_BothSecretsFromStdin_Exit2(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	engine := cert.NewDefaultEngine()
+	cmd := NewRootCmd(engine, nil, BuildInfo{Version: "test"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"from-jks", "store.jks", "out", "--password-stdin", "--key-password-file", "-"})
+
+	code, _, ok := ExitCode(cmd.Execute())
+	if !ok || code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+}
+
+func TestFromJKS_NoArgs(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	engine := cert.NewDefaultEngine()
+	cmd := NewRootCmd(engine, nil, BuildInfo{Version: "test"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"from-jks"})
+
+	err := cmd.Execute()
+	code, _, ok := ExitCode(err)
+	if !ok || code != 2 {
+		t.Fatalf("expected exit code 2, got %T: %v", err, err)
+	}
+}
+
+func TestToJKS_ShowAndFromJKS_RoundTrip(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	pair := testutil.MakeCertPair(t)
+	pwFile := filepath.Join(pair.Dir, "pw.txt")
+	if err := os.WriteFile(pwFile, []byte("changeit\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	jks := filepath.Join(pair.Dir, "app.jks")
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := NewRootCmd(cert.NewGoEngine(), nil, BuildInfo{Version: "test"})
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	var created cert.ToKeystoreResult
+	out := run("to-jks", pair.CertPath, pair.KeyPath, jks, "--password-file", pwFile, "--json")
+	if err := json.Unmarshal([]byte(out), &created); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", out, err)
+	}
+	if created.Output != jks || len(created.Aliases) != 1 || created.Aliases[0] != "test.local" {
+		t.Errorf("to-jks result = %+v", created)
+	}
+
+	var summary cert.CertSummary
+	out = run("show", jks, "--json")
+	if err := json.Unmarshal([]byte(out), &summary); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", out, err)
+	}
+	if summary.FileType != cert.FileTypeJKS || len(summary.KeystoreEntries) != 1 {
+		t.Errorf("show summary = %+v", summary)
+	}
+
+	var extracted cert.FromJKSResult
+	out = run("from-jks", jks, filepath.Join(pair.Dir, "out"), "--password-file", pwFile, "--json")
+	if err := json.Unmarshal([]byte(out), &extracted); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", out, err)
+	}
+	if len(extracted.Entries) != 1 || extracted.Entries[0].KeyFile == "" {
+		t.Fatalf("from-jks result = %+v", extracted)
+	}
+	m, err := cert.NewGoEngine().MatchKeyToCert(context.Background(), pair.CertPath, extracted.Entries[0].KeyFile, "")
+	if err != nil || !m.Match {
+		t.Errorf("extracted key does not match original cert: %v", err)
+	}
+}
+
+func TestToTruststore_JSON_ExplicitAliases(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	pair := testutil.MakeCertPair(t)
+	out := filepath.Join(pair.Dir, "trust.jks")
+
+	cmd := NewRootCmd(cert.NewGoEngine(), nil, BuildInfo{Version: "test"})
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader("changeit\n"))
+	cmd.SetArgs([]string{"to-truststore", pair.CertPath, out, "--password-stdin", "--alias", "Corp Root", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var result cert.ToKeystoreResult
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", buf.String(), err)
+	}
+	if len(result.Aliases) != 1 || result.Aliases[0] != "corp root" {
+		t.Errorf("Aliases = %v", result.Aliases)
+	}
+}
+
+func TestToTruststore_MissingPassword_Exit2(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	pair := testutil.MakeCertPair(t)
+	cmd := NewRootCmd(cert.NewGoEngine(), nil, BuildInfo{Version: "test"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"to-truststore", pair.CertPath, filepath.Join(pair.Dir, "trust.jks")})
+
+	code, _, ok := ExitCode(cmd.Execute())
+	if !ok || code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+}
+
+func TestLint_RuleConfig_FileAndFlags(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+
+	cfgHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", cfgHome)
+	if err := os.MkdirAll(filepath.Join(cfgHome, "certconv"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "lint:\n  disable: missing-sans\n  severity: long-validity=error\n"
+	if err := os.WriteFile(filepath.Join(cfgHome, "certconv", "config.yml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"lint"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("--list-rules", "--json", "--severity", "sha1-signature=error")
+	if err != nil {
+		t.Fatalf("--list-rules: %v", err)
+	}
+	var rules []struct {
+		ID              string `json:"id"`
+		DefaultSeverity string `json:"default_severity"`
+		Severity        string `json:"severity"`
+		Enabled         bool   `json:"enabled"`
+	}
+	if err := json.Unmarshal([]byte(out), &rules); err != nil {
+		t.Fatalf("expected JSON rule list, got %q err=%v", out, err)
+	}
+	got := map[string]string{}
+	for _, r := range rules {
+		state := r.DefaultSeverity + ">" + r.Severity
+		if !r.Enabled {
+			state = "off"
+		}
+		got[r.ID] = state
+	}
+	if got["missing-sans"] != "off" || got["long-validity"] != "warning>error" || got["sha1-signature"] != "warning>error" || got["weak-key"] != "error>error" {
+		t.Fatalf("unexpected rule states: %v", got)
+	}
+
+	// --enable overrides the config file's disable.
+	out, err = run("--list-rules", "--enable", "missing-sans")
+	if err != nil || !strings.Contains(out, "missing-sans") || strings.Contains(out, "disabled") {
+		t.Fatalf("--enable did not override config: %v\n%s", err, out)
+	}
+
+	_, err = run("--list-rules", "--disable", "no-such-rule")
+	if code, _, ok := ExitCode(err); !ok || code != 2 {
+		t.Fatalf("expected exit 2 for unknown rule, got %v", err)
+	}
+
+	// A malformed lint line must not silently drop the policy.
+	cfg = "lint:\n  severity: long-validity\n"
+	if err := os.WriteFile(filepath.Join(cfgHome, "certconv", "config.yml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = run("--list-rules")
+	if code, _, ok := ExitCode(err); !ok || code != 2 || !strings.Contains(err.Error(), "config") {
+		t.Fatalf("expected exit 2 for a malformed config, got %v", err)
+	}
+}
+
+func TestLint_CABFProfile_JSONCitations(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	chain := testutil.MakeChain(t)
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "test"})
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"lint"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run(chain.LeafPath, "--profile", "cabf-br", "--json")
+	if err != nil {
+		t.Fatalf("lint --profile cabf-br: %v", err)
+	}
+	var result cert.LintResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("expected JSON, got %q err=%v", out, err)
+	}
+	cited := map[string]string{}
+	for _, issue := range result.Issues {
+		cited[issue.Code] = issue.Citation
+	}
+	if cited["br-missing-aia"] != "CABF BR 7.1.2.7.7" || cited["br-internal-name"] == "" {
+		t.Fatalf("expected br-missing-aia and br-internal-name with citations, got %+v", result.Issues)
+	}
+	if _, ok := cited["long-validity"]; ok {
+		t.Fatalf("default-profile rule ran under cabf-br: %+v", result.Issues)
+	}
+
+	_, err = run(chain.LeafPath, "--profile", "mozilla")
+	if code, _, ok := ExitCode(err); !ok || code != 2 {
+		t.Fatalf("expected exit 2 for unknown profile, got %v", err)
+	}
+}
+
+func TestLint_SARIF_MultipleFiles(t *testing.T) {
+	oldIsTTY := isTerminalFn
+	t.Cleanup(func() { isTerminalFn = oldIsTTY })
+	isTerminalFn = func(_ *os.File) bool { return false }
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	chain := testutil.MakeChain(t)
+	missing := filepath.Join(chain.Dir, "missing.pem")
+	cmd := NewRootCmd(cert.NewDefaultEngine(), nil, BuildInfo{Version: "1.2.3"})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"lint", "--format", "sarif", "--profile", "cabf-br", chain.LeafPath, chain.RootPath, missing})
+	err := cmd.Execute()
+	if code, _, ok := ExitCode(err); !ok || code != 1 {
+		t.Fatalf("expected exit 1 for the unreadable file, got %v", err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Version string `json:"version"`
+					Rules   []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Artifacts []struct {
+				Location struct {
+					URI string `json:"uri"`
+				} `json:"location"`
+			} `json:"artifacts"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							Index int `json:"index"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+			Invocations []struct {
+				ExecutionSuccessful bool `json:"executionSuccessful"`
+			} `json:"invocations"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("expected SARIF JSON, got %q err=%v", out.String(), err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF envelope: %s", out.String())
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Version != "1.2.3" || len(run.Artifacts) != 3 || !strings.HasSuffix(run.Artifacts[0].Location.URI, "/leaf.pem") {
+		t.Fatalf("unexpected driver/artifacts: %s", out.String())
+	}
+	if len(run.Results) == 0 || run.Invocations[0].ExecutionSuccessful {
+		t.Fatalf("expected results and an unsuccessful invocation: %s", out.String())
+	}
+	onLeaf := false
+	for _, r := range run.Results {
+		if run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Errorf("result %s points at rule %d", r.RuleID, r.RuleIndex)
+		}
+		idx := r.Locations[0].PhysicalLocation.ArtifactLocation.Index
+		if idx == 2 {
+			t.Errorf("result %s on the unreadable file", r.RuleID)
+		}
+		onLeaf = onLeaf || (idx == 0 && r.RuleID == "br-internal-name")
+	}
+	if !onLeaf {
+		t.Errorf("expected br-internal-name on the leaf: %s", out.String())
+	}
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickromney/certconv/internal/cert"
+)
+
+// SARIF 2.1.0 log, reduced to the parts code-scanning tools read. Rules are
+// the lint rules enabled for the run; each linted file is an artifact.
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifSrcRoot = "%SRCROOT%"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+	Artifacts          []sarifArtifact             `json:"artifacts"`
+	Results            []sarifResult               `json:"results"`
+	Invocations        []sarifInvocation           `json:"invocations"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	DefaultConfiguration sarifRuleConfig     `json:"defaultConfiguration"`
+	Properties           sarifRuleProperties `json:"properties"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProperties struct {
+	Tags     []string `json:"tags"`
+	Citation string   `json:"citation,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifArtifact struct {
+	Location sarifArtifactLoc `json:"location"`
+}
+
+type sarifArtifactLoc struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+	Index     *int   `json:"index,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+// writeLintSARIF writes results as one SARIF run. Files that could not be
+// linted become tool execution notifications, and mark the run unsuccessful.
+func writeLintSARIF(w io.Writer, results []cert.LintResult, cfg cert.LintConfig, version string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "certconv",
+			Version:        version,
+			InformationURI: "https://github.com/nickromney/certconv",
+			Rules:          []sarifRule{},
+		}},
+		Artifacts:   []sarifArtifact{},
+		Results:     []sarifResult{},
+		Invocations: []sarifInvocation{{ExecutionSuccessful: true}},
+	}
+	if wd, err := os.Getwd(); err == nil {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLoc{sarifSrcRoot: {URI: sarifFileURI(wd) + "/"}}
+	}
+
+	ruleIndex := map[string]int{}
+	for _, r := range cert.LintRules() {
+		if !cfg.RuleEnabled(r) {
+			continue
+		}
+		ruleIndex[r.ID] = len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   r.ID,
+			ShortDescription:     sarifMessage{Text: r.Description},
+			DefaultConfiguration: sarifRuleConfig{Level: sarifLevel(cfg.RuleSeverity(r))},
+			Properties:           sarifRuleProperties{Tags: append([]string{"certificate"}, r.Profiles...), Citation: r.Citation},
+		})
+	}
+
+	inv := &run.Invocations[0]
+	for i, res := range results {
+		loc := sarifArtifactLocation(res.File)
+		run.Artifacts = append(run.Artifacts, sarifArtifact{Location: loc})
+		index := i
+		loc.Index = &index
+		locations := []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: loc}}}
+
+		if res.Error != "" {
+			inv.ExecutionSuccessful = false
+			inv.ToolExecutionNotifications = append(inv.ToolExecutionNotifications, sarifNotification{
+				Level:     "error",
+				Message:   sarifMessage{Text: res.Error},
+				Locations: locations,
+			})
+			continue
+		}
+		for _, issue := range res.Issues {
+			msg := issue.Message
+			if issue.Position != "" {
+				msg = issue.Position + ": " + msg
+			}
+			if issue.Citation != "" {
+				msg += " (" + issue.Citation + ")"
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    issue.Code,
+				RuleIndex: ruleIndex[issue.Code],
+				Level:     sarifLevel(issue.Severity),
+				Message:   sarifMessage{Text: msg},
+				Locations: locations,
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+func sarifLevel(s cert.LintSeverity) string {
+	if s == cert.LintError {
+		return "error"
+	}
+	return "warning"
+}
+
+// sarifArtifactLocation keeps relative paths relative to %SRCROOT% (the
+// working directory), which is how code-scanning services match results to
+// files in the repository.
+func sarifArtifactLocation(path string) sarifArtifactLoc {
+	if filepath.IsAbs(path) {
+		return sarifArtifactLoc{URI: sarifFileURI(path)}
+	}
+	rel := filepath.ToSlash(filepath.Clean(path))
+	return sarifArtifactLoc{URI: (&url.URL{Path: strings.TrimPrefix(rel, "./")}).String(), URIBaseID: sarifSrcRoot}
+}
+
+func sarifFileURI(path string) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
@@ -13,24 +13,39 @@ func resolveInputArgs(cmd *cobra.Command, args []string, expected int, pathInput
 	if expected < 0 {
 		expected = 0
 	}
-	out := append([]string(nil), args...)
-	if pathInput == nil {
-		return requireArgCount(cmd, out, expected)
+	out, err := collectInputArgs(cmd, args, pathInput)
+	if err != nil {
+		return nil, err
 	}
+	return requireArgCount(cmd, out, expected)
+}
 
-	if pathInput.pathStdin || pathInput.path0Stdin {
-		if usesStdinForSecrets(cmd) {
-			return nil, &ExitError{Code: 2, Msg: "--path-stdin/--path0-stdin cannot be combined with secret stdin flags"}
-		}
-		fromStdin, err := readPathsFromStdin(cmd, pathInput.path0Stdin)
-		if err != nil {
-			return nil, err
-		}
-		out = append(fromStdin, out...)
-		return requireArgCount(cmd, out, expected)
+// resolveInputPaths is resolveInputArgs for commands that take one or more
+// paths.
+func resolveInputPaths(cmd *cobra.Command, args []string, pathInput *pathInputOptions) ([]string, error) {
+	out, err := collectInputArgs(cmd, args, pathInput)
+	if err != nil {
+		return nil, err
 	}
+	if len(out) == 0 {
+		return nil, &ExitError{Code: 2, Msg: fmt.Sprintf("%s requires at least 1 argument", cmd.CommandPath())}
+	}
+	return out, nil
+}
 
-	return requireArgCount(cmd, out, expected)
+func collectInputArgs(cmd *cobra.Command, args []string, pathInput *pathInputOptions) ([]string, error) {
+	out := append([]string(nil), args...)
+	if pathInput == nil || !(pathInput.pathStdin || pathInput.path0Stdin) {
+		return out, nil
+	}
+	if usesStdinForSecrets(cmd) {
+		return nil, &ExitError{Code: 2, Msg: "--path-stdin/--path0-stdin cannot be combined with secret stdin flags"}
+	}
+	fromStdin, err := readPathsFromStdin(cmd, pathInput.path0Stdin)
+	if err != nil {
+		return nil, err
+	}
+	return append(fromStdin, out...), nil
 }
 
 func requireArgCount(cmd *cobra.Command, args []string, expected int) ([]string, error) {
@@ -41,13 +56,13 @@ func requireArgCount(cmd *cobra.Command, args []string, expected int) ([]string,
 }
 
 func usesStdinForSecrets(cmd *cobra.Command) bool {
-	for _, name := range []string{"password-stdin", "key-password-stdin"} {
+	for _, name := range []string{"password-stdin", "key-password-stdin", "new-password-stdin"} {
 		f := cmd.Flags().Lookup(name)
 		if f != nil && strings.TrimSpace(f.Value.String()) == "true" {
 			return true
 		}
 	}
-	for _, name := range []string{"password-file", "key-password-file"} {
+	for _, name := range []string{"password-file", "key-password-file", "new-password-file"} {
 		f := cmd.Flags().Lookup(name)
 		if f != nil && strings.TrimSpace(f.Value.String()) == "-" {
 			return true
@@ -20,16 +20,21 @@ type discoverableEntry struct {
 }
 
 var discoverableExtensions = map[string]bool{
-	".pem": true,
-	".der": true,
-	".pfx": true,
-	".p12": true,
-	".cer": true,
-	".crt": true,
-	".key": true,
-	".pub": true,
-	".p7b": true,
-	".p7c": true,
+	".pem":   true,
+	".der":   true,
+	".pfx":   true,
+	".p12":   true,
+	".cer":   true,
+	".crt":   true,
+	".key":   true,
+	".pub":   true,
+	".p7b":   true,
+	".p7c":   true,
+	".csr":   true,
+	".crl":   true,
+	".req":   true,
+	".jks":   true,
+	".jceks": true,
 }
 
 func runAutoPathAction(cmd *cobra.Command, engine *cert.Engine, path string) error {
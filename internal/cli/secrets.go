@@ -36,6 +36,64 @@ func loadSecret(cmd *cobra.Command, value string, fromStdin bool, fromFile strin
 	return value, nil
 }
 
+// secretFlag is one secret supplied as --NAME, --NAME-stdin or --NAME-file.
+type secretFlag struct {
+	name  string
+	value string
+	stdin bool
+	file  string
+}
+
+func (f *secretFlag) register(cmd *cobra.Command, shorthand, usage, stdinUsage, fileUsage string) {
+	cmd.Flags().StringVarP(&f.value, f.name, shorthand, "", usage)
+	cmd.Flags().BoolVar(&f.stdin, f.name+"-stdin", false, stdinUsage)
+	cmd.Flags().StringVar(&f.file, f.name+"-file", "", fileUsage)
+}
+
+// given reports whether any of the three flags was supplied.
+func (f *secretFlag) given() bool {
+	return strings.TrimSpace(f.value) != "" || f.stdin || strings.TrimSpace(f.file) != ""
+}
+
+func (f *secretFlag) fromStdin() bool {
+	return f.stdin || strings.TrimSpace(f.file) == "-"
+}
+
+// load resolves the secret into value, warning when it was passed inline.
+func (f *secretFlag) load(cmd *cobra.Command) error {
+	inlineProvided := strings.TrimSpace(f.value) != ""
+	v, err := loadSecret(cmd, f.value, f.stdin, f.file, f.name, f.name+"-stdin", f.name+"-file")
+	if err != nil {
+		return err
+	}
+	f.value = v
+	if inlineProvided && strings.TrimSpace(v) != "" && !f.stdin && strings.TrimSpace(f.file) == "" {
+		warnInlineSecretFlag(f.name)
+	}
+	return nil
+}
+
+// secretPair is a current and a replacement secret, e.g. the old and new
+// passwords when re-encrypting a key or PFX.
+type secretPair struct {
+	current, next secretFlag
+}
+
+// load resolves both secrets. stdin can only be consumed once, so at most
+// one of them may be read from it.
+func (p *secretPair) load(cmd *cobra.Command) error {
+	if p.current.fromStdin() && p.next.fromStdin() {
+		return &ExitError{
+			Code: 2,
+			Msg:  fmt.Sprintf("only one secret may be read from stdin; use --%s-file for one secret and --%s-file for the other", p.current.name, p.next.name),
+		}
+	}
+	if err := p.current.load(cmd); err != nil {
+		return err
+	}
+	return p.next.load(cmd)
+}
+
 func readSecretFromStdin(cmd *cobra.Command, flagName string) (string, error) {
 	// Intentionally gate on the real stdin TTY-ness to avoid accidental hangs.
 	// Users should pipe/redirect.
@@ -9,7 +9,8 @@ import (
 	"strings"
 )
 
-// Config is intentionally small and flat. It's a TUI-focused config for now.
+// Config is intentionally small and flat. It is mostly TUI settings, plus
+// local-ca directories and lint rule overrides for the CLI.
 //
 // File location: ~/.config/certconv/config.yml (or $XDG_CONFIG_HOME/certconv/config.yml)
 type Config struct {
@@ -23,6 +24,7 @@ type Config struct {
 	Theme            string // "default", "github-dark", "github-dark-high-contrast", "terminal"
 	FocusIndicator   string // "color", "marker", "both"
 	Keys             KeysConfig
+	Lint             LintConfig
 }
 
 type KeysConfig struct {
@@ -35,6 +37,16 @@ type KeysConfig struct {
 	ResizeSummaryMore string
 }
 
+// LintConfig holds the profile and rule overrides for "certconv lint". The
+// profile, rule IDs and severities are validated by the lint command, which
+// knows the rules.
+type LintConfig struct {
+	Profile  string
+	Enable   []string
+	Disable  []string
+	Severity map[string]string // rule ID -> "error" or "warning"
+}
+
 func Default() Config {
 	return Config{
 		AutoMatchKey:     true,
@@ -140,6 +152,7 @@ func Load() (Config, error) {
 	if patch.FocusIndicator != "" {
 		cfg.FocusIndicator = patch.FocusIndicator
 	}
+	cfg.Lint = patch.Lint
 
 	return cfg, nil
 }
@@ -155,13 +168,14 @@ type partialConfig struct {
 	Theme            string
 	FocusIndicator   string
 	Keys             KeysConfig
+	Lint             LintConfig
 	autoMatchSet     bool
 	eagerViewsSet    bool
 }
 
 // parseYAMLSubset parses a very small subset of YAML:
 // - top-level `key: value`
-// - one nested map: `keys:` with indented `next_view: n` etc.
+// - nested maps: `keys:` with indented `next_view: n` etc., and `lint:`
 // - comments with '#'
 func parseYAMLSubset(data []byte) (partialConfig, error) {
 	var out partialConfig
@@ -220,18 +234,36 @@ func parseYAMLSubset(data []byte) (partialConfig, error) {
 			}
 			continue
 		}
+		if section == "lint" {
+			switch k {
+			case "profile":
+				out.Lint.Profile = v
+			case "enable":
+				out.Lint.Enable = append(out.Lint.Enable, splitList(v)...)
+			case "disable":
+				out.Lint.Disable = append(out.Lint.Disable, splitList(v)...)
+			case "severity":
+				for _, item := range splitList(v) {
+					rule, sev, ok := strings.Cut(item, "=")
+					rule, sev = strings.TrimSpace(rule), strings.TrimSpace(sev)
+					if !ok || rule == "" || sev == "" {
+						return out, fmt.Errorf("lint severity must be RULE=SEVERITY, got %q", item)
+					}
+					if out.Lint.Severity == nil {
+						out.Lint.Severity = map[string]string{}
+					}
+					out.Lint.Severity[rule] = sev
+				}
+			}
+			continue
+		}
 
 		switch k {
 		case "certs_dir":
 			out.CertsDir = v
 		case "local_ca_dirs":
 			// Comma-separated list of directories
-			for _, d := range strings.Split(v, ",") {
-				d = strings.TrimSpace(d)
-				if d != "" {
-					out.LocalCADirs = append(out.LocalCADirs, d)
-				}
-			}
+			out.LocalCADirs = append(out.LocalCADirs, splitList(v)...)
 		case "auto_match_key":
 			b, ok := parseBool(v)
 			if !ok {
@@ -298,6 +330,17 @@ func splitKV(s string) (key, val string, ok bool) {
 	return key, val, true
 }
 
+// splitList splits a comma-separated value, dropping empty items.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 func countLeadingSpaces(s string) int {
 	n := 0
 	for n < len(s) && s[n] == ' ' {
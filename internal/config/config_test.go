@@ -60,3 +60,34 @@ func TestParseYAMLSubset_DefaultsAutoMatchWhenUnset(t *testing.T) {
 		t.Fatalf("expected EagerViews default %v, got %v", Default().EagerViews, got.EagerViews)
 	}
 }
+
+func TestParseYAMLSubset_LintSection(t *testing.T) {
+	in := []byte(`
+lint:
+  profile: cabf-br
+  disable: long-validity, missing-sans
+  enable: ca-as-leaf
+  severity: sha1-signature=error, weak-key = warning
+theme: terminal
+`)
+	got, err := parseYAMLSubset(in)
+	if err != nil {
+		t.Fatalf("parseYAMLSubset error: %v", err)
+	}
+	if len(got.Lint.Disable) != 2 || got.Lint.Disable[1] != "missing-sans" || len(got.Lint.Enable) != 1 {
+		t.Fatalf("lint enable/disable: got %+v", got.Lint)
+	}
+	if got.Lint.Profile != "cabf-br" {
+		t.Fatalf("lint profile: got %q", got.Lint.Profile)
+	}
+	if got.Lint.Severity["sha1-signature"] != "error" || got.Lint.Severity["weak-key"] != "warning" {
+		t.Fatalf("lint severity: got %+v", got.Lint.Severity)
+	}
+	if got.Theme != "terminal" {
+		t.Fatalf("theme after lint section: got %q", got.Theme)
+	}
+
+	if _, err := parseYAMLSubset([]byte("lint:\n  severity: long-validity\n")); err == nil {
+		t.Fatal("expected error for severity without =")
+	}
+}
@@ -37,12 +37,15 @@ func (ap *actionPanel) SetActions(ft cert.FileType) {
 	case cert.FileTypeCert, cert.FileTypeCombined:
 		ap.actions = []action{
 			{Name: "Check Expiry", Key: "e", ID: "expiry"},
+			{Name: "Check Hostname", Key: "h", ID: "covers"},
 			{Name: "Match Keys", Key: "m", ID: "match"},
 			{Name: "Verify Chain", Key: "v", ID: "verify"},
+			{Name: "Show as P7B", Key: "b", ID: "p7b"},
 		}
 	case cert.FileTypeDER:
 		ap.actions = []action{
 			{Name: "Check Expiry", Key: "e", ID: "expiry"},
+			{Name: "Check Hostname", Key: "h", ID: "covers"},
 			{Name: "Match Keys", Key: "m", ID: "match"},
 		}
 	}
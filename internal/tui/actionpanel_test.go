@@ -11,7 +11,9 @@ func TestActionPanel_SetActions_CertOrderAndKeys(t *testing.T) {
 	ap.SetActions(cert.FileTypeCert)
 
 	want := []action{
+		{Name: "Show as P7B", Key: "b", ID: "p7b"},
 		{Name: "Check Expiry", Key: "e", ID: "expiry"},
+		{Name: "Check Hostname", Key: "h", ID: "covers"},
 		{Name: "Match Keys", Key: "m", ID: "match"},
 		{Name: "Verify Chain", Key: "v", ID: "verify"},
 	}
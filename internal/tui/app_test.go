@@ -380,6 +380,43 @@ func TestUpdateKey_CycleContentPane_NextAndPrev(t *testing.T) {
 	}
 }
 
+func TestUpdateKey_CycleContentPane_PFXStructure(t *testing.T) {
+	pfxPath := testutil.MakePFX(t, testutil.MakeCertPair(t), "pw")
+	cp := newContentPane(64)
+	cp.SetParsed("PARSED")
+	cp.SetMode(contentPaneModeParsed)
+
+	m := Model{
+		selectedFile: pfxPath,
+		selectedType: cert.FileTypePFX,
+		focused:      PaneContent,
+		keyNextView:  "n",
+		keyPrevView:  "p",
+		contentPane:  cp,
+		pfxPasswords: map[string]string{pfxPath: "pw"},
+	}
+
+	next, cmd := m.updateKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m1 := next.(Model)
+	if m1.contentPane.Mode() != contentPaneModePFXStructure {
+		t.Fatalf("expected PFX structure, got %v", m1.contentPane.Mode())
+	}
+	if cmd == nil {
+		t.Fatal("expected a load command")
+	}
+	msg, ok := cmd().(ContentPFXStructureMsg)
+	if !ok || msg.Err != nil {
+		t.Fatalf("unexpected message: %#v", msg)
+	}
+	m1.updateContentPFXStructure(msg)
+	got := m1.contentPane.CopyText()
+	for _, want := range []string{"MAC: HMAC-SHA-256", "pkcs8ShroudedKeyBag", "Pairing:"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in PFX structure view:\n%s", want, got)
+		}
+	}
+}
+
 func TestCertSummary_PFXPasswordDoesNotPromptOnSummaryError(t *testing.T) {
 	m := Model{
 		selectedFile: "/tmp/test.pfx",
@@ -11,6 +11,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/nickromney/certconv/internal/cert"
 	"github.com/nickromney/certconv/internal/config"
+	"github.com/nickromney/certconv/test/testutil"
 )
 
 type tuiFakeExec struct{}
@@ -568,3 +569,72 @@ func TestUpdate_FilePicker_AtAliasOpensPicker(t *testing.T) {
 		t.Fatalf("expected home picker root %q, got %q", home, m.fzfPanel.rootDir)
 	}
 }
+
+func TestUpdate_InputMode_CoversReportsEachHost(t *testing.T) {
+	chain := testutil.MakeChain(t)
+	dir := chain.Dir
+	certPath := chain.LeafPath
+
+	m := Model{
+		engine:       cert.NewDefaultEngine(),
+		filePane:     filePane{dir: dir},
+		contentPane:  newContentPane(64),
+		infoPane:     newInfoPane(),
+		actionPanel:  newActionPanel(),
+		helpPane:     newHelpPane(),
+		selectedFile: certPath,
+	}
+
+	next, _ := m.Update(ActionSelectedMsg{ID: "covers"})
+	m = next.(Model)
+	if !m.input.active() || m.input.action != "covers" {
+		t.Fatalf("expected covers prompt active, got %+v", m.input)
+	}
+
+	next, cmd := m.processInputResult("covers", "app.test.local, other.invalid")
+	m = next.(Model)
+	if cmd == nil {
+		t.Fatalf("expected cmd for covers")
+	}
+	ar, ok := cmd().(ActionResultMsg)
+	if !ok {
+		t.Fatalf("expected ActionResultMsg, got %T", ar)
+	}
+	if !ar.IsErr || !strings.Contains(ar.Message, "1 of 2") {
+		t.Fatalf("expected one host reported uncovered, got %+v", ar)
+	}
+	if !strings.Contains(ar.Details, "app.test.local: covered by DNS: app.test.local") || !strings.Contains(ar.Details, "other.invalid: not covered") {
+		t.Fatalf("expected per-host details, got %q", ar.Details)
+	}
+}
+
+func TestHandleAction_P7BShowsBundle(t *testing.T) {
+	chain := testutil.MakeChain(t)
+	m := Model{
+		engine:       cert.NewDefaultEngine(),
+		filePane:     filePane{dir: chain.Dir},
+		contentPane:  newContentPane(64),
+		selectedFile: chain.LeafPath,
+	}
+
+	cmd := m.handleAction("p7b")
+	if cmd == nil || m.input.active() {
+		t.Fatalf("expected a cmd and no prompt, got input %+v", m.input)
+	}
+	ar, ok := cmd().(ActionResultMsg)
+	if !ok || ar.IsErr {
+		t.Fatalf("expected a successful ActionResultMsg, got %+v", ar)
+	}
+	if !strings.Contains(ar.Details, "-----BEGIN PKCS7-----") || !strings.Contains(ar.Details, "CN = app.test.local") {
+		t.Fatalf("expected PKCS7 PEM in details, got %q", ar.Details)
+	}
+	entries, err := os.ReadDir(chain.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".p7b") {
+			t.Fatalf("TUI action wrote %s", e.Name())
+		}
+	}
+}
@@ -21,6 +21,7 @@ const (
 	contentPaneModeDERBase64
 	contentPaneModePFXBase64
 	contentPaneModeParsed
+	contentPaneModePFXStructure
 	contentPaneModeCount // sentinel for wrapping
 )
 
@@ -52,6 +53,8 @@ func (m contentPaneMode) Title() string {
 		return "PFX (Base64)"
 	case contentPaneModeParsed:
 		return "Parsed Certificate"
+	case contentPaneModePFXStructure:
+		return "PFX Structure"
 	default:
 		return "?"
 	}
@@ -85,6 +88,8 @@ type contentPane struct {
 	pfxBase64Text            string
 	parsedText               string
 	parsedErr                string
+	pfxStructureText         string
+	pfxStructureErr          string
 	oneLineErr               string
 	base64Err                string
 	derBase64Err             string
@@ -152,6 +157,8 @@ func (cp *contentPane) ResetForFile() {
 	cp.modulusErr = ""
 	cp.parsedText = ""
 	cp.parsedErr = ""
+	cp.pfxStructureText = ""
+	cp.pfxStructureErr = ""
 	cp.oneLineText = ""
 	cp.base64Text = ""
 	cp.derBase64Text = ""
@@ -237,6 +244,28 @@ func (cp *contentPane) SetParsedError(err string) {
 	}
 }
 
+func (cp *contentPane) HasPFXStructure() bool {
+	return strings.TrimSpace(cp.pfxStructureText) != "" || strings.TrimSpace(cp.pfxStructureErr) != ""
+}
+
+func (cp *contentPane) SetPFXStructure(text string) {
+	cp.pfxStructureText = text
+	cp.pfxStructureErr = ""
+	cp.loading = false
+	if cp.mode == contentPaneModePFXStructure {
+		cp.refreshViewport(true)
+	}
+}
+
+func (cp *contentPane) SetPFXStructureError(err string) {
+	cp.pfxStructureText = ""
+	cp.pfxStructureErr = err
+	cp.loading = false
+	if cp.mode == contentPaneModePFXStructure {
+		cp.refreshViewport(true)
+	}
+}
+
 func (cp *contentPane) SetModulusError(err string) {
 	cp.modulusText = ""
 	cp.modulusErr = err
@@ -417,6 +446,8 @@ func (cp *contentPane) CanCopy() bool {
 		return strings.TrimSpace(cp.modulusText) != ""
 	case contentPaneModeParsed:
 		return strings.TrimSpace(cp.parsedText) != ""
+	case contentPaneModePFXStructure:
+		return strings.TrimSpace(cp.pfxStructureText) != ""
 	case contentPaneModeOneLine:
 		return strings.TrimSpace(cp.oneLineText) != ""
 	case contentPaneModeBase64:
@@ -442,6 +473,8 @@ func (cp *contentPane) CopyText() string {
 		return cp.modulusText
 	case contentPaneModeParsed:
 		return cp.parsedText
+	case contentPaneModePFXStructure:
+		return cp.pfxStructureText
 	case contentPaneModeOneLine:
 		return cp.oneLineText
 	case contentPaneModeBase64:
@@ -489,6 +522,8 @@ func (m contentPaneMode) CopyLabel() string {
 		return "PFX base64"
 	case contentPaneModeParsed:
 		return "Parsed certificate"
+	case contentPaneModePFXStructure:
+		return "PFX structure"
 	default:
 		return "Content"
 	}
@@ -535,6 +570,14 @@ func (cp *contentPane) refreshViewport(resetScroll bool) {
 		} else {
 			text = cp.parsedText
 		}
+	case contentPaneModePFXStructure:
+		if strings.TrimSpace(cp.pfxStructureErr) != "" {
+			text = errorStyle.Render(cp.pfxStructureErr)
+		} else if strings.TrimSpace(cp.pfxStructureText) == "" {
+			text = lipgloss.NewStyle().Foreground(paneDimColor).Render("Not generated. Cycle views to generate.")
+		} else {
+			text = cp.pfxStructureText
+		}
 	case contentPaneModeOneLine:
 		if strings.TrimSpace(cp.oneLineErr) != "" {
 			text = errorStyle.Render(cp.oneLineErr)
@@ -30,16 +30,25 @@ type filePane struct {
 }
 
 var certFileExtensions = map[string]bool{
-	".pem": true,
-	".der": true,
-	".pfx": true,
-	".p12": true,
-	".cer": true,
-	".crt": true,
-	".key": true,
-	".pub": true,
-	".p7b": true,
-	".p7c": true,
+	".pem":   true,
+	".der":   true,
+	".pfx":   true,
+	".p12":   true,
+	".cer":   true,
+	".crt":   true,
+	".key":   true,
+	".pub":   true,
+	".p7b":   true,
+	".p7c":   true,
+	".csr":   true,
+	".crl":   true,
+	".req":   true,
+	".jks":   true,
+	".jceks": true,
+	".yaml":  true,
+	".yml":   true,
+	".jwk":   true,
+	".json":  true,
 }
 
 func newFilePane(startDir string, showAll ...bool) filePane {
@@ -86,7 +95,7 @@ func (fp *filePane) loadDir() {
 		if e.IsDir() {
 			dirs = append(dirs, fileEntry{name: e.Name() + "/", path: path, isDir: true})
 		} else {
-			if !fp.showAll && !isCertLikeFile(e.Name()) {
+			if !fp.showAll && !isCertLikeFile(e.Name()) && !isKubeconfigName(fp.dir, e.Name()) {
 				continue
 			}
 			files = append(files, fileEntry{name: e.Name(), path: path, isDir: false})
@@ -105,6 +114,12 @@ func isCertLikeFile(name string) bool {
 	return certFileExtensions[ext]
 }
 
+// isKubeconfigName reports whether name is a kubeconfig by convention:
+// kubectl's default .kube/config, which has no extension, or "kubeconfig".
+func isKubeconfigName(dir, name string) bool {
+	return name == "kubeconfig" || (name == "config" && filepath.Base(dir) == ".kube")
+}
+
 func (fp *filePane) ToggleShowAll() bool {
 	fp.showAll = !fp.showAll
 	fp.loadDir()
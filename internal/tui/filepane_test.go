@@ -83,6 +83,27 @@ func TestFilePane_DefaultsToCertLikeExtensionsOnly(t *testing.T) {
 	}
 }
 
+func TestFilePane_ShowsKubeconfigWithoutExtension(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".kube")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"config", "cache"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fp := newFilePane(dir)
+	var names []string
+	for _, e := range fp.entries {
+		names = append(names, e.name)
+	}
+	if !containsString(names, "config") || containsString(names, "cache") {
+		t.Fatalf("expected only .kube/config to be listed: %v", names)
+	}
+}
+
 func TestFilePane_ToggleShowAll(t *testing.T) {
 	dir := t.TempDir()
 	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), []byte("x"), 0o644); err != nil {
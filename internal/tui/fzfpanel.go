@@ -18,17 +18,26 @@ type pickerEntry struct {
 }
 
 var pickerFileExtensions = map[string]bool{
-	".pem": true,
-	".der": true,
-	".pfx": true,
-	".p12": true,
-	".cer": true,
-	".crt": true,
-	".key": true,
-	".pub": true,
-	".b64": true,
-	".p7b": true,
-	".p7c": true,
+	".pem":   true,
+	".der":   true,
+	".pfx":   true,
+	".p12":   true,
+	".cer":   true,
+	".crt":   true,
+	".key":   true,
+	".pub":   true,
+	".b64":   true,
+	".p7b":   true,
+	".p7c":   true,
+	".csr":   true,
+	".crl":   true,
+	".req":   true,
+	".jks":   true,
+	".jceks": true,
+	".yaml":  true,
+	".yml":   true,
+	".jwk":   true,
+	".json":  true,
 }
 
 // fzfPanel is an in-app floating picker with basic fzf-like filtering.
@@ -404,7 +413,7 @@ func listPickerEntries(root string) ([]pickerEntry, error) {
 			}
 			continue
 		}
-		if pickerFileExtensions[strings.ToLower(filepath.Ext(name))] {
+		if pickerFileExtensions[strings.ToLower(filepath.Ext(name))] || isKubeconfigName(root, name) {
 			files = append(files, pickerEntry{name: name, path: path, isDir: false})
 		}
 	}
@@ -430,7 +439,7 @@ func certFilesInDir(dir, prefix string) ([]pickerEntry, error) {
 			continue
 		}
 		name := e.Name()
-		if pickerFileExtensions[strings.ToLower(filepath.Ext(name))] {
+		if pickerFileExtensions[strings.ToLower(filepath.Ext(name))] || isKubeconfigName(dir, name) {
 			out = append(out, pickerEntry{
 				name:  prefix + name,
 				path:  filepath.Join(dir, name),
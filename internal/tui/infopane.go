@@ -150,6 +150,57 @@ func (ip *infoPane) summaryKVs() []summaryKV {
 	switch s.FileType {
 	case cert.FileTypeKey:
 		add("Key Type", string(s.KeyType))
+		if k := s.OpenSSHKey; k != nil {
+			add("Algorithm", k.Algorithm)
+			add("Bits", fmt.Sprint(k.Bits))
+			add("Fingerprint", k.Fingerprint)
+			add("Comment", k.Comment)
+			if k.Encrypted {
+				add("Encrypted", k.Cipher+", "+k.KDF)
+			} else {
+				add("Encrypted", "no")
+			}
+		}
+	case cert.FileTypeCSR:
+		add("Subject", s.Subject)
+		if len(s.SANs) > 0 {
+			add("SANs", cert.FormatSANsShort(s.SANs))
+		}
+		sep()
+		add("Public Key", s.PublicKeyInfo)
+		add("Sig Algo", s.SignatureAlgorithm)
+		if s.SignatureValid != nil {
+			if *s.SignatureValid {
+				add("Signature", "Valid")
+			} else {
+				add("Signature", "INVALID")
+			}
+		}
+		if len(s.RequestedExtensions) > 0 {
+			add("Requested", strings.Join(s.RequestedExtensions, ", "))
+		}
+	case cert.FileTypeCRL:
+		add("Issuer", s.Issuer)
+		sep()
+		add("This Update", s.ThisUpdate)
+		add("Next Update", s.NextUpdate)
+		add("CRL Number", s.CRLNumber)
+		add("Sig Algo", s.SignatureAlgorithm)
+		add("Revoked", fmt.Sprint(len(s.Revoked)))
+	case cert.FileTypeSSHCert:
+		if c := s.SSHCert; c != nil {
+			add("Key ID", c.KeyID)
+			add("Cert Type", c.Type)
+			add("Principals", strings.Join(c.Principals, ", "))
+			sep()
+			add("Valid After", c.ValidAfter)
+			add("Valid Before", c.ValidBefore)
+			sep()
+			add("Serial", s.Serial)
+			add("Key", c.KeyAlgorithm+" "+c.KeyFingerprint)
+			add("Signing CA", c.CAFingerprint)
+			add("Extensions", strings.Join(c.Extensions, ", "))
+		}
 	case cert.FileTypePublicKey:
 		if strings.TrimSpace(s.PublicKeyAlgorithm) != "" {
 			add("Key Type", s.PublicKeyAlgorithm)
@@ -179,6 +230,37 @@ func (ip *infoPane) summaryKVs() []summaryKV {
 		if s.IsCA {
 			add("CA", "Yes")
 		}
+		if s.FileType == cert.FileTypeJKS {
+			sep()
+			add("Keystore", fmt.Sprintf("%s (%d entries)", s.KeystoreType, len(s.KeystoreEntries)))
+			for _, e := range s.KeystoreEntries {
+				add(e.Alias, string(e.Type))
+			}
+		}
+		if s.FileType == cert.FileTypeK8sSecret {
+			sep()
+			add("Secrets", fmt.Sprint(len(s.K8sSecrets)))
+			for _, sec := range s.K8sSecrets {
+				add(sec.ID(), sec.Subject)
+			}
+		}
+		if s.FileType == cert.FileTypeKubeconfig {
+			sep()
+			for _, e := range s.KubeconfigEntries {
+				add(e.ID(), e.NotAfter)
+			}
+		}
+		if s.FileType == cert.FileTypeJWK {
+			sep()
+			add("Keys", fmt.Sprint(len(s.JWKs)))
+			for _, k := range s.JWKs {
+				desc := k.Key
+				if k.Private {
+					desc += " (private)"
+				}
+				add(k.Kid, desc)
+			}
+		}
 	}
 
 	return kvs
@@ -99,6 +99,23 @@ func (m *Model) handleAction(id string) tea.Cmd {
 		m.input.begin("text", "CA bundle path: ", "verify")
 		return nil
 
+	case "covers":
+		m.input.begin("text", "Hostnames or IPs: ", "covers")
+		return nil
+
+	case "p7b":
+		// The TUI writes no files: show the PEM bundle in the last-action view.
+		path := m.selectedFile
+		return func() tea.Msg {
+			data, r, err := cert.BuildP7B([]string{path}, false)
+			if err != nil {
+				return ActionResultMsg{Message: err.Error(), Details: err.Error(), IsErr: true}
+			}
+			msg := fmt.Sprintf("PKCS#7 bundle of %d certificate(s), leaf first", len(r.Subjects))
+			details := msg + ":\n  " + strings.Join(r.Subjects, "\n  ") + "\n\n" + string(data)
+			return ActionResultMsg{Message: msg, Details: details}
+		}
+
 	case "match":
 		m.input.begin("text", "Private key path: ", "match-key")
 		m.input.context = map[string]string{}
@@ -189,6 +206,9 @@ func (m Model) processInputResult(action, value string) (tea.Model, tea.Cmd) {
 			if err != nil {
 				return ActionResultMsg{Message: err.Error(), Details: err.Error(), IsErr: true}
 			}
+			if r.Valid && r.ExpiryDate == cert.ExpiryForever {
+				return ActionResultMsg{Message: "Valid forever", Details: "Valid forever"}
+			}
 			if r.Valid {
 				msg := fmt.Sprintf("Valid for %d more days (expires %s)", r.DaysLeft, r.ExpiryDate)
 				return ActionResultMsg{Message: msg, Details: msg}
@@ -216,6 +236,9 @@ func (m Model) processInputResult(action, value string) (tea.Model, tea.Cmd) {
 		case contentPaneModeDetails, contentPaneModeDetailsNoBag:
 			m.contentPane.SetLoading()
 			cmds = append(cmds, m.loadContentDetails(path))
+		case contentPaneModePFXStructure:
+			m.contentPane.SetLoading()
+			cmds = append(cmds, m.loadContentPFXStructure(path))
 		}
 		return m, tea.Batch(cmds...)
 
@@ -227,23 +250,42 @@ func (m Model) processInputResult(action, value string) (tea.Model, tea.Cmd) {
 				return ActionResultMsg{Message: err.Error(), Details: err.Error(), IsErr: true}
 			}
 			if r.Valid {
-				details := r.Output
+				details := verifyResultText(r)
 				if strings.TrimSpace(details) == "" {
 					details = "Certificate chain verified"
 				}
 				return ActionResultMsg{Message: "Certificate chain verified", Details: details}
 			}
 			msg := "Chain verification failed"
+			if r.Reason != "" && r.FailingDepth != nil {
+				msg += fmt.Sprintf(" (%s at depth %d)", r.Reason, *r.FailingDepth)
+			}
 			if r.Details != "" {
 				msg += ": " + r.Details
 			}
-			details := r.Output
+			details := verifyResultText(r)
 			if strings.TrimSpace(details) == "" {
 				details = msg
 			}
 			return ActionResultMsg{Message: msg, Details: details, IsErr: true}
 		}
 
+	case "covers":
+		hosts := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+		if len(hosts) == 0 {
+			m.input.begin("text", "Hostnames or IPs: ", "covers")
+			m.input.note = "Enter at least one hostname"
+			return m, nil
+		}
+		certPath := m.selectedFile
+		return m, func() tea.Msg {
+			r, err := cert.CoversFile(certPath, "", hosts, false)
+			if err != nil {
+				return ActionResultMsg{Message: err.Error(), Details: err.Error(), IsErr: true}
+			}
+			return coversResultMsg(r)
+		}
+
 	case "match-key":
 		if m.input.context == nil {
 			m.input.context = map[string]string{}
@@ -548,3 +590,55 @@ func pathExistsCheck(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// coversResultMsg summarises a hostname coverage check: one line per host
+// with the SAN that matched it or the reason nothing did.
+func coversResultMsg(r *cert.CoversResult) ActionResultMsg {
+	var b strings.Builder
+	missed := 0
+	for _, h := range r.Hosts {
+		if h.Covered {
+			fmt.Fprintf(&b, "%s: covered by %s\n", h.Host, h.MatchedBy)
+			continue
+		}
+		missed++
+		fmt.Fprintf(&b, "%s: not covered (%s)\n", h.Host, h.Reason)
+	}
+	if missed == 0 {
+		return ActionResultMsg{Message: fmt.Sprintf("Certificate covers all %d host(s)", len(r.Hosts)), Details: strings.TrimSpace(b.String())}
+	}
+	msg := fmt.Sprintf("Certificate does NOT cover %d of %d host(s)", missed, len(r.Hosts))
+	return ActionResultMsg{Message: msg, Details: strings.TrimSpace(b.String()), IsErr: true}
+}
+
+// verifyResultText renders a verification result for the last-action view:
+// the verifier output, then the reason, failing depth and chain(s) when the
+// backend reports them.
+func verifyResultText(r *cert.VerifyResult) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(r.Output))
+	if r.Reason != "" {
+		b.WriteString("\n\nReason: " + string(r.Reason))
+		if r.FailingDepth != nil {
+			fmt.Fprintf(&b, "\nFailing depth: %d", *r.FailingDepth)
+		}
+	}
+	for i, chain := range r.Chains {
+		if r.Valid {
+			fmt.Fprintf(&b, "\n\nChain %d:", i+1)
+		} else {
+			b.WriteString("\n\nAttempted path:")
+		}
+		for depth, c := range chain {
+			marker := ""
+			if !r.Valid && r.FailingDepth != nil && *r.FailingDepth == depth {
+				marker = "  <- " + string(r.Reason)
+			}
+			fmt.Fprintf(&b, "\n  %d: %s (not after %s)%s", depth, c.Subject, c.NotAfter, marker)
+		}
+	}
+	if r.Details != "" {
+		b.WriteString("\n\n" + r.Details)
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nickromney/certconv/internal/cert"
 )
 
 func TestInputState_BeginResetActive(t *testing.T) {
@@ -335,3 +336,23 @@ func TestHandleInputKey_TypingAndEscAndCtrlU(t *testing.T) {
 		t.Fatalf("expected input inactive after esc")
 	}
 }
+
+func TestVerifyResultText_ShowsReasonDepthAndPath(t *testing.T) {
+	depth := 1
+	r := &cert.VerifyResult{
+		Output:       "leaf.pem: verification failed: x509: certificate has expired",
+		Reason:       cert.VerifyReasonExpired,
+		FailingDepth: &depth,
+		Chains: [][]cert.VerifyChainCert{{
+			{Subject: "CN = leaf", NotAfter: "Jan  1 00:00:00 2030 GMT"},
+			{Subject: "CN = intermediate", NotAfter: "Jan  1 00:00:00 2027 GMT"},
+		}},
+		Details: "Certificate at depth 1 (CN = intermediate) is not valid",
+	}
+	got := verifyResultText(r)
+	for _, want := range []string{"Reason: expired", "Failing depth: 1", "Attempted path:", "1: CN = intermediate (not after Jan  1 00:00:00 2027 GMT)  <- expired", "is not valid"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
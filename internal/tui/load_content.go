@@ -128,6 +128,16 @@ func (m Model) loadContentParsed(path string) tea.Cmd {
 	}
 }
 
+func (m Model) loadContentPFXStructure(path string) tea.Cmd {
+	return func() tea.Msg {
+		in, err := cert.InspectPFX(path, m.pfxPassword(path))
+		if err != nil {
+			return ContentPFXStructureMsg{Path: path, Err: err}
+		}
+		return ContentPFXStructureMsg{Path: path, Text: cert.RenderPFXInspectionText(in)}
+	}
+}
+
 func (m Model) loadContentModulus(path string) tea.Cmd {
 	return func() tea.Msg {
 		mod, err := m.engine.RSAModulus(m.ctx(), path)
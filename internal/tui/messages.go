@@ -69,6 +69,13 @@ type ContentParsedMsg struct {
 	Err  error
 }
 
+// ContentPFXStructureMsg carries the rendered bag-level structure of a PFX.
+type ContentPFXStructureMsg struct {
+	Path string
+	Text string
+	Err  error
+}
+
 // ContentModulusMsg carries RSA modulus information (and optional match result).
 type ContentModulusMsg struct {
 	Path string
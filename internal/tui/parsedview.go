@@ -5,6 +5,8 @@ import (
 	"crypto/x509"
 	"encoding/hex"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -20,6 +22,13 @@ func renderParsedCert(path, password string, engine *cert.Engine, ctx context.Co
 		return "", fmt.Errorf("detect type: %w", err)
 	}
 
+	switch ft {
+	case cert.FileTypeCSR:
+		return renderParsedCSR(path)
+	case cert.FileTypeCRL:
+		return renderParsedCRL(path)
+	}
+
 	var c *x509.Certificate
 
 	switch ft {
@@ -29,6 +38,14 @@ func renderParsedCert(path, password string, engine *cert.Engine, ctx context.Co
 			return "", pemErr
 		}
 		c, err = cert.ParseCertBytes(pemOut)
+	case cert.FileTypeJKS:
+		c, err = firstKeystoreCertificate(path, password)
+	case cert.FileTypeK8sSecret:
+		c, err = firstK8sSecretCertificate(path)
+	case cert.FileTypeKubeconfig:
+		c, err = firstKubeconfigCertificate(path)
+	case cert.FileTypeJWK:
+		c, err = firstJWKCertificate(path)
 	case cert.FileTypeCert, cert.FileTypeCombined, cert.FileTypeDER:
 		c, err = cert.ParseCertFile(path)
 	default:
@@ -159,6 +176,131 @@ func renderParsedCert(path, password string, engine *cert.Engine, ctx context.Co
 	return b.String(), nil
 }
 
+// renderParsedCSR is the CSR counterpart to renderParsedCert.
+func renderParsedCSR(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	s, err := cert.SummaryFromBytes(path, data)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	const labelW = 24
+	kv := func(k, v string) {
+		if v == "" {
+			return
+		}
+		fmt.Fprintf(&b, "%-*s %s\n", labelW, k+":", v)
+	}
+	section := func(title string) {
+		b.WriteString("\n")
+		b.WriteString(title + "\n")
+		b.WriteString(strings.Repeat("-", len(title)) + "\n")
+	}
+
+	section("Certificate Request")
+	kv("Subject", s.Subject)
+	if s.SignatureValid != nil {
+		if *s.SignatureValid {
+			kv("Self-Signature", "Valid")
+		} else {
+			kv("Self-Signature", "INVALID")
+		}
+	}
+
+	if len(s.SANs) > 0 {
+		section("Requested Subject Alternative Names")
+		for _, san := range s.SANs {
+			b.WriteString("  " + san + "\n")
+		}
+	}
+
+	section("Key & Signature")
+	kv("Public Key", s.PublicKeyInfo)
+	kv("Signature Algorithm", s.SignatureAlgorithm)
+
+	if len(s.RequestedExtensions) > 0 {
+		section("Requested Extensions")
+		for _, ext := range s.RequestedExtensions {
+			b.WriteString("  " + ext + "\n")
+		}
+		if len(s.KeyUsage) > 0 {
+			kv("Key Usage", strings.Join(s.KeyUsage, ", "))
+		}
+		if len(s.ExtKeyUsage) > 0 {
+			kv("Extended Key Usage", strings.Join(s.ExtKeyUsage, ", "))
+		}
+	}
+
+	section("Fingerprints")
+	kv("SHA-256", s.Fingerprint)
+
+	return b.String(), nil
+}
+
+// renderParsedCRL lists a revocation list's header fields and revoked serials.
+func renderParsedCRL(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	s, err := cert.SummaryFromBytes(path, data)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	const labelW = 24
+	kv := func(k, v string) {
+		if v == "" {
+			return
+		}
+		fmt.Fprintf(&b, "%-*s %s\n", labelW, k+":", v)
+	}
+	section := func(title string) {
+		b.WriteString("\n")
+		b.WriteString(title + "\n")
+		b.WriteString(strings.Repeat("-", len(title)) + "\n")
+	}
+
+	section("Certificate Revocation List")
+	kv("Issuer", s.Issuer)
+	kv("CRL Number", s.CRLNumber)
+	kv("Signature Algorithm", s.SignatureAlgorithm)
+
+	section("Validity")
+	kv("This Update", s.ThisUpdate)
+	if s.NextUpdate == "" {
+		kv("Next Update", "none")
+	} else {
+		kv("Next Update", s.NextUpdate)
+	}
+	if t, err := time.Parse(time.RFC3339, s.NextUpdate); err == nil {
+		if time.Now().After(t) {
+			kv("Status", "EXPIRED")
+		} else {
+			kv("Status", fmt.Sprintf("Current (next update in %d days)", int(time.Until(t).Hours()/24)))
+		}
+	}
+
+	section(fmt.Sprintf("Revoked Certificates (%d)", len(s.Revoked)))
+	for _, r := range s.Revoked {
+		line := "  " + r.Serial + "  " + r.RevokedAt
+		if r.Reason != "" {
+			line += "  " + r.Reason
+		}
+		b.WriteString(line + "\n")
+	}
+
+	section("Fingerprints")
+	kv("SHA-256", s.Fingerprint)
+
+	return b.String(), nil
+}
+
 func describePublicKeyBrief(c *x509.Certificate) string {
 	// Reuse the cert package's existing function via the summary path.
 	s := &cert.CertSummary{}
@@ -177,3 +319,64 @@ func formatHex(data []byte) string {
 	}
 	return strings.Join(parts, ":")
 }
+
+func firstKeystoreCertificate(path, password string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ks, err := cert.ParseKeystore(data, password)
+	if err != nil {
+		return nil, err
+	}
+	certs := ks.Certificates()
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("keystore contains no certificates")
+	}
+	return certs[0], nil
+}
+
+func firstK8sSecretCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := cert.ParseK8sSecrets(data)
+	if err != nil {
+		return nil, err
+	}
+	return secrets[0].Certificates[0], nil
+}
+
+func firstKubeconfigCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := cert.ParseKubeconfig(data, filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	return entries[0].Certificates[0], nil
+}
+
+func firstJWKCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := cert.ParseJWKs(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		certs, err := k.Certificates()
+		if err != nil {
+			return nil, err
+		}
+		if len(certs) > 0 {
+			return certs[0], nil
+		}
+	}
+	return nil, fmt.Errorf("JWK has no x5c certificates")
+}
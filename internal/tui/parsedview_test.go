@@ -111,3 +111,35 @@ func TestRenderParsedCert_ShowsExpiredStatus(t *testing.T) {
 		t.Fatalf("expected EXPIRED status, got:\n%s", out)
 	}
 }
+
+func TestRenderParsedCert_K8sSecretShowsFirstCertificate(t *testing.T) {
+	dir := t.TempDir()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ingress.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	certPEM, err := os.ReadFile(writeCertPEM(t, dir, template, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: web-tls\ntype: kubernetes.io/tls\nstringData:\n  tls.crt: |\n    " +
+		strings.ReplaceAll(strings.TrimSpace(string(certPEM)), "\n", "\n    ") + "\n"
+	p := filepath.Join(dir, "secret.yaml")
+	if err := os.WriteFile(p, []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := renderParsedCert(p, "", nil, context.Background())
+	if err != nil {
+		t.Fatalf("renderParsedCert: %v", err)
+	}
+	if !strings.Contains(out, "ingress.example.com") {
+		t.Fatalf("expected the Secret's certificate, got:\n%s", out)
+	}
+}
@@ -506,7 +506,7 @@ func (m Model) helpText() string {
 		{
 			title: "Actions",
 			items: []helpItem{
-				{key: "a", desc: "Toggle read-only checks (expiry/match/verify)"},
+				{key: "a", desc: "Toggle read-only checks (expiry/hostname/match/verify/P7B)"},
 				{key: "f / @", desc: "Open floating file picker (Enter opens dirs; Esc closes)"},
 				{key: "o", desc: "Show output command for current pane-3 view (Esc closes; c copies)"},
 				{key: "t", desc: "Cycle theme (session)"},
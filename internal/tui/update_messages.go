@@ -69,6 +69,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateContentParsed(msg)
 		return m, nil
 
+	case ContentPFXStructureMsg:
+		m.updateContentPFXStructure(msg)
+		return m, nil
+
 	case CertSummaryMsg:
 		return m.updateCertSummary(msg)
 
@@ -262,6 +266,17 @@ func (m *Model) updateContentPFXBase64(msg ContentPFXBase64Msg) {
 	m.contentPane.SetPFXBase64(msg.Text)
 }
 
+func (m *Model) updateContentPFXStructure(msg ContentPFXStructureMsg) {
+	if msg.Path == "" || msg.Path != m.selectedFile {
+		return
+	}
+	if msg.Err != nil {
+		m.contentPane.SetPFXStructureError(msg.Err.Error())
+		return
+	}
+	m.contentPane.SetPFXStructure(msg.Text)
+}
+
 func (m *Model) updateContentParsed(msg ContentParsedMsg) {
 	if msg.Path == "" || msg.Path != m.selectedFile {
 		return
@@ -450,7 +465,7 @@ func (m Model) autoMatchKeyCmd(certPath string) tea.Cmd {
 
 func (m Model) updateActionSelected(msg ActionSelectedMsg) (tea.Model, tea.Cmd) {
 	switch msg.ID {
-	case "expiry", "match", "verify":
+	case "expiry", "covers", "match", "p7b", "verify":
 		return m, m.handleAction(msg.ID)
 	default:
 		m.statusMsg = "TUI write actions are disabled (read-only mode)"
@@ -238,6 +238,191 @@ func MakePFX(t *testing.T, pair *CertPair, password string) string {
 	return path
 }
 
+// CRLSet holds a CA, two leaves it issued and a CRL from the CA that
+// revokes one of them (serial RevokedSerial, reason keyCompromise).
+type CRLSet struct {
+	CAPath        string
+	GoodPath      string
+	RevokedPath   string
+	CRLPath       string // PEM
+	DERCRLPath    string
+	RevokedSerial *big.Int
+	Dir           string
+}
+
+// MakeCRLSet generates a CRLSet whose CRL has the given next update time;
+// pass a time in the past for an expired CRL.
+func MakeCRLSet(t *testing.T, nextUpdate time.Time) *CRLSet {
+	t.Helper()
+
+	dir := t.TempDir()
+	writePEM := func(name, typ string, der []byte) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return p
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CertConv Test CA"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		SubjectKeyId:          []byte{1, 2, 3, 4},
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("parse CA: %v", err)
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate leaf key: %v", err)
+	}
+	set := &CRLSet{
+		CAPath:        writePEM("ca.pem", "CERTIFICATE", caDER),
+		RevokedSerial: big.NewInt(0x1001),
+		Dir:           dir,
+	}
+	for _, leaf := range []struct {
+		serial *big.Int
+		path   *string
+		name   string
+	}{
+		{big.NewInt(0x1000), &set.GoodPath, "good.pem"},
+		{set.RevokedSerial, &set.RevokedPath, "revoked.pem"},
+	} {
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: leaf.serial,
+			Subject:      pkix.Name{CommonName: "leaf.test.local"},
+			DNSNames:     []string{"leaf.test.local"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(30 * 24 * time.Hour),
+		}, ca, &leafKey.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("create leaf: %v", err)
+		}
+		*leaf.path = writePEM(leaf.name, "CERTIFICATE", der)
+	}
+
+	crlDER, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(42),
+		ThisUpdate: time.Now().Add(-12 * time.Hour),
+		NextUpdate: nextUpdate,
+		RevokedCertificateEntries: []x509.RevocationListEntry{{
+			SerialNumber:   set.RevokedSerial,
+			RevocationTime: time.Now().Add(-6 * time.Hour),
+			ReasonCode:     1, // keyCompromise
+		}},
+	}, ca, caKey)
+	if err != nil {
+		t.Fatalf("create CRL: %v", err)
+	}
+	set.CRLPath = writePEM("ca.crl", "X509 CRL", crlDER)
+	set.DERCRLPath = filepath.Join(dir, "ca-der.crl")
+	if err := os.WriteFile(set.DERCRLPath, crlDER, 0o644); err != nil {
+		t.Fatalf("write DER CRL: %v", err)
+	}
+	return set
+}
+
+// ChainSet holds a root CA, an intermediate it issued and a serverAuth leaf
+// for app.test.local issued by the intermediate. The intermediate expires
+// after 60 days and the leaf after 90, so a point-in-time check between the
+// two fails at the intermediate.
+type ChainSet struct {
+	RootPath         string
+	IntermediatePath string
+	LeafPath         string
+	LeafKeyPath      string
+	Dir              string
+}
+
+// MakeChain generates a ChainSet.
+func MakeChain(t *testing.T) *ChainSet {
+	t.Helper()
+
+	dir := t.TempDir()
+	now := time.Now()
+	writePEM := func(name, typ string, der []byte) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return p
+	}
+	issue := func(tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		if parent == nil {
+			parent, parentKey = tmpl, key
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+		if err != nil {
+			t.Fatalf("create %s: %v", tmpl.Subject.CommonName, err)
+		}
+		c, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatalf("parse %s: %v", tmpl.Subject.CommonName, err)
+		}
+		return c, key, der
+	}
+
+	root, rootKey, rootDER := issue(&x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CertConv Test Root"},
+		NotBefore:             now.Add(-24 * time.Hour),
+		NotAfter:              now.Add(10 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	inter, interKey, interDER := issue(&x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "CertConv Test Intermediate"},
+		NotBefore:             now.Add(-24 * time.Hour),
+		NotAfter:              now.Add(60 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+	}, root, rootKey)
+	_, leafKey, leafDER := issue(&x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "app.test.local"},
+		DNSNames:     []string{"app.test.local"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(90 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, inter, interKey)
+
+	keyDER, err := x509.MarshalECPrivateKey(leafKey)
+	if err != nil {
+		t.Fatalf("marshal leaf key: %v", err)
+	}
+	return &ChainSet{
+		RootPath:         writePEM("root.pem", "CERTIFICATE", rootDER),
+		IntermediatePath: writePEM("intermediate.pem", "CERTIFICATE", interDER),
+		LeafPath:         writePEM("leaf.pem", "CERTIFICATE", leafDER),
+		LeafKeyPath:      writePEM("leaf.key", "EC PRIVATE KEY", keyDER),
+		Dir:              dir,
+	}
+}
+
 func parsePEMPrivateKey(data []byte) (any, error) {
 	block, _ := pem.Decode(data)
 	if block == nil {